@@ -6,10 +6,18 @@ async function render() {
     const response = await fetch('http://localhost:8090/status');
     const data = await response.json();
 
-    function createRowHtml({ id, name, pid, running, auto_restart, group }) {
+    function createRowHtml({ id, name, pid, running, state, auto_restart, group, actions }) {
         
-        const runningTextColor = running ? 'text-success' : 'text-danger';
-        const runningTextStatus = running ? 'Running' : 'Stopped';
+        const paused = state === 'paused';
+        const runningTextColor = paused ? 'text-warning' : running ? 'text-success' : 'text-danger';
+        const runningTextStatus = paused ? 'Paused' : running ? 'Running' : 'Stopped';
+        const pauseButton = paused
+            ? `<button onclick="resume('${id}')" class="btn btn-warning">Resume</button>`
+            : `<button onclick="pause('${id}')" class="btn btn-warning">Pause</button>`;
+        const actionButtons = (actions || [])
+            .map(action => `<button onclick="runAction('${id}', '${action}')" class="btn btn-secondary btn-sm mt-1">${action}</button>`)
+            .join(' ');
+        const signalOptions = signals.map(signal => `<option value="${signal}">${signal}</option>`).join('');
 
         return `
             <div class="row py-2">
@@ -21,7 +29,14 @@ async function render() {
                 <div class="col-3">
                     <button onclick="run('${id}')" class="btn btn-success">Start</button>
                     <button onclick="stop('${id}')" class="btn btn-danger">Stop</button>
+                    <button onclick="restart('${id}')" class="btn btn-primary">Restart</button>
+                    ${pauseButton}
                     <button onclick="showLogsModal('${id}')" class="btn btn-info">Logs</button>
+                    <div class="input-group input-group-sm mt-1">
+                        <select id="signal-${id}" class="form-select">${signalOptions}</select>
+                        <button onclick="sendSignal('${id}')" class="btn btn-outline-secondary">Send</button>
+                    </div>
+                    ${actionButtons}
                 </div>
             </div>
         `;
@@ -45,6 +60,11 @@ async function render() {
                 </div>
                 <div class="col-2">
                     <button onclick="stopgroup('${group}')" class="btn btn-danger">Stop Group</button>
+                    <button onclick="restartGroup('${group}')" class="btn btn-primary mt-1">Rolling Restart</button>
+                </div>
+                <div class="col-3">
+                    <button onclick="pauseGroup('${group}')" class="btn btn-warning">Pause Group</button>
+                    <button onclick="resumeGroup('${group}')" class="btn btn-warning">Resume Group</button>
                 </div>
             </div>
             ${items.map(createRowHtml).join('')}
@@ -162,6 +182,10 @@ async function stop(id) {
     await simpleRequest(`/stop?id=${id}`);
 }
 
+async function restart(id) {
+    await simpleRequest(`/restart?id=${id}`);
+}
+
 async function runGroup(group) {
     await simpleRequest(`/rungroup?group=${group}`);
 }
@@ -178,6 +202,69 @@ async function stopAll() {
     await simpleRequest(`/stopall`);
 }
 
+// signals are the signals that can be sent to an executable, see orchestrator.Signals.
+const signals = ['HUP', 'INT', 'QUIT', 'USR1', 'USR2', 'TERM', 'KILL', 'ALRM', 'WINCH'];
+
+// v2Request posts to an endpoint of the v2 API, which answers with the status on success and with a message on error.
+async function v2Request(path, successMessage, body) {
+    try {
+        const options = { method: 'POST' };
+        if (body !== undefined) {
+            options.headers = { 'Content-Type': 'application/json' };
+            options.body = JSON.stringify(body);
+        }
+        const response = await fetch(path, options);
+        triggerRefreshImmediately();
+        const responseJson = await response.json();
+        alertAsync(response.ok ? successMessage : responseJson.message);
+    }
+    catch (error) {
+        alertAsync('Error executing the request, see console for more details');
+        console.error('Error:', error);
+    }
+}
+
+async function sendSignal(id) {
+    const signal = $(`#signal-${id}`).val();
+    await v2Request(`/api/v2/executables/${id}:signal`, `Sent ${signal}`, { signal });
+}
+
+async function pause(id) {
+    await v2Request(`/api/v2/executables/${id}:pause`, 'Paused');
+}
+
+async function resume(id) {
+    await v2Request(`/api/v2/executables/${id}:resume`, 'Resumed');
+}
+
+async function pauseGroup(group) {
+    await v2Request(`/api/v2/groups/${group}:pause`, `Paused group ${group}`);
+}
+
+async function resumeGroup(group) {
+    await v2Request(`/api/v2/groups/${group}:resume`, `Resumed group ${group}`);
+}
+
+async function restartGroup(group) {
+    await v2Request(`/api/v2/groups/${group}:restart`, `Restarted group ${group}`, {});
+}
+
+async function runAction(id, action) {
+    try {
+        const response = await fetch(`/api/v2/executables/${id}/actions/${action}:run`, { method: 'POST' });
+        triggerRefreshImmediately();
+        const responseJson = await response.json();
+        // The result of a command that failed is in the details of the error.
+        const result = response.ok ? responseJson : responseJson.details;
+        const message = response.ok ? `Action ${action} succeeded` : responseJson.message;
+        alertAsync(result && result.output ? `${message}\n\n${result.output}` : message);
+    }
+    catch (error) {
+        alertAsync('Error executing the request, see console for more details');
+        console.error('Error:', error);
+    }
+}
+
 async function toggleSet() {
     if (isSet === false) await simpleRequest(`/set`);
     if (isSet === true) await simpleRequest(`/unset`);
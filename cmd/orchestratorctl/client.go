@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"orchestrator/internal/apihttp/dtos"
+	"orchestrator/internal/orchestrator"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var UnixSchemePrefix = "unix://"
+
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrInvalid     = errors.New("invalid")
+	ErrUnreachable = errors.New("server unreachable")
+	ErrForbidden   = errors.New("forbidden")
+)
+
+type Client struct {
+	server     string
+	httpClient *http.Client
+}
+
+type APIError struct {
+	Status   int
+	Response dtos.ErrorResponse
+	Body     []byte
+}
+
+// newAPIError decodes the error response of the server, which is kept as the message when it is not JSON.
+func newAPIError(status int, content []byte) *APIError {
+	apiErr := &APIError{Status: status, Body: content}
+	if json.Unmarshal(content, &apiErr.Response) != nil || apiErr.Response.Message == "" {
+		apiErr.Response.Message = strings.TrimSpace(string(content))
+	}
+
+	return apiErr
+}
+
+func (o *APIError) Error() string {
+	return fmt.Sprintf("%s (%s)", o.Response.Message, o.Response.Code)
+}
+
+func (o *APIError) Unwrap() error {
+	switch o.Status {
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+// NewClient talks to an HTTP server, or to the Unix socket of the server when it is given as unix:///path/to/socket.
+func NewClient(server string) *Client {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+
+	if socketPath, ok := strings.CutPrefix(server, UnixSchemePrefix); ok {
+		httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		server = "http://unix"
+	}
+
+	return &Client{
+		server:     strings.TrimSuffix(server, "/"),
+		httpClient: httpClient,
+	}
+}
+
+func (o *Client) Executables() ([]orchestrator.Status, error) {
+	var statuses []orchestrator.Status
+	err := o.do(http.MethodGet, "/api/v2/executables", nil, &statuses)
+
+	return statuses, err
+}
+
+func (o *Client) Group(name string) ([]orchestrator.Status, error) {
+	var statuses []orchestrator.Status
+	err := o.do(http.MethodGet, "/api/v2/groups/"+url.PathEscape(name), nil, &statuses)
+
+	return statuses, err
+}
+
+func (o *Client) ExecutableAction(id string, action string) (orchestrator.Status, error) {
+	var status orchestrator.Status
+	err := o.do(http.MethodPost, "/api/v2/executables/"+url.PathEscape(id)+":"+action, nil, &status)
+
+	return status, err
+}
+
+func (o *Client) Scale(id string, replicas int) ([]orchestrator.Status, error) {
+	body, err := json.Marshal(dtos.ScaleRequest{Replicas: replicas})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []orchestrator.Status
+	err = o.do(http.MethodPost, "/api/v2/executables/"+url.PathEscape(id)+":scale", bytes.NewReader(body), &statuses)
+
+	return statuses, err
+}
+
+// AllAction starts or stops all the executables. Scheduled executables are left to their schedule, and the ones already in the state are skipped.
+func (o *Client) AllAction(action string) ([]orchestrator.Status, error) {
+	var statuses []orchestrator.Status
+	err := o.do(http.MethodPost, "/api/v2/executables:"+action, nil, &statuses)
+
+	return statuses, err
+}
+
+func (o *Client) GroupAction(name string, action string) ([]orchestrator.Status, error) {
+	var statuses []orchestrator.Status
+	err := o.do(http.MethodPost, "/api/v2/groups/"+url.PathEscape(name)+":"+action, nil, &statuses)
+
+	return statuses, err
+}
+
+func (o *Client) Signal(id string, signal string) (orchestrator.Status, error) {
+	body, err := json.Marshal(dtos.SignalRequest{Signal: signal})
+	if err != nil {
+		return orchestrator.Status{}, err
+	}
+
+	var status orchestrator.Status
+	err = o.do(http.MethodPost, "/api/v2/executables/"+url.PathEscape(id)+":signal", bytes.NewReader(body), &status)
+
+	return status, err
+}
+
+func (o *Client) SignalGroup(name string, signal string) ([]orchestrator.Status, error) {
+	body, err := json.Marshal(dtos.SignalRequest{Signal: signal})
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []orchestrator.Status
+	err = o.do(http.MethodPost, "/api/v2/groups/"+url.PathEscape(name)+":signal", bytes.NewReader(body), &statuses)
+
+	return statuses, err
+}
+
+// RunAction runs an action of an executable. The result of a command that failed is returned with the error.
+func (o *Client) RunAction(id string, action string) (orchestrator.ActionResult, error) {
+	var result orchestrator.ActionResult
+	err := o.do(http.MethodPost, "/api/v2/executables/"+url.PathEscape(id)+"/actions/"+url.PathEscape(action)+":run", nil, &result)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		var response struct {
+			Details orchestrator.ActionResult `json:"details"`
+		}
+		if json.Unmarshal(apiErr.Body, &response) == nil && response.Details.Action != "" {
+			result = response.Details
+		}
+	}
+
+	return result, err
+}
+
+// RollingRestart restarts a group in batches. The result of an aborted rolling restart is returned with the error.
+func (o *Client) RollingRestart(name string, maxUnavailable int, minUptime time.Duration) (orchestrator.RolloutResult, error) {
+	request := dtos.RollingRestartRequest{MaxUnavailable: maxUnavailable}
+	if minUptime > 0 {
+		request.MinUptime = minUptime.String()
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return orchestrator.RolloutResult{}, err
+	}
+
+	// A rolling restart lasts as long as its batches.
+	var result orchestrator.RolloutResult
+	err = o.withoutTimeout().do(http.MethodPost, "/api/v2/groups/"+url.PathEscape(name)+":restart", bytes.NewReader(body), &result)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		var response struct {
+			Details orchestrator.RolloutResult `json:"details"`
+		}
+		if json.Unmarshal(apiErr.Body, &response) == nil && response.Details.Group != "" {
+			result = response.Details
+		}
+	}
+
+	return result, err
+}
+
+/*
+Deploy uploads a binary and deploys it onto an executable, or deploys a version kept by the server when binary is nil.
+The result of a deployment that was rolled back is returned with the error.
+*/
+func (o *Client) Deploy(id string, binary io.Reader, version string, watch time.Duration) (orchestrator.DeployResult, error) {
+	query := url.Values{}
+	if version != "" {
+		query.Set("version", version)
+	}
+	if watch > 0 {
+		query.Set("watch", watch.String())
+	}
+
+	// A deployment lasts as long as the watch periods of its instances.
+	var result orchestrator.DeployResult
+	err := o.withoutTimeout().send(http.MethodPost, "/api/v2/executables/"+url.PathEscape(id)+":deploy?"+query.Encode(), "application/octet-stream", binary, &result)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		var response struct {
+			Details orchestrator.DeployResult `json:"details"`
+		}
+		if json.Unmarshal(apiErr.Body, &response) == nil && response.Details.Executable != "" {
+			result = response.Details
+		}
+	}
+
+	return result, err
+}
+
+// RunGroup starts the executables of a group and waits for its tasks, at most for the timeout when it is not 0.
+func (o *Client) RunGroup(name string, timeout time.Duration) (orchestrator.GroupResult, error) {
+	var result orchestrator.GroupResult
+	path := "/api/v2/groups/" + url.PathEscape(name) + ":run"
+	if timeout > 0 {
+		path += "?" + url.Values{"timeout": {timeout.String()}}.Encode()
+	}
+	err := o.withoutTimeout().do(http.MethodPost, path, nil, &result)
+
+	return result, err
+}
+
+func (o *Client) Logs(id string, logsType string, offset int) (string, error) {
+	var logs string
+	query := url.Values{"type": {logsType}, "offset": {fmt.Sprint(offset)}}
+	err := o.do(http.MethodGet, "/api/v2/executables/"+url.PathEscape(id)+"/logs?"+query.Encode(), nil, &logs)
+
+	return logs, err
+}
+
+// FollowLogs writes the most recent log file of an executable to w as it grows, until the context is done.
+func (o *Client) FollowLogs(ctx context.Context, id string, logsType string, fromStart bool, w io.Writer) error {
+	query := url.Values{"type": {logsType}, "follow": {"true"}, "from_start": {strconv.FormatBool(fromStart)}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, o.server+"/api/v2/executables/"+url.PathEscape(id)+"/logs?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := o.withoutTimeout().httpClient.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrUnreachable, err.Error())
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		content, _ := io.ReadAll(response.Body)
+		return newAPIError(response.StatusCode, content)
+	}
+
+	_, err = io.Copy(w, response.Body)
+	if err != nil && ctx.Err() == nil {
+		return errors.New("error reading logs: " + err.Error())
+	}
+
+	return nil
+}
+
+func (o *Client) Reload() ([]orchestrator.Status, error) {
+	var statuses []orchestrator.Status
+	err := o.do(http.MethodPost, "/api/v2/config:reload", nil, &statuses)
+
+	return statuses, err
+}
+
+// Validate returns the validation report of the configuration in the format. An invalid configuration is not an error of the request.
+func (o *Client) Validate(content io.Reader, format string) (orchestrator.ValidationReport, error) {
+	var report orchestrator.ValidationReport
+	err := o.do(http.MethodPost, "/api/v2/config:validate?"+url.Values{"format": {format}}.Encode(), content, &report)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnprocessableEntity {
+		if json.Unmarshal(apiErr.Body, &report) == nil && len(report.Issues) > 0 {
+			return report, nil
+		}
+	}
+
+	return report, err
+}
+
+// withoutTimeout returns a copy of the client for requests that last as long as the operation they wait for.
+func (o *Client) withoutTimeout() *Client {
+	client, httpClient := *o, *o.httpClient
+	httpClient.Timeout = 0
+	client.httpClient = &httpClient
+
+	return &client
+}
+
+// do sends the request and decodes the response into out. Plain text responses are stored as is when out is a *string.
+func (o *Client) do(method string, path string, body io.Reader, out any) error {
+	return o.send(method, path, "application/json", body, out)
+}
+
+// send is do with the content type of the body.
+func (o *Client) send(method string, path string, contentType string, body io.Reader, out any) error {
+	request, err := http.NewRequest(method, o.server+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := o.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnreachable, err.Error())
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return errors.New("error reading response: " + err.Error())
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return newAPIError(response.StatusCode, content)
+	}
+
+	if text, ok := out.(*string); ok {
+		*text = string(content)
+		return nil
+	}
+
+	if out == nil || len(content) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(content, out)
+	if err != nil {
+		return errors.New("error decoding response: " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var (
+	DefaultServer    = "http://localhost:8090"
+	ServerEnv        = "ORCHESTRATOR_SERVER"
+	ContextEnv       = "ORCHESTRATOR_CONTEXT"
+	ConfigPathEnv    = "ORCHESTRATORCTL_CONFIG"
+	ConfigDirName    = "orchestratorctl"
+	ConfigFileName   = "config.json"
+	ConfigDirDefault = ".config"
+)
+
+// Config keeps named servers, so that the on-call can switch between orchestrators with a context name.
+type Config struct {
+	CurrentContext string             `json:"current_context"`
+	Contexts       map[string]Context `json:"contexts"`
+}
+
+type Context struct {
+	Server string `json:"server"`
+}
+
+/*
+resolveServer picks the server to talk to in order of precedence:
+the -server flag, the ORCHESTRATOR_SERVER env, the -context flag or ORCHESTRATOR_CONTEXT env,
+the current context of the config file and finally the default local server.
+*/
+func resolveServer(serverFlag string, contextFlag string) (string, error) {
+	if serverFlag != "" {
+		return serverFlag, nil
+	}
+
+	if server := os.Getenv(ServerEnv); server != "" {
+		return server, nil
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	contextName := contextFlag
+	if contextName == "" {
+		contextName = os.Getenv(ContextEnv)
+	}
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+
+	if contextName == "" {
+		return DefaultServer, nil
+	}
+
+	context, ok := config.Contexts[contextName]
+	if !ok || context.Server == "" {
+		return "", errors.New("context not found in config: " + contextName)
+	}
+
+	return context.Server, nil
+}
+
+func loadConfig() (Config, error) {
+	var config Config
+
+	path := os.Getenv(ConfigPathEnv)
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return config, nil
+		}
+		path = filepath.Join(home, ConfigDirDefault, ConfigDirName, ConfigFileName)
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, errors.New("error reading config file: " + err.Error())
+	}
+
+	err = json.Unmarshal(content, &config)
+	if err != nil {
+		return config, errors.New("error decoding config file " + path + ": " + err.Error())
+	}
+
+	return config, nil
+}
@@ -0,0 +1,651 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"orchestrator/internal/orchestrator"
+)
+
+var (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitNotFound    = 3
+	ExitConflict    = 4
+	ExitInvalid     = 5
+	ExitUnreachable = 6
+	ExitForbidden   = 7
+)
+
+var ErrUsage = errors.New("usage")
+
+var usage = `Usage: orchestratorctl [-server URL] [-context NAME] [-o table|json] COMMAND [ARGS]
+
+Commands:
+  status   [NAME|ID ...] [-group GROUP]        Show the status of the executables
+  start    NAME|ID ... | -group GROUP | -all   Start executables
+           -group GROUP -wait [-timeout D]     Start a group and wait for its tasks to finish
+  stop     NAME|ID ... | -group GROUP | -all   Stop executables
+  restart  NAME|ID ... | -group GROUP | -all   Restart executables
+           -group GROUP [-max-unavailable N] [-min-uptime D]
+                                               Restart a group in batches, stopping at the first member that fails
+  pause    NAME|ID ... | -group GROUP          Freeze running executables, keeping their memory
+  resume   NAME|ID ... | -group GROUP          Resume paused executables
+  scale    NAME|ID REPLICAS                    Change the number of instances of a replicated executable
+  signal   SIGNAL NAME|ID ... | -group GROUP   Send a signal such as HUP, USR1 or QUIT to running executables
+  action   NAME|ID ACTION                      Run a named action of an executable and print its output
+  deploy   NAME|ID FILE [-version V] [-watch D]
+                                               Deploy a new binary, rolled back when an instance fails within the watch period
+  deploy   NAME|ID -version V [-watch D]       Deploy a version kept by the server, e.g. to roll back by hand
+  history  NAME|ID                             Show the deployed version of an executable and its deployments
+  logs     NAME|ID [-type out|errors|build] [-offset N] [-f]
+                                               Print the logs of an executable
+  reload                                       Apply the configuration file of the server
+  validate [-format F] FILE                    Validate a configuration file against the server ("-" reads stdin)
+
+The server is chosen by -server, $ORCHESTRATOR_SERVER, -context or $ORCHESTRATOR_CONTEXT
+(contexts are read from ~/.config/orchestratorctl/config.json) and defaults to ` + DefaultServer + `.
+A Unix socket of the server is given as unix:///path/to/socket.
+
+Exit codes: 0 ok, 1 error, 2 usage, 3 not found, 4 conflict, 5 invalid, 6 server unreachable, 7 forbidden.
+`
+
+type cli struct {
+	server  string
+	context string
+	output  string
+	stdout  io.Writer
+	stderr  io.Writer
+	client  *Client
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+
+	global := flag.NewFlagSet("orchestratorctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprint(stderr, usage) }
+	c.commonFlags(global)
+
+	if err := global.Parse(args); err != nil {
+		return exitCode(ErrUsage)
+	}
+
+	if global.NArg() == 0 {
+		global.Usage()
+		return ExitUsage
+	}
+
+	command, commandArgs := global.Arg(0), global.Args()[1:]
+
+	commands := map[string]func(args []string) error{
+		"status":   c.status,
+		"start":    func(args []string) error { return c.action("start", args) },
+		"stop":     func(args []string) error { return c.action("stop", args) },
+		"restart":  func(args []string) error { return c.action("restart", args) },
+		"pause":    func(args []string) error { return c.action("pause", args) },
+		"resume":   func(args []string) error { return c.action("resume", args) },
+		"scale":    c.scale,
+		"signal":   c.signal,
+		"action":   c.runAction,
+		"deploy":   c.deploy,
+		"history":  c.history,
+		"logs":     c.logs,
+		"reload":   c.reload,
+		"validate": c.validate,
+	}
+
+	handler, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: %s\n\n%s", command, usage)
+		return ExitUsage
+	}
+
+	err := handler(commandArgs)
+	if err != nil && !errors.Is(err, ErrUsage) {
+		fmt.Fprintln(stderr, "Error: "+err.Error())
+	}
+
+	return exitCode(err)
+}
+
+func (o *cli) commonFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&o.server, "server", o.server, "URL of the orchestrator server")
+	flagSet.StringVar(&o.context, "context", o.context, "Name of the context of the config file")
+	flagSet.StringVar(&o.output, "o", o.valueOr(o.output, OutputTable), "Output format: table or json")
+}
+
+func (o *cli) valueOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func (o *cli) flagSet(name string) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.SetOutput(o.stderr)
+	flagSet.Usage = func() { fmt.Fprint(o.stderr, usage) }
+	o.commonFlags(flagSet)
+
+	return flagSet
+}
+
+// parse accepts flags before and after the positional arguments.
+func (o *cli) parse(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, ErrUsage
+		}
+		if flagSet.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flagSet.Arg(0))
+		args = flagSet.Args()[1:]
+	}
+
+	if o.output != OutputTable && o.output != OutputJSON {
+		fmt.Fprintln(o.stderr, "unknown output format: "+o.output)
+		return nil, ErrUsage
+	}
+
+	server, err := resolveServer(o.server, o.context)
+	if err != nil {
+		return nil, err
+	}
+	o.client = NewClient(server)
+
+	return positional, nil
+}
+
+func (o *cli) status(args []string) error {
+	flagSet := o.flagSet("status")
+	group := flagSet.String("group", "", "Show the executables of a group only")
+
+	names, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	var statuses []orchestrator.Status
+	if *group != "" {
+		statuses, err = o.client.Group(*group)
+	} else {
+		statuses, err = o.client.Executables()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(names) > 0 {
+		statuses, err = resolve(statuses, names)
+		if err != nil {
+			return err
+		}
+	}
+
+	return printStatuses(o.stdout, o.output, statuses)
+}
+
+func (o *cli) action(action string, args []string) error {
+	flagSet := o.flagSet(action)
+	group := flagSet.String("group", "", "Apply to the executables of a group")
+	all := flagSet.Bool("all", false, "Apply to all the executables")
+	wait := flagSet.Bool("wait", false, "Wait for the tasks of the group to finish, with start -group")
+	timeout := flagSet.Duration("timeout", 0, "Maximum time to wait with -wait")
+	maxUnavailable := flagSet.Int("max-unavailable", 0, "Executables restarted at once with restart -group, 1 by default")
+	minUptime := flagSet.Duration("min-uptime", 0, "Time the restarted executables must stay up before the next batch with restart -group, 10s by default")
+
+	names, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	targets := 0
+	for _, selected := range []bool{len(names) > 0, *group != "", *all} {
+		if selected {
+			targets++
+		}
+	}
+	if targets != 1 {
+		fmt.Fprintln(o.stderr, action+" requires executable names, -group or -all")
+		return ErrUsage
+	}
+	// Only the running executables can be paused, the group endpoints skip the other ones.
+	if *all && (action == "pause" || action == "resume") {
+		fmt.Fprintln(o.stderr, action+" requires executable names or -group")
+		return ErrUsage
+	}
+
+	if *wait {
+		if action != "start" || *group == "" {
+			fmt.Fprintln(o.stderr, "-wait requires start -group")
+			return ErrUsage
+		}
+
+		result, err := o.client.RunGroup(*group, *timeout)
+		if err != nil {
+			return err
+		}
+		if err := printStatuses(o.stdout, o.output, result.Tasks); err != nil {
+			return err
+		}
+
+		switch {
+		case result.Error != "":
+			return fmt.Errorf("executables of group %s did not all start: %s", *group, result.Error)
+		case !result.Finished:
+			return fmt.Errorf("tasks of group %s did not finish in time", *group)
+		case !result.Succeeded:
+			return fmt.Errorf("tasks of group %s did not all succeed", *group)
+		}
+		return nil
+	}
+
+	if *group != "" && action == "restart" {
+		result, err := o.client.RollingRestart(*group, *maxUnavailable, *minUptime)
+		if result.Group != "" {
+			if printErr := printRollout(o.stdout, o.output, result); printErr != nil {
+				return printErr
+			}
+		}
+		return err
+	}
+
+	if *group != "" {
+		statuses, err := o.client.GroupAction(*group, action)
+		if err != nil {
+			return err
+		}
+		return printStatuses(o.stdout, o.output, statuses)
+	}
+
+	if *all && (action == "start" || action == "stop") {
+		statuses, err := o.client.AllAction(action)
+		if err != nil {
+			return err
+		}
+		return printStatuses(o.stdout, o.output, statuses)
+	}
+
+	executables, err := o.client.Executables()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *group != "":
+		executables, err = o.client.Group(*group)
+		if err != nil {
+			return err
+		}
+	case len(names) > 0:
+		executables, err = resolve(executables, names)
+		if err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	statuses := make([]orchestrator.Status, 0, len(executables))
+	for _, executable := range executables {
+		status, err := o.client.ExecutableAction(executable.ID, action)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", executable.Name, err))
+			status = executable
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := printStatuses(o.stdout, o.output, statuses); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o *cli) scale(args []string) error {
+	flagSet := o.flagSet("scale")
+
+	positional, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		fmt.Fprintln(o.stderr, "scale requires an executable and a number of replicas")
+		return ErrUsage
+	}
+
+	replicas, err := strconv.Atoi(positional[1])
+	if err != nil {
+		fmt.Fprintln(o.stderr, "invalid number of replicas: "+positional[1])
+		return ErrUsage
+	}
+
+	executables, err := o.client.Executables()
+	if err != nil {
+		return err
+	}
+	executables, err = resolve(executables, positional[:1])
+	if err != nil {
+		return err
+	}
+
+	statuses, err := o.client.Scale(executables[0].ID, replicas)
+	if err != nil {
+		return err
+	}
+
+	return printStatuses(o.stdout, o.output, statuses)
+}
+
+func (o *cli) signal(args []string) error {
+	flagSet := o.flagSet("signal")
+	group := flagSet.String("group", "", "Send the signal to the running executables of a group")
+
+	positional, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 || (len(positional) > 1) == (*group != "") {
+		fmt.Fprintln(o.stderr, "signal requires a signal and executable names or -group")
+		return ErrUsage
+	}
+	signalName, names := positional[0], positional[1:]
+
+	if *group != "" {
+		statuses, err := o.client.SignalGroup(*group, signalName)
+		if err != nil {
+			return err
+		}
+		return printStatuses(o.stdout, o.output, statuses)
+	}
+
+	executables, err := o.client.Executables()
+	if err != nil {
+		return err
+	}
+	executables, err = resolve(executables, names)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	statuses := make([]orchestrator.Status, 0, len(executables))
+	for _, executable := range executables {
+		status, err := o.client.Signal(executable.ID, signalName)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", executable.Name, err))
+			status = executable
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := printStatuses(o.stdout, o.output, statuses); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o *cli) deploy(args []string) error {
+	flagSet := o.flagSet("deploy")
+	version := flagSet.String("version", "", "Version of the binary, the beginning of its SHA-256 by default")
+	watch := flagSet.Duration("watch", 0, "Time the restarted instances must stay up on the new version, 30s by default")
+
+	positional, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 && (len(positional) != 1 || *version == "") {
+		fmt.Fprintln(o.stderr, "deploy requires an executable and a binary or -version")
+		return ErrUsage
+	}
+
+	executables, err := o.client.Executables()
+	if err != nil {
+		return err
+	}
+	executables, err = resolve(executables, positional[:1])
+	if err != nil {
+		return err
+	}
+
+	var binary io.Reader
+	if len(positional) == 2 {
+		file, err := os.Open(positional[1])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		binary = file
+	}
+
+	// The result of a deployment that was rolled back is printed too, it tells which instance failed.
+	result, err := o.client.Deploy(executables[0].ID, binary, *version, *watch)
+	if result.Executable != "" {
+		if printErr := printDeploy(o.stdout, o.output, result); printErr != nil {
+			return printErr
+		}
+	}
+
+	return err
+}
+
+func (o *cli) history(args []string) error {
+	flagSet := o.flagSet("history")
+
+	positional, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(o.stderr, "history requires an executable")
+		return ErrUsage
+	}
+
+	executables, err := o.client.Executables()
+	if err != nil {
+		return err
+	}
+	executables, err = resolve(executables, positional)
+	if err != nil {
+		return err
+	}
+
+	return printHistory(o.stdout, o.output, executables[0].Deployment)
+}
+
+func (o *cli) runAction(args []string) error {
+	flagSet := o.flagSet("action")
+
+	positional, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		fmt.Fprintln(o.stderr, "action requires an executable and an action")
+		return ErrUsage
+	}
+
+	executables, err := o.client.Executables()
+	if err != nil {
+		return err
+	}
+	executables, err = resolve(executables, positional[:1])
+	if err != nil {
+		return err
+	}
+
+	// The result of a failed command is printed too, its output usually tells why it failed.
+	result, err := o.client.RunAction(executables[0].ID, positional[1])
+	if result.Action != "" {
+		if printErr := printActionResult(o.stdout, o.output, result); printErr != nil {
+			return printErr
+		}
+	}
+
+	return err
+}
+
+func (o *cli) logs(args []string) error {
+	flagSet := o.flagSet("logs")
+	logsType := flagSet.String("type", "out", "Type of logs: out, errors or build")
+	offset := flagSet.Int("offset", 0, "Offset of the log file, 0 is the most recent")
+	follow := flagSet.Bool("f", false, "Follow the most recent log file")
+
+	names, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		fmt.Fprintln(o.stderr, "logs requires exactly one executable")
+		return ErrUsage
+	}
+
+	executables, err := o.client.Executables()
+	if err != nil {
+		return err
+	}
+	executables, err = resolve(executables, names)
+	if err != nil {
+		return err
+	}
+	id := executables[0].ID
+
+	// Following starts with the whole most recent file, an older one is printed before it.
+	if !*follow || *offset != 0 {
+		logs, err := o.client.Logs(id, *logsType, *offset)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(o.stdout, logs)
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return o.client.FollowLogs(ctx, id, *logsType, true, o.stdout)
+}
+
+func (o *cli) reload(args []string) error {
+	flagSet := o.flagSet("reload")
+
+	positional, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fmt.Fprintln(o.stderr, "reload does not accept arguments")
+		return ErrUsage
+	}
+
+	statuses, err := o.client.Reload()
+	if err != nil {
+		return err
+	}
+
+	return printStatuses(o.stdout, o.output, statuses)
+}
+
+func (o *cli) validate(args []string) error {
+	flagSet := o.flagSet("validate")
+	format := flagSet.String("format", "", "Format of the configuration: json, yaml or toml, from the file extension by default")
+
+	files, err := o.parse(flagSet, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		fmt.Fprintln(o.stderr, "validate requires exactly one file")
+		return ErrUsage
+	}
+
+	var content io.Reader = os.Stdin
+	if files[0] != "-" {
+		file, err := os.Open(files[0])
+		if err != nil {
+			return errors.New("error opening config file: " + err.Error())
+		}
+		defer file.Close()
+		content = file
+	}
+
+	if *format == "" {
+		*format = orchestrator.ConfigurationFormat(files[0])
+	}
+
+	report, err := o.client.Validate(content, *format)
+	if err != nil {
+		return err
+	}
+
+	if err := printReport(o.stdout, o.output, report); err != nil {
+		return err
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("%w: configuration has %d issue(s)", ErrInvalid, len(report.Issues))
+	}
+
+	return nil
+}
+
+// resolve selects the executables by ID or name, keeping the order of the arguments.
+func resolve(statuses []orchestrator.Status, names []string) ([]orchestrator.Status, error) {
+	resolved := make([]orchestrator.Status, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, status := range statuses {
+			if status.ID == name || status.Name == name {
+				resolved = append(resolved, status)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: executable %s", ErrNotFound, name)
+		}
+	}
+
+	return resolved, nil
+}
+
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case errors.Is(err, ErrUnreachable):
+		return ExitUnreachable
+	case errors.Is(err, ErrForbidden):
+		return ExitForbidden
+	case errors.Is(err, ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, ErrConflict):
+		return ExitConflict
+	case errors.Is(err, ErrInvalid):
+		return ExitInvalid
+	default:
+		return ExitError
+	}
+}
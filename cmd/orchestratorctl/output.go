@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"orchestrator/internal/orchestrator"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+func printStatuses(w io.Writer, output string, statuses []orchestrator.Status) error {
+	switch output {
+	case OutputJSON:
+		return printJSON(w, statuses)
+	case OutputTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tID\tGROUP\tRUNNING\tSTATE\tPID\tAUTO RESTART")
+		for _, status := range statuses {
+			pid := "-"
+			if status.Running {
+				pid = strconv.Itoa(status.PID)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\t%t\n", status.Name, status.ID, status.Group, status.Running, status.State, pid, status.AutoRestart)
+		}
+		return tw.Flush()
+	default:
+		return errors.New("unknown output format: " + output)
+	}
+}
+
+func printReport(w io.Writer, output string, report orchestrator.ValidationReport) error {
+	if output == OutputJSON {
+		return printJSON(w, report)
+	}
+
+	if report.Valid {
+		_, err := fmt.Fprintln(w, "Configuration is valid")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tCOLUMN\tEXECUTABLE\tFIELD\tMESSAGE")
+	for _, issue := range report.Issues {
+		executable := issue.Executable
+		if executable == "" && issue.Index >= 0 {
+			executable = "#" + strconv.Itoa(issue.Index)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\n", issue.Line, issue.Column, executable, issue.Field, issue.Message)
+	}
+
+	return tw.Flush()
+}
+
+func printActionResult(w io.Writer, output string, result orchestrator.ActionResult) error {
+	if output == OutputJSON {
+		return printJSON(w, result)
+	}
+
+	if result.Signal != "" {
+		_, err := fmt.Fprintf(w, "Sent %s\n", result.Signal)
+		return err
+	}
+
+	_, err := fmt.Fprint(w, result.Output)
+	if err == nil && result.ExitCode != nil && *result.ExitCode != 0 {
+		_, err = fmt.Fprintf(w, "Exit code %d\n", *result.ExitCode)
+	}
+
+	return err
+}
+
+func printRollout(w io.Writer, output string, result orchestrator.RolloutResult) error {
+	if output == OutputJSON {
+		return printJSON(w, result)
+	}
+
+	return printMembers(w, result.Members)
+}
+
+func printDeploy(w io.Writer, output string, result orchestrator.DeployResult) error {
+	if output == OutputJSON {
+		return printJSON(w, result)
+	}
+
+	if result.Outcome == orchestrator.ReleaseOutcomeRolledBack {
+		fmt.Fprintf(w, "Version %s of %s rolled back to %s\n", result.Version, result.Executable, result.Previous)
+	} else {
+		fmt.Fprintf(w, "Deployed version %s of %s over %s\n", result.Version, result.Executable, result.Previous)
+	}
+
+	return printMembers(w, result.Instances)
+}
+
+func printMembers(w io.Writer, members []orchestrator.RolloutMember) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tBATCH\tSTATE\tERROR")
+	for _, member := range members {
+		batch := "-"
+		if member.Batch > 0 {
+			batch = strconv.Itoa(member.Batch)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", member.Name, member.ID, batch, member.State, member.Error)
+	}
+
+	return tw.Flush()
+}
+
+func printHistory(w io.Writer, output string, deployment *orchestrator.DeploymentStatus) error {
+	if output == OutputJSON {
+		return printJSON(w, deployment)
+	}
+	if deployment == nil {
+		_, err := fmt.Fprintln(w, "Never deployed")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tDEPLOYED AT\tOUTCOME\tSHA256\tERROR")
+	for _, release := range deployment.History {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", release.Version, release.DeployedAt.Format(time.RFC3339), release.Outcome, release.SHA256[:min(12, len(release.SHA256))], release.Error)
+	}
+
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
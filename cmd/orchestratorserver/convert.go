@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"orchestrator/internal/orchestrator"
+	"os"
+)
+
+// convert translates a configuration file between JSON, YAML and TOML. The formats default to the file extensions.
+func convert(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	from := flagSet.String("from", "", "Format of INPUT: json, yaml or toml (default: by extension)")
+	to := flagSet.String("to", "", "Format of OUTPUT: json, yaml or toml (default: by extension)")
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: orchestratorserver convert [-from FORMAT] [-to FORMAT] INPUT [OUTPUT]")
+		fmt.Fprintln(stderr, "Converts INPUT and writes it to OUTPUT, or to the standard output when OUTPUT is omitted.")
+		flagSet.PrintDefaults()
+	}
+
+	if err := flagSet.Parse(args); err != nil || flagSet.NArg() < 1 || flagSet.NArg() > 2 {
+		flagSet.Usage()
+		return 2
+	}
+
+	input, output := flagSet.Arg(0), flagSet.Arg(1)
+	if *from == "" {
+		*from = orchestrator.ConfigurationFormat(input)
+	}
+	if *to == "" {
+		if output == "" {
+			fmt.Fprintln(stderr, "Error: -to is required when writing to the standard output")
+			return 2
+		}
+		*to = orchestrator.ConfigurationFormat(output)
+	}
+
+	content, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error: "+err.Error())
+		return 2
+	}
+
+	converted, report, err := orchestrator.ConvertConfiguration(content, input, *from, *to)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error: "+err.Error())
+		return 2
+	}
+	if !report.Valid {
+		for _, issue := range report.Issues {
+			fmt.Fprintln(stderr, issue.String())
+		}
+		return 1
+	}
+
+	if output == "" {
+		_, _ = stdout.Write(converted)
+		return 0
+	}
+
+	if err := os.WriteFile(output, converted, 0644); err != nil {
+		fmt.Fprintln(stderr, "Error: "+err.Error())
+		return 2
+	}
+
+	return 0
+}
@@ -1,15 +1,39 @@
 package main
 
 import (
+	"orchestrator/internal/apigrpc"
+	"orchestrator/internal/apigrpc/pb"
 	"orchestrator/internal/apihttp"
 	"orchestrator/internal/apihttp/controllers"
+	"orchestrator/internal/auth"
 	"orchestrator/internal/orchestrator"
+
+	"google.golang.org/grpc"
 )
 
-func dependencies(instance *orchestrator.Orchestrator) *apihttp.Router {
+func dependencies(instance *orchestrator.Orchestrator, authorizer *auth.Authorizer) *apihttp.Router {
 	orchestrator := controllers.NewOrchestrator(instance)
+	orchestratorV2 := controllers.NewOrchestratorV2(instance)
+	audit := controllers.NewAudit(instance.Auditor)
+	webhooks := controllers.NewWebhooks(instance.Webhooks)
+	alerts := controllers.NewAlerts(instance.Alerts)
 
 	return apihttp.NewRouter(
 		orchestrator,
+		orchestratorV2,
+		audit,
+		webhooks,
+		alerts,
+		authorizer,
 	)
 }
+
+func grpcDependencies(instance *orchestrator.Orchestrator, authorizer *auth.Authorizer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(apigrpc.UnaryAuthorization(authorizer)),
+		grpc.ChainStreamInterceptor(apigrpc.StreamAuthorization(authorizer)),
+	)
+	pb.RegisterOrchestratorServer(server, apigrpc.NewServer(instance))
+
+	return server
+}
@@ -3,10 +3,14 @@ package main
 import (
 	"context"
 	"log"
+	"net"
+	"orchestrator/internal/apihttp"
+	"orchestrator/internal/auth"
 	"orchestrator/internal/config"
 	"orchestrator/internal/orchestrator"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -14,22 +18,60 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		os.Exit(convert(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	c := config.GetConfig()
 
 	instance := orchestrator.NewOrchestrator()
 
 	defer func() {
 		instance.LoggerCleanup()
+		instance.AuditorCleanup()
+		instance.AlertsCleanup()
+		instance.WebhooksCleanup()
 	}()
 
+	authorizer := auth.NewAuthorizer(c.UNIX_SOCKET_UIDS, c.UNIX_SOCKET_GIDS)
+
 	e := echo.New()
-	router := dependencies(instance)
+	router := dependencies(instance, authorizer)
 	router.Route(e)
 
 	go func() {
 		e.Logger.Fatal(e.Start(":" + c.HTTP_PORT))
 	}()
 
+	if c.GRPC_PORT != "" {
+		listener, err := net.Listen("tcp", ":"+c.GRPC_PORT)
+		if err != nil {
+			log.Fatal("Failed to listen on gRPC port: " + err.Error())
+		}
+
+		grpcServer := grpcDependencies(instance, authorizer)
+		go func() {
+			log.Fatal(grpcServer.Serve(listener))
+		}()
+		defer grpcServer.Stop()
+	}
+
+	if c.UNIX_SOCKET_PATH != "" {
+		mode, err := strconv.ParseUint(c.UNIX_SOCKET_MODE, 8, 32)
+		if err != nil {
+			log.Fatal("Invalid unix socket mode: " + err.Error())
+		}
+
+		unixSocketCleanup, err := apihttp.ServeUnixSocket(e, c.UNIX_SOCKET_PATH, os.FileMode(mode), instance.Logger)
+		if err != nil {
+			log.Fatal("Failed to serve unix socket: " + err.Error())
+		}
+		defer unixSocketCleanup()
+	}
+
 	time.Sleep(1 * time.Second)
 
 	go instance.ConsumeNotifications()
@@ -45,9 +87,10 @@ func main() {
 			log.Fatal("Failed to set orchestrator: " + err.Error())
 		}
 
+		// The executables that started keep running when others failed to.
 		err = instance.RunAll(ctx)
 		if err != nil {
-			log.Fatal("Failed to run all processes: " + err.Error())
+			log.Println("Failed to run all processes: " + err.Error())
 		}
 	}
 
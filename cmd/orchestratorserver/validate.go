@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"orchestrator/internal/config"
+	"orchestrator/internal/orchestrator"
+)
+
+// validate lints a configuration file without starting the server. It exits with 1 when the configuration is invalid.
+func validate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	output := flagSet.String("o", "text", "Output format: text or json")
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: orchestratorserver validate [-o text|json] [FILE]")
+		fmt.Fprintln(stderr, "Validates FILE, or EXECUTABLES_JSON_PATH when FILE is omitted.")
+		flagSet.PrintDefaults()
+	}
+
+	if err := flagSet.Parse(args); err != nil || flagSet.NArg() > 1 {
+		return 2
+	}
+
+	path := flagSet.Arg(0)
+	if path == "" {
+		path = config.GetConfig().EXECUTABLES_JSON_PATH
+	}
+
+	report, err := orchestrator.ValidateConfigurationFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error: "+err.Error())
+		return 2
+	}
+
+	switch *output {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	default:
+		for _, issue := range report.Issues {
+			fmt.Fprintln(stdout, issue.String())
+		}
+		if report.Valid {
+			fmt.Fprintln(stdout, path+": configuration is valid")
+		} else {
+			fmt.Fprintf(stdout, "%s: %d issue(s) found\n", path, len(report.Issues))
+		}
+	}
+
+	if !report.Valid {
+		return 1
+	}
+
+	return 0
+}
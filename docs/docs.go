@@ -15,37 +15,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/execlogs": {
+        "/api/v2/alerts": {
             "get": {
-                "description": "This endpoint tries to get the logs of an executable that is set in the orchestrator.",
+                "description": "This endpoint returns the pending and firing alerts, then the most recently resolved ones.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "alerts"
                 ],
-                "summary": "Get the logs of an executable",
+                "summary": "List the alerts",
                 "parameters": [
                     {
+                        "enum": [
+                            "pending",
+                            "firing",
+                            "resolved"
+                        ],
                         "type": "string",
-                        "format": "uuid",
-                        "description": "UUID of the executable to get logs",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Type of logs to get",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Offset of the logs to get",
-                        "name": "offset",
+                        "description": "Return alerts of this state only",
+                        "name": "state",
                         "in": "query"
                     }
                 ],
@@ -53,151 +42,221 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alerts.Alert"
+                            }
                         }
                     }
                 }
             }
         },
-        "/run": {
+        "/api/v2/alerts/rules": {
             "get": {
-                "description": "This endpoint tries to run an executable that is set in the orchestrator.",
+                "description": "This endpoint returns the alert rules that are evaluated.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "alerts"
                 ],
-                "summary": "Run an executable",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "UUID of the executable to run",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                "summary": "List the alert rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alerts.Rule"
+                            }
+                        }
                     }
+                }
+            }
+        },
+        "/api/v2/alerts/rules:reload": {
+            "post": {
+                "description": "This endpoint reads the alert rules and notifiers from alerts.json again and returns the rules. The alerts of removed rules are dropped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
                 ],
+                "summary": "Reload the alert rules",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alerts.Rule"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/runall": {
+        "/api/v2/config": {
             "get": {
-                "description": "This endpoint tries to run all the executables that are set in the orchestrator.",
+                "description": "This endpoint returns the configuration of every executable after its defaults, templates and variables are applied. When the executables are not set, the configuration files are resolved.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "v2"
                 ],
-                "summary": "Run all the executables",
+                "summary": "Get the resolved configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.ResolvedConfiguration"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/rungroup": {
-            "get": {
-                "description": "This endpoint tries to run a group of executables that are set in the orchestrator.",
+        "/api/v2/config:reload": {
+            "post": {
+                "description": "This endpoint applies the configuration file to the executables that are set. New executables are added, changed executables apply the new configuration on their next start and removed executables are unset.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
-                ],
-                "summary": "Run a group of executables",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Group name to run",
-                        "name": "group",
-                        "in": "query",
-                        "required": true
-                    }
+                    "v2"
                 ],
+                "summary": "Reload the configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/set": {
-            "get": {
-                "description": "This endpoint sets the executables in the orchestrator. In order to set the executables again, all processes must be stopped and unset.",
+        "/api/v2/config:validate": {
+            "post": {
+                "description": "This endpoint strictly decodes and validates the candidate configuration of the request body without applying it. The report lists every problem with its line and column.\nThe format is the format parameter, or else the one of the Content-Type (application/yaml or application/toml), JSON by default.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml",
+                    "application/toml"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "v2"
+                ],
+                "summary": "Validate a configuration",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "yaml",
+                            "toml"
+                        ],
+                        "type": "string",
+                        "description": "Format of the configuration",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Candidate configuration",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Configuration"
+                            }
+                        }
+                    }
                 ],
-                "summary": "Set the executables",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/orchestrator.ValidationReport"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.ValidationReport"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/status": {
+        "/api/v2/executables": {
             "get": {
-                "description": "This endpoint returns the status of the executables that are set in the orchestrator.",
+                "description": "This endpoint returns the status of every executable that is set in the orchestrator.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "v2"
                 ],
-                "summary": "Return the status of the executables",
+                "summary": "List the executables",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -211,29 +270,79 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint validates and adds an executable to the orchestrator without starting it. A body with an unknown field is rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Create an executable",
+                "parameters": [
+                    {
+                        "description": "Configuration of the executable",
+                        "name": "executable",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Configuration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/stop": {
+        "/api/v2/executables/{id}": {
             "get": {
-                "description": "This endpoint tries to stop an executable that is set in the orchestrator.",
+                "description": "This endpoint returns the status of an executable.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "v2"
                 ],
-                "summary": "Stops an executable",
+                "summary": "Get an executable",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "UUID of the executable to stop",
+                        "description": "UUID of the executable",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -241,111 +350,2950 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/stopall": {
-            "get": {
-                "description": "This endpoint tries to stop all the executables that are set in the orchestrator.",
+            },
+            "put": {
+                "description": "This endpoint validates and replaces the configuration of an executable. The response tells whether the configuration was applied immediately or will be applied on the next restart of the running executable. A body with an unknown field is rejected.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "v2"
+                ],
+                "summary": "Update an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Configuration of the executable",
+                        "name": "executable",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Configuration"
+                        }
+                    }
                 ],
-                "summary": "Stops all the executables",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/orchestrator.UpdateResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/stopgroup": {
-            "get": {
-                "description": "This endpoint tries to stop a group of executables that are set in the orchestrator.",
+            },
+            "delete": {
+                "description": "This endpoint removes a stopped executable from the orchestrator.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "v2"
                 ],
-                "summary": "Stops a group of executables",
+                "summary": "Delete an executable",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group name to stop",
-                        "name": "group",
-                        "in": "query",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/unset": {
-            "get": {
-                "description": "This endpoint unsets the executables in the orchestrator. In order to unset the executables, all processes must be stopped.",
+        "/api/v2/executables/{id}/actions/{action}:run": {
+            "post": {
+                "description": "This endpoint runs a named action of an executable and returns its result. A signal action requires the executable to be running. The output of a command action is returned, and its result is in the details of the error when it fails.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orchestrator"
+                    "v2"
+                ],
+                "summary": "Run an action of an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the action",
+                        "name": "action",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Unset the executables",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/orchestrator.ActionResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/dtos.GenericResponse"
+                            "$ref": "#/definitions/dtos.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dtos.GenericResponse": {
+        },
+        "/api/v2/executables/{id}/attach": {
+            "get": {
+                "description": "This endpoint upgrades to a WebSocket connected to the terminal of a running executable started with tty. The output, starting with the recent scrollback, is sent as binary frames. Binary frames from the client are written to the terminal, and text frames carry control messages: {\"type\": \"resize\", \"rows\": 40, \"cols\": 120} and {\"type\": \"input\", \"data\": \"...\"}. Any number of read-only attachments is allowed next to one interactive attachment.",
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Attach to the terminal of an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Only receive the output",
+                        "name": "readonly",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}/config": {
+            "get": {
+                "description": "This endpoint returns the configuration of an executable after its defaults, templates and variables are applied.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Get the resolved configuration of an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.ResolvedConfiguration"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}/logs": {
+            "get": {
+                "description": "This endpoint returns a log file of an executable. Offset 0 is the most recent file. With follow, the most recent file is streamed from its end, or from its start with from_start, until the client disconnects, and tailing continues in the new file when the executable is started again.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Get the logs of an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "out",
+                        "description": "Type of logs to get",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset of the logs to get",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Stream the most recent log file",
+                        "name": "follow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Follow from the start of the file",
+                        "name": "from_start",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:deploy": {
+            "post": {
+                "description": "This endpoint deploys the binary sent as application/octet-stream, with the version and watch query parameters, or with application/json a file on the host of the server or a version kept in the store. Without a body, the version query parameter references a version kept in the store. The previous binaries are kept in the store. A canary instance is restarted first, then the other running instances once it stayed up for the watch period. When an instance fails during its watch period, the previous version is restored and the result is returned in the details of a deploy_failed error.",
+                "consumes": [
+                    "application/json",
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Deploy a new version of the binary of an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of any instance of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Path or version to deploy",
+                        "name": "deploy",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.DeployRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version of an uploaded binary, the beginning of its SHA-256 by default",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Watch period of an uploaded binary, e.g. 1m",
+                        "name": "watch",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.DeployResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:pause": {
+            "post": {
+                "description": "This endpoint freezes the processes of a running executable, keeping their memory, and returns its status. The cgroup v2 freezer is used when available, SIGSTOP on its process group otherwise.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Pause an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:restart": {
+            "post": {
+                "description": "This endpoint stops an executable, waits for it to exit, starts it again and returns its status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Restart an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:resume": {
+            "post": {
+                "description": "This endpoint thaws the processes of a paused executable and returns its status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Resume an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:scale": {
+            "post": {
+                "description": "This endpoint changes the number of instances of a replicated executable and returns the status of its instances. Scaling down stops the instances with the highest indices first. New instances are started when the executable is running.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Scale an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of any instance of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Number of replicas",
+                        "name": "scale",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ScaleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:signal": {
+            "post": {
+                "description": "This endpoint sends a signal such as HUP, USR1, USR2 or QUIT to a running executable and returns its status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Send a signal to an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Signal to send",
+                        "name": "signal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SignalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:start": {
+            "post": {
+                "description": "This endpoint starts an executable and returns its status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Start an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables/{id}:stop": {
+            "post": {
+                "description": "This endpoint sends the graceful exit signal to an executable and returns its status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Stop an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables:set": {
+            "post": {
+                "description": "This endpoint loads the executables from the configuration into the orchestrator and returns their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Set the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables:start": {
+            "post": {
+                "description": "This endpoint tries to start all the executables and returns their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Start all the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables:stop": {
+            "post": {
+                "description": "This endpoint tries to stop all the executables and returns their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Stop all the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/executables:unset": {
+            "post": {
+                "description": "This endpoint removes the executables from the orchestrator. All executables must be stopped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Unset the executables",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}": {
+            "get": {
+                "description": "This endpoint returns the status of the executables of a group.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Get a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}:pause": {
+            "post": {
+                "description": "This endpoint pauses the running executables of a group and returns their status. The executables that are not running or already paused are skipped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Pause a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}:restart": {
+            "post": {
+                "description": "This endpoint restarts the running services of a group in batches of max_unavailable, starting the next batch once the restarted ones stayed up for min_uptime, and returns the state of every member. When a member fails to restart or exits within min_uptime, the remaining batches are not restarted and the result is returned in the details of a rollout_failed error.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Rolling restart of a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rolling restart options",
+                        "name": "options",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RollingRestartRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.RolloutResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}:resume": {
+            "post": {
+                "description": "This endpoint resumes the paused executables of a group and returns their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Resume a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}:run": {
+            "post": {
+                "description": "This endpoint starts the executables of a group, waits until every task of the group has finished and returns their aggregated outcome. Services of the group are started but not waited for. When the timeout elapses first, the result is returned with finished set to false.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Run a group and wait for its tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum time to wait, e.g. 10m",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.GroupResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}:signal": {
+            "post": {
+                "description": "This endpoint sends a signal such as HUP, USR1, USR2 or QUIT to the running executables of a group and returns their status. The executables that are not running are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Send a signal to a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Signal to send",
+                        "name": "signal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SignalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}:start": {
+            "post": {
+                "description": "This endpoint starts the executables of a group and returns their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Start a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/groups/{name}:stop": {
+            "post": {
+                "description": "This endpoint stops the executables of a group and returns their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Stop a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/silences": {
+            "get": {
+                "description": "This endpoint returns the silences that have not expired.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List the silences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alerts.Silence"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint mutes the notifications of the alerts of a rule, of a subject (an executable or a group), or of both, for a duration.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Create a silence",
+                "parameters": [
+                    {
+                        "description": "Matchers, duration and comment of the silence",
+                        "name": "silence",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SilenceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/alerts.Silence"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/silences/{id}": {
+            "delete": {
+                "description": "This endpoint removes a silence before it expires.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Delete a silence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID of the silence",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/webhooks": {
+            "get": {
+                "description": "This endpoint returns the registered webhooks, without their secrets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List the webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Webhook"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "This endpoint registers an endpoint that receives the events as JSON, signed in the X-Orchestrator-Signature header with HMAC-SHA256 of the body. A secret is generated when none is given. The response is the only one that contains the secret.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Endpoint, secret and filters of the webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Webhook"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/webhooks/{id}": {
+            "get": {
+                "description": "This endpoint returns a webhook, without its secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Webhook"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint removes a webhook. Its pending deliveries are abandoned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Unregister a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "This endpoint returns the deliveries of a webhook, the most recent first, with their attempts and outcome.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get the delivery log of a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Return deliveries of this status only",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return at most this number of deliveries",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Delivery"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/webhooks/{id}:ping": {
+            "post": {
+                "description": "This endpoint queues a ping event for a webhook and returns its delivery, to check that the endpoint is reachable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Ping a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Delivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "This endpoint returns the audit records of the control-plane actions, filtered by time, action and executable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Query the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Return records at or after this time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Return records at or before this time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return records of this action only",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return records that target this executable name or UUID",
+                        "name": "executable",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/audit.Record"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/config/validate": {
+            "post": {
+                "description": "This endpoint strictly decodes and validates the candidate configuration of the request body without applying it. The report lists every problem with its line and column.\nThe format is the format parameter, or else the one of the Content-Type (application/yaml or application/toml), JSON by default.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml",
+                    "application/toml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "v2"
+                ],
+                "summary": "Validate a configuration",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "yaml",
+                            "toml"
+                        ],
+                        "type": "string",
+                        "description": "Format of the configuration",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Candidate configuration",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Configuration"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.ValidationReport"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/orchestrator.ValidationReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/execlogs": {
+            "get": {
+                "description": "This endpoint tries to get the logs of an executable that is set in the orchestrator.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Get the logs of an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable to get logs",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Type of logs to get",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset of the logs to get",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/run": {
+            "get": {
+                "description": "This endpoint tries to run an executable that is set in the orchestrator. An executable that is already running is left running and succeeds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Run an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable to run",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runall": {
+            "get": {
+                "description": "This endpoint tries to run all the executables that are set in the orchestrator.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Run all the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rungroup": {
+            "get": {
+                "description": "This endpoint tries to run a group of executables that are set in the orchestrator.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Run a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name to run",
+                        "name": "group",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/set": {
+            "get": {
+                "description": "This endpoint sets the executables in the orchestrator. In order to set the executables again, all processes must be stopped and unset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Set the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "This endpoint returns the status of the executables that are set in the orchestrator.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Return the status of the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/orchestrator.Status"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stop": {
+            "get": {
+                "description": "This endpoint tries to stop an executable that is set in the orchestrator.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Stops an executable",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "UUID of the executable to stop",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stopall": {
+            "get": {
+                "description": "This endpoint tries to stop all the executables that are set in the orchestrator.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Stops all the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stopgroup": {
+            "get": {
+                "description": "This endpoint tries to stop a group of executables that are set in the orchestrator.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Stops a group of executables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name to stop",
+                        "name": "group",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/unset": {
+            "get": {
+                "description": "This endpoint unsets the executables in the orchestrator. In order to unset the executables, all processes must be stopped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orchestrator"
+                ],
+                "summary": "Unset the executables",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.GenericResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "alerts.Alert": {
+            "type": "object",
+            "properties": {
+                "executable_id": {
+                    "type": "string"
+                },
+                "fired_at": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "silenced": {
+                    "type": "boolean"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "alerts.Rule": {
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "type": "string"
+                },
+                "executable": {
+                    "description": "Executable selects an executable by name, every executable when it is empty.",
+                    "type": "string"
+                },
+                "for": {
+                    "description": "For is how long the condition must hold before the alert fires, such as 2m.",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "Group selects the executables of a group. It is required by group_running_below.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notifiers": {
+                    "description": "Notifiers are the names of the notifiers of the rule, every notifier when it is empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "integer"
+                },
+                "window": {
+                    "description": "Window is the duration restarts are counted over, such as 10m.",
+                    "type": "string"
+                }
+            }
+        },
+        "alerts.Silence": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "CreatedBy is the caller that created the silence.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Record": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "caller": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "executables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.Target"
+                    }
+                },
+                "result": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Target": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.DeployRequest": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                },
+                "watch": {
+                    "description": "Watch is how long the restarted instances must stay up on the new version, such as 1m. 30s by default.",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {},
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.GenericResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.RollingRestartRequest": {
+            "type": "object",
+            "properties": {
+                "max_unavailable": {
+                    "description": "MaxUnavailable is how many executables are restarted at once, 1 by default.",
+                    "type": "integer"
+                },
+                "min_uptime": {
+                    "description": "MinUptime is how long the restarted executables must stay up before the next batch, such as 30s. 10s by default.",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ScaleRequest": {
+            "type": "object",
+            "properties": {
+                "replicas": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.SignalRequest": {
+            "type": "object",
+            "properties": {
+                "signal": {
+                    "description": "Signal is a name such as HUP or SIGHUP.",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SilenceRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "Duration is how long the silence lasts, such as 2h.",
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.WebhookRequest": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries, one is generated when it is empty.",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "executable_id": {
+                    "type": "string"
+                },
+                "executable_name": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.Action": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "description": "Command is the binary and the arguments of the command.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "signal": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "Timeout is a duration such as 10s, 30s by default. It only applies to commands.",
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.ActionResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "description": "ExitCode and Output are only set for commands.",
+                    "type": "integer"
+                },
+                "output": {
+                    "type": "string"
+                },
+                "signal": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.Build": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "command": {
+                    "type": "string"
+                },
+                "inputs": {
+                    "description": "Inputs are glob patterns of the files the output is built from. Matched directories count with all their files.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "output": {
+                    "description": "Output is the artifact the command produces, usually the binary path.",
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "Timeout is a duration such as 2m, 5m by default.",
+                    "type": "string"
+                },
+                "working_dir": {
+                    "description": "WorkingDir is where the command runs, the working directory of the executable by default.",
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.Configuration": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "description": "Actions are the named control actions of the executable, e.g. reload: {signal: HUP}.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/orchestrator.Action"
+                    }
+                },
+                "arguments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auto_restart": {
+                    "type": "boolean"
+                },
+                "binary_path": {
+                    "type": "string"
+                },
+                "build": {
+                    "description": "Build produces the binary before every start, unless its inputs did not change.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.Build"
+                        }
+                    ]
+                },
+                "checksums_file": {
+                    "type": "string"
+                },
+                "concurrency_policy": {
+                    "type": "string"
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "error_file_name": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "hooks": {
+                    "$ref": "#/definitions/orchestrator.Hooks"
+                },
+                "log_dir": {
+                    "type": "string"
+                },
+                "log_file_name": {
+                    "type": "string"
+                },
+                "max_runtime": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "retries": {
+                    "type": "integer"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "SHA256, the entry of the binary in ChecksumsFile and the ed25519 signature in SignatureFile are verified before every start.",
+                    "type": "string"
+                },
+                "signature_file": {
+                    "type": "string"
+                },
+                "starting_deadline": {
+                    "description": "StartingDeadline is how late a run missed while the orchestrator was down may still start, such as 1h. Missed runs are not started by default.",
+                    "type": "string"
+                },
+                "success_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "tty": {
+                    "description": "TTY starts the executable with a pseudo-terminal that can be attached to. Its output, stderr included, goes to the out log file.",
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "watch": {
+                    "description": "Watch restarts the executable when its files change, optionally after a build.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.Watch"
+                        }
+                    ]
+                },
+                "working_dir": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.DeployResult": {
+            "type": "object",
+            "properties": {
+                "executable": {
+                    "type": "string"
+                },
+                "instances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orchestrator.RolloutMember"
+                    }
+                },
+                "outcome": {
+                    "description": "Outcome is deployed, or rolled_back when an instance failed on the new version and the previous one was restored.",
+                    "type": "string"
+                },
+                "previous": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.DeploymentStatus": {
+            "type": "object",
+            "properties": {
+                "deployed_at": {
+                    "type": "string"
+                },
+                "history": {
+                    "description": "History are the deployments, most recent first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orchestrator.Release"
+                    }
+                },
+                "version": {
+                    "description": "Version is the deployed version.",
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.GroupResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error reports the executables that could not be started. The tasks among them are failed.",
+                    "type": "string"
+                },
+                "finished": {
+                    "description": "Finished tells whether every task finished before the wait was cancelled.",
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "states": {
+                    "description": "States counts the tasks by state.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "succeeded": {
+                    "description": "Succeeded tells whether every executable started and every task succeeded.",
+                    "type": "boolean"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orchestrator.Status"
+                    }
+                }
+            }
+        },
+        "orchestrator.Hook": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "command": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "Timeout is a duration such as 10s, 30s by default.",
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.Hooks": {
+            "type": "object",
+            "properties": {
+                "post_start": {
+                    "$ref": "#/definitions/orchestrator.Hook"
+                },
+                "post_stop": {
+                    "$ref": "#/definitions/orchestrator.Hook"
+                },
+                "pre_start": {
+                    "$ref": "#/definitions/orchestrator.Hook"
+                },
+                "pre_stop": {
+                    "$ref": "#/definitions/orchestrator.Hook"
+                }
+            }
+        },
+        "orchestrator.Release": {
+            "type": "object",
+            "properties": {
+                "deployed_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "Outcome is deployed for a version that was kept, rolled_back for one that failed and was replaced by the previous one.",
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.ResolvedConfiguration": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "description": "Actions are the named control actions of the executable, e.g. reload: {signal: HUP}.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/orchestrator.Action"
+                    }
+                },
+                "arguments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "auto_restart": {
+                    "type": "boolean"
+                },
+                "binary_path": {
+                    "type": "string"
+                },
+                "build": {
+                    "description": "Build produces the binary before every start, unless its inputs did not change.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.Build"
+                        }
+                    ]
+                },
+                "checksums_file": {
+                    "type": "string"
+                },
+                "concurrency_policy": {
+                    "type": "string"
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "error_file_name": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "hooks": {
+                    "$ref": "#/definitions/orchestrator.Hooks"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "log_dir": {
+                    "type": "string"
+                },
+                "log_file_name": {
+                    "type": "string"
+                },
+                "max_runtime": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "replicas": {
+                    "type": "integer"
+                },
+                "retries": {
+                    "type": "integer"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "SHA256, the entry of the binary in ChecksumsFile and the ed25519 signature in SignatureFile are verified before every start.",
+                    "type": "string"
+                },
+                "signature_file": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "starting_deadline": {
+                    "description": "StartingDeadline is how late a run missed while the orchestrator was down may still start, such as 1h. Missed runs are not started by default.",
+                    "type": "string"
+                },
+                "success_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "tty": {
+                    "description": "TTY starts the executable with a pseudo-terminal that can be attached to. Its output, stderr included, goes to the out log file.",
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "watch": {
+                    "description": "Watch restarts the executable when its files change, optionally after a build.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.Watch"
+                        }
+                    ]
+                },
+                "working_dir": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.RolloutMember": {
             "type": "object",
             "properties": {
-                "message": {
+                "batch": {
+                    "description": "Batch is the 1-based batch of a restarted member, 0 for a skipped one.",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.RolloutResult": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed tells whether every batch was restarted and stayed up.",
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orchestrator.RolloutMember"
+                    }
+                }
+            }
+        },
+        "orchestrator.Run": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "pid": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.ScheduleStatus": {
+            "type": "object",
+            "properties": {
+                "concurrency_policy": {
+                    "type": "string"
+                },
+                "expression": {
+                    "type": "string"
+                },
+                "last_missed_at": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "missed_runs": {
+                    "type": "integer"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orchestrator.Run"
+                    }
+                },
+                "timezone": {
                     "type": "string"
                 }
             }
@@ -353,23 +3301,296 @@ const docTemplate = `{
         "orchestrator.Status": {
             "type": "object",
             "properties": {
+                "actions": {
+                    "description": "Actions are the names of the actions of the executable.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "auto_restart": {
                     "type": "boolean"
                 },
+                "binary_path": {
+                    "type": "string"
+                },
+                "build_error": {
+                    "description": "BuildError is set while the last build failed, and the executable is not started.",
+                    "type": "string"
+                },
+                "deployment": {
+                    "description": "Deployment is only set for executables that were deployed.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.DeploymentStatus"
+                        }
+                    ]
+                },
                 "group": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "index": {
+                    "type": "integer"
+                },
+                "integrity_error": {
+                    "description": "IntegrityError is set while the binary fails its verification, and the executable is not started.",
+                    "type": "string"
+                },
+                "log_dir": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "paused_at": {
+                    "description": "PausedAt is only set while the executable is paused.",
+                    "type": "string"
+                },
                 "pid": {
                     "type": "integer"
                 },
+                "replicas": {
+                    "type": "integer"
+                },
                 "running": {
                     "type": "boolean"
+                },
+                "schedule": {
+                    "description": "Schedule is only set for scheduled executables.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.ScheduleStatus"
+                        }
+                    ]
+                },
+                "source": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "task": {
+                    "description": "Task is only set for tasks.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.TaskStatus"
+                        }
+                    ]
+                },
+                "watch": {
+                    "description": "Watch is only set for executables with a watch.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.WatchStatus"
+                        }
+                    ]
+                },
+                "working_dir": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.TaskStatus": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.UpdateResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/orchestrator.Status"
+                }
+            }
+        },
+        "orchestrator.ValidationIssue": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "integer"
+                },
+                "executable": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "orchestrator.ValidationReport": {
+            "type": "object",
+            "properties": {
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orchestrator.ValidationIssue"
+                    }
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "orchestrator.Watch": {
+            "type": "object",
+            "properties": {
+                "build": {
+                    "description": "Build is a command run in the working directory before the restart, which is skipped when it fails.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/orchestrator.Hook"
+                        }
+                    ]
+                },
+                "debounce": {
+                    "description": "Debounce is a duration such as 500ms, 500ms by default.",
+                    "type": "string"
+                },
+                "exclude": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "include": {
+                    "description": "Include and Exclude are patterns such as *.go matched against file names. Without Include every file counts.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "paths": {
+                    "description": "Paths are the files and directories watched, directories recursively. The working directory by default.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "orchestrator.WatchStatus": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "description": "Changed are the files of the last change, at most WatchChangedFilesReported of them.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "changed_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is set when the paths cannot be watched, or the last build or restart failed.",
+                    "type": "string"
+                },
+                "paths": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "webhooks.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/events.Event"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhooks.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries. It is only returned when the webhook is created.",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         }
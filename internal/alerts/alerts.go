@@ -0,0 +1,552 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"orchestrator/internal/events"
+	"orchestrator/internal/logger"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	RulesFileName      = "alerts.json"
+	EvaluationInterval = 10 * time.Second
+	// ResolvedHistorySize is the number of resolved alerts that are kept.
+	ResolvedHistorySize = 100
+)
+
+var (
+	// ConditionDown holds while a selected executable is not running.
+	ConditionDown = "down"
+	// ConditionRestarts holds while a selected executable was restarted more than threshold times within the window.
+	ConditionRestarts = "restarts"
+	// ConditionGroupRunningBelow holds while fewer than threshold executables of the group are running.
+	ConditionGroupRunningBelow = "group_running_below"
+)
+
+var (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+var (
+	EventAlertFiring   = "alert_firing"
+	EventAlertResolved = "alert_resolved"
+	// EventRestartScheduled is the event of the orchestrator that restarts rules count.
+	EventRestartScheduled = "restart_scheduled"
+)
+
+var (
+	ErrInvalidRules = errors.New("invalid alert rules")
+)
+
+// Config is the content of alerts.json: the rules and the notifiers they send to.
+type Config struct {
+	Rules     []Rule     `json:"rules"`
+	Notifiers []Notifier `json:"notifiers"`
+}
+
+/*
+Rule is a condition on the executables. An alert is raised for every executable, or group, that meets the
+condition, and fires once the condition held for the duration of For.
+*/
+type Rule struct {
+	Name      string `json:"name"`
+	Condition string `json:"condition"`
+	// Executable selects an executable by name, every executable when it is empty.
+	Executable string `json:"executable,omitempty"`
+	// Group selects the executables of a group. It is required by group_running_below.
+	Group     string `json:"group,omitempty"`
+	Threshold int    `json:"threshold,omitempty"`
+	// Window is the duration restarts are counted over, such as 10m.
+	Window string `json:"window,omitempty"`
+	// For is how long the condition must hold before the alert fires, such as 2m.
+	For      string `json:"for,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	// Notifiers are the names of the notifiers of the rule, every notifier when it is empty.
+	Notifiers []string `json:"notifiers,omitempty"`
+}
+
+// Target is an executable as seen by the rules.
+type Target struct {
+	ID      string
+	Name    string
+	Group   string
+	Running bool
+}
+
+/*
+Alert is the state of a rule for an executable or a group, its subject. An alert is pending while its condition
+holds for less than the duration of its rule, firing afterwards and resolved once the condition no longer holds.
+Notifiers are told when it fires and when it is resolved, unless it is silenced.
+*/
+type Alert struct {
+	ID           string     `json:"id"`
+	Rule         string     `json:"rule"`
+	Subject      string     `json:"subject"`
+	ExecutableID string     `json:"executable_id,omitempty"`
+	Group        string     `json:"group,omitempty"`
+	Severity     string     `json:"severity,omitempty"`
+	State        string     `json:"state"`
+	Message      string     `json:"message"`
+	Silenced     bool       `json:"silenced"`
+	StartedAt    time.Time  `json:"started_at"`
+	FiredAt      *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Engine evaluates the rules against the executables on an interval and notifies the alerts that fire and resolve.
+type Engine struct {
+	mu       sync.Mutex
+	dir      string
+	logger   *log.Logger
+	bus      *events.Bus
+	client   *http.Client
+	targets  func() []Target
+	config   Config
+	silences []Silence
+	alerts   map[string]*Alert
+	resolved []Alert
+	restarts map[string][]time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// observation is a subject that meets the condition of a rule.
+type observation struct {
+	rule         Rule
+	subject      string
+	executableID string
+	group        string
+	message      string
+}
+
+func NewEngine(dir string, bus *events.Bus, targets func() []Target, logger *log.Logger) (*Engine, func(), error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, errors.New("error creating alerts directory: " + err.Error())
+	}
+
+	engine := &Engine{
+		dir:      dir,
+		logger:   logger,
+		bus:      bus,
+		client:   &http.Client{Timeout: NotificationTimeout},
+		targets:  targets,
+		alerts:   make(map[string]*Alert),
+		restarts: make(map[string][]time.Time),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	config, err := engine.loadRules()
+	if err != nil {
+		return nil, nil, err
+	}
+	engine.config = config
+
+	if err := engine.loadSilences(); err != nil {
+		return nil, nil, err
+	}
+
+	subscription, cancel := bus.Subscribe()
+	go engine.consume(subscription)
+	go engine.run()
+
+	cleanup := func() {
+		cancel()
+		close(engine.stop)
+		<-engine.done
+	}
+
+	return engine, cleanup, nil
+}
+
+// Alerts returns the pending and firing alerts, then the most recent resolved ones, optionally of a state only.
+func (o *Engine) Alerts(state string) []Alert {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(o.alerts)+len(o.resolved))
+	for _, alert := range o.alerts {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].StartedAt.Equal(alerts[j].StartedAt) {
+			return alerts[i].ID < alerts[j].ID
+		}
+		return alerts[i].StartedAt.After(alerts[j].StartedAt)
+	})
+	alerts = append(alerts, o.resolved...)
+
+	if state == "" {
+		return alerts
+	}
+
+	return slices.DeleteFunc(alerts, func(alert Alert) bool { return alert.State != state })
+}
+
+// Rules returns the rules that are evaluated.
+func (o *Engine) Rules() []Rule {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]Rule{}, o.config.Rules...)
+}
+
+// Reload reads the rules and notifiers again. The alerts of rules that no longer exist are dropped.
+func (o *Engine) Reload() ([]Rule, error) {
+	config, err := o.loadRules()
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	o.config = config
+	for id, alert := range o.alerts {
+		if !slices.ContainsFunc(config.Rules, func(rule Rule) bool { return rule.Name == alert.Rule }) {
+			delete(o.alerts, id)
+		}
+	}
+	o.mu.Unlock()
+
+	o.logger.Printf(logger.LogInfo+"Reloaded %d alert rules", len(config.Rules))
+	o.evaluate()
+
+	return config.Rules, nil
+}
+
+// consume keeps the restarts that restarts rules count.
+func (o *Engine) consume(subscription <-chan events.Event) {
+	for event := range subscription {
+		if event.Type != EventRestartScheduled || event.ExecutableID == "" {
+			continue
+		}
+
+		o.mu.Lock()
+		o.restarts[event.ExecutableID] = append(o.restarts[event.ExecutableID], event.Timestamp)
+		o.mu.Unlock()
+	}
+}
+
+func (o *Engine) run() {
+	defer close(o.done)
+
+	ticker := time.NewTicker(EvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+			o.evaluate()
+		}
+	}
+}
+
+/*
+evaluate checks every rule against the executables. Subjects that meet a condition raise a pending alert, or keep
+their alert, which fires once the condition held long enough. Alerts whose condition no longer holds are resolved.
+*/
+func (o *Engine) evaluate() {
+	targets := o.targets()
+	now := time.Now()
+
+	o.mu.Lock()
+
+	o.pruneRestarts(now)
+	o.pruneSilences(now)
+
+	observed := make(map[string]bool)
+	var notifications []Alert
+	for _, rule := range o.config.Rules {
+		for _, observation := range rule.observe(targets, o.restarts, now) {
+			id := rule.Name + "/" + observation.subject
+			observed[id] = true
+
+			alert, ok := o.alerts[id]
+			if !ok {
+				alert = &Alert{
+					ID:           id,
+					Rule:         rule.Name,
+					Subject:      observation.subject,
+					ExecutableID: observation.executableID,
+					Group:        observation.group,
+					Severity:     rule.Severity,
+					State:        StatePending,
+					StartedAt:    now,
+				}
+				o.alerts[id] = alert
+			}
+			alert.Message = observation.message
+			alert.Silenced = o.silenced(*alert, now)
+
+			if alert.State == StatePending && now.Sub(alert.StartedAt) >= duration(rule.For) {
+				firedAt := now
+				alert.State = StateFiring
+				alert.FiredAt = &firedAt
+				notifications = append(notifications, *alert)
+			}
+		}
+	}
+
+	for id, alert := range o.alerts {
+		if observed[id] {
+			continue
+		}
+
+		delete(o.alerts, id)
+		if alert.State != StateFiring {
+			continue
+		}
+
+		resolvedAt := now
+		alert.State = StateResolved
+		alert.ResolvedAt = &resolvedAt
+		alert.Silenced = o.silenced(*alert, now)
+		o.resolved = append([]Alert{*alert}, o.resolved...)
+		if len(o.resolved) > ResolvedHistorySize {
+			o.resolved = o.resolved[:ResolvedHistorySize]
+		}
+		notifications = append(notifications, *alert)
+	}
+
+	config := o.config
+	o.mu.Unlock()
+
+	for _, alert := range notifications {
+		o.announce(config, alert)
+	}
+}
+
+// announce publishes the alert that fired or resolved and sends it to the notifiers of its rule, unless it is silenced.
+func (o *Engine) announce(config Config, alert Alert) {
+	eventType := EventAlertFiring
+	if alert.State == StateResolved {
+		eventType = EventAlertResolved
+	}
+
+	o.logger.Printf(logger.LogInfo+"Alert %s is %s: %s", alert.ID, alert.State, alert.Message)
+	o.bus.Publish(events.Event{
+		Type:         eventType,
+		ExecutableID: alert.ExecutableID,
+		Group:        alert.Group,
+		Message:      alert.Message,
+		Attributes: map[string]string{
+			"rule":     alert.Rule,
+			"subject":  alert.Subject,
+			"severity": alert.Severity,
+			"silenced": strconv.FormatBool(alert.Silenced),
+		},
+	})
+
+	if alert.Silenced {
+		return
+	}
+
+	rule := config.rule(alert.Rule)
+	for _, notifier := range config.Notifiers {
+		if len(rule.Notifiers) > 0 && !slices.Contains(rule.Notifiers, notifier.Name) {
+			continue
+		}
+
+		go func() {
+			if err := o.notify(notifier, alert); err != nil {
+				o.logger.Printf(logger.LogErr+"Error notifying %s of alert %s: %s", notifier.Name, alert.ID, err.Error())
+			}
+		}()
+	}
+}
+
+// observe returns the subjects that meet the condition of the rule.
+func (o Rule) observe(targets []Target, restarts map[string][]time.Time, now time.Time) []observation {
+	var observations []observation
+
+	switch o.Condition {
+	case ConditionDown:
+		for _, target := range targets {
+			if o.selects(target) && !target.Running {
+				observations = append(observations, observation{
+					rule:         o,
+					subject:      target.Name,
+					executableID: target.ID,
+					group:        target.Group,
+					message:      fmt.Sprintf("executable %s is down", target.Name),
+				})
+			}
+		}
+	case ConditionRestarts:
+		window := duration(o.Window)
+		for _, target := range targets {
+			if !o.selects(target) {
+				continue
+			}
+
+			count := 0
+			for _, restart := range restarts[target.ID] {
+				if now.Sub(restart) <= window {
+					count++
+				}
+			}
+			if count > o.Threshold {
+				observations = append(observations, observation{
+					rule:         o,
+					subject:      target.Name,
+					executableID: target.ID,
+					group:        target.Group,
+					message:      fmt.Sprintf("executable %s was restarted %d times in %s", target.Name, count, o.Window),
+				})
+			}
+		}
+	case ConditionGroupRunningBelow:
+		total, running := 0, 0
+		for _, target := range targets {
+			if target.Group != o.Group {
+				continue
+			}
+			total++
+			if target.Running {
+				running++
+			}
+		}
+		if running < o.Threshold {
+			observations = append(observations, observation{
+				rule:    o,
+				subject: o.Group,
+				group:   o.Group,
+				message: fmt.Sprintf("group %s has %d of %d executables running, fewer than %d", o.Group, running, total, o.Threshold),
+			})
+		}
+	}
+
+	return observations
+}
+
+func (o Rule) selects(target Target) bool {
+	return (o.Executable == "" || o.Executable == target.Name) && (o.Group == "" || o.Group == target.Group)
+}
+
+func (o Rule) validate(notifiers []Notifier) error {
+	var errs []error
+	invalid := func(message string) {
+		errs = append(errs, fmt.Errorf("%w: rule %s: %s", ErrInvalidRules, o.Name, message))
+	}
+
+	if o.Name == "" {
+		invalid("name is required")
+	}
+	switch o.Condition {
+	case ConditionDown:
+	case ConditionRestarts:
+		if o.Threshold < 0 {
+			invalid("threshold must not be negative")
+		}
+		if _, err := time.ParseDuration(o.Window); err != nil || duration(o.Window) <= 0 {
+			invalid("window must be a positive duration such as 10m: " + o.Window)
+		}
+	case ConditionGroupRunningBelow:
+		if o.Group == "" {
+			invalid("group is required")
+		}
+		if o.Threshold <= 0 {
+			invalid("threshold must be positive")
+		}
+	default:
+		invalid(fmt.Sprintf("condition must be %s, %s or %s: %s", ConditionDown, ConditionRestarts, ConditionGroupRunningBelow, o.Condition))
+	}
+	if _, err := time.ParseDuration(o.For); o.For != "" && (err != nil || duration(o.For) < 0) {
+		invalid("for must be a duration such as 2m: " + o.For)
+	}
+	for _, name := range o.Notifiers {
+		if !slices.ContainsFunc(notifiers, func(notifier Notifier) bool { return notifier.Name == name }) {
+			invalid("unknown notifier: " + name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o Config) validate() error {
+	var errs []error
+	names := make(map[string]bool, len(o.Rules))
+	for _, rule := range o.Rules {
+		if names[rule.Name] {
+			errs = append(errs, fmt.Errorf("%w: rule %s: duplicate name", ErrInvalidRules, rule.Name))
+		}
+		names[rule.Name] = true
+		errs = append(errs, rule.validate(o.Notifiers))
+	}
+
+	names = make(map[string]bool, len(o.Notifiers))
+	for _, notifier := range o.Notifiers {
+		if names[notifier.Name] {
+			errs = append(errs, fmt.Errorf("%w: notifier %s: duplicate name", ErrInvalidRules, notifier.Name))
+		}
+		names[notifier.Name] = true
+		errs = append(errs, notifier.validate())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o Config) rule(name string) Rule {
+	for _, rule := range o.Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+
+	return Rule{}
+}
+
+// loadRules reads alerts.json. Without the file, there are no rules.
+func (o *Engine) loadRules() (Config, error) {
+	var config Config
+
+	content, err := os.ReadFile(filepath.Join(o.dir, RulesFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return config, nil
+	case err != nil:
+		return config, errors.New("error reading alert rules: " + err.Error())
+	}
+
+	if err := json.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("%w: %s", ErrInvalidRules, err.Error())
+	}
+
+	return config, config.validate()
+}
+
+// pruneRestarts forgets the restarts that are older than the longest window of the rules.
+func (o *Engine) pruneRestarts(now time.Time) {
+	var window time.Duration
+	for _, rule := range o.config.Rules {
+		window = max(window, duration(rule.Window))
+	}
+
+	for id, restarts := range o.restarts {
+		restarts = slices.DeleteFunc(restarts, func(restart time.Time) bool { return now.Sub(restart) > window })
+		if len(restarts) == 0 {
+			delete(o.restarts, id)
+		} else {
+			o.restarts[id] = restarts
+		}
+	}
+}
+
+func duration(value string) time.Duration {
+	parsed, _ := time.ParseDuration(value)
+
+	return parsed
+}
@@ -0,0 +1,149 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"orchestrator/internal/webhooks"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	NotifierSMTP    = "smtp"
+	NotifierWebhook = "webhook"
+)
+
+var (
+	NotificationTimeout = 10 * time.Second
+	DefaultSMTPPort     = 25
+)
+
+/*
+Notifier sends the alerts that fire and resolve. An smtp notifier mails them from From to To through Host, with
+plain authentication when a username is set. A webhook notifier posts them as JSON to URL, signed like the
+deliveries of the webhooks when a secret is set.
+*/
+type Notifier struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	Host     string   `json:"host,omitempty"`
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+
+	URL    string `json:"url,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+func (o *Engine) notify(notifier Notifier, alert Alert) error {
+	switch notifier.Type {
+	case NotifierSMTP:
+		return notifier.mail(alert)
+	case NotifierWebhook:
+		return o.post(notifier, alert)
+	default:
+		return errors.New("unknown notifier type: " + notifier.Type)
+	}
+}
+
+func (o Notifier) mail(alert Alert) error {
+	port := o.Port
+	if port == 0 {
+		port = DefaultSMTPPort
+	}
+	address := net.JoinHostPort(o.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if o.Username != "" {
+		auth = smtp.PlainAuth("", o.Username, o.Password, o.Host)
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", o.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(o.To, ", "))
+	fmt.Fprintf(&message, "Subject: [%s] %s: %s\r\n", strings.ToUpper(alert.State), alert.Rule, alert.Subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&message, "%s\r\n\r\n", alert.Message)
+	fmt.Fprintf(&message, "Rule: %s\r\nSubject: %s\r\nSeverity: %s\r\nState: %s\r\nStarted at: %s\r\n",
+		alert.Rule, alert.Subject, alert.Severity, alert.State, alert.StartedAt.Format(time.RFC3339))
+	if alert.ResolvedAt != nil {
+		fmt.Fprintf(&message, "Resolved at: %s\r\n", alert.ResolvedAt.Format(time.RFC3339))
+	}
+
+	return smtp.SendMail(address, auth, o.From, o.To, []byte(message.String()))
+}
+
+func (o *Engine) post(notifier Notifier, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.New("error encoding alert: " + err.Error())
+	}
+
+	request, err := http.NewRequest(http.MethodPost, notifier.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("error creating request: " + err.Error())
+	}
+
+	eventType := EventAlertFiring
+	if alert.State == StateResolved {
+		eventType = EventAlertResolved
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "orchestrator-alerts")
+	request.Header.Set(webhooks.HeaderEvent, eventType)
+	if notifier.Secret != "" {
+		webhooks.SignRequest(request, notifier.Secret, body)
+	}
+
+	response, err := o.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return errors.New("endpoint answered with status " + strconv.Itoa(response.StatusCode))
+	}
+
+	return nil
+}
+
+func (o Notifier) validate() error {
+	var errs []error
+	invalid := func(message string) {
+		errs = append(errs, fmt.Errorf("%w: notifier %s: %s", ErrInvalidRules, o.Name, message))
+	}
+
+	if o.Name == "" {
+		invalid("name is required")
+	}
+	switch o.Type {
+	case NotifierSMTP:
+		if o.Host == "" {
+			invalid("host is required")
+		}
+		if o.From == "" || len(o.To) == 0 {
+			invalid("from and to are required")
+		}
+	case NotifierWebhook:
+		endpoint, err := url.Parse(o.URL)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			invalid("url must be an absolute http or https URL: " + o.URL)
+		}
+	default:
+		invalid(fmt.Sprintf("type must be %s or %s: %s", NotifierSMTP, NotifierWebhook, o.Type))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,160 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	SilencesFileName = "silences.json"
+)
+
+var (
+	ErrSilenceNotFound = errors.New("silence not found")
+	ErrInvalidSilence  = errors.New("invalid silence")
+)
+
+// Silence mutes the notifications of the alerts of a rule, of a subject, or of both, until it expires.
+type Silence struct {
+	ID      string `json:"id"`
+	Rule    string `json:"rule,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	// CreatedBy is the caller that created the silence.
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Silences returns the silences that have not expired.
+func (o *Engine) Silences() []Silence {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pruneSilences(time.Now())
+
+	return append([]Silence{}, o.silences...)
+}
+
+// Silence adds a silence that expires after the duration. The alerts it matches are silenced right away.
+func (o *Engine) Silence(silence Silence, duration time.Duration) (Silence, error) {
+	if silence.Rule == "" && silence.Subject == "" {
+		return Silence{}, fmt.Errorf("%w: rule or subject is required", ErrInvalidSilence)
+	}
+	if duration <= 0 {
+		return Silence{}, fmt.Errorf("%w: duration must be positive", ErrInvalidSilence)
+	}
+
+	now := time.Now().UTC()
+	silence.ID = uuid.New().String()
+	silence.CreatedAt = now
+	silence.ExpiresAt = now.Add(duration)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pruneSilences(now)
+	o.silences = append(o.silences, silence)
+	if err := o.saveSilences(); err != nil {
+		o.silences = o.silences[:len(o.silences)-1]
+		return Silence{}, err
+	}
+
+	for _, alert := range o.alerts {
+		alert.Silenced = o.silenced(*alert, now)
+	}
+
+	return silence, nil
+}
+
+// Unsilence removes a silence before it expires.
+func (o *Engine) Unsilence(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	index := slices.IndexFunc(o.silences, func(silence Silence) bool { return silence.ID == id })
+	if index < 0 {
+		return ErrSilenceNotFound
+	}
+
+	previous := o.silences
+	o.silences = slices.Delete(slices.Clone(o.silences), index, index+1)
+	if err := o.saveSilences(); err != nil {
+		o.silences = previous
+		return err
+	}
+
+	now := time.Now()
+	for _, alert := range o.alerts {
+		alert.Silenced = o.silenced(*alert, now)
+	}
+
+	return nil
+}
+
+func (o Silence) matches(alert Alert) bool {
+	return (o.Rule == "" || o.Rule == alert.Rule) && (o.Subject == "" || o.Subject == alert.Subject)
+}
+
+// silenced tells whether a silence that has not expired matches the alert. The lock of the engine is held.
+func (o *Engine) silenced(alert Alert, now time.Time) bool {
+	return slices.ContainsFunc(o.silences, func(silence Silence) bool {
+		return now.Before(silence.ExpiresAt) && silence.matches(alert)
+	})
+}
+
+// pruneSilences forgets the expired silences. The lock of the engine is held.
+func (o *Engine) pruneSilences(now time.Time) {
+	silences := slices.DeleteFunc(slices.Clone(o.silences), func(silence Silence) bool { return !now.Before(silence.ExpiresAt) })
+	if len(silences) == len(o.silences) {
+		return
+	}
+
+	o.silences = silences
+	_ = o.saveSilences()
+}
+
+func (o *Engine) loadSilences() error {
+	content, err := os.ReadFile(filepath.Join(o.dir, SilencesFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return errors.New("error reading silences: " + err.Error())
+	}
+
+	if err := json.Unmarshal(content, &o.silences); err != nil {
+		return errors.New("error decoding silences: " + err.Error())
+	}
+
+	return nil
+}
+
+func (o *Engine) saveSilences() error {
+	silences := o.silences
+	if silences == nil {
+		silences = []Silence{}
+	}
+
+	content, err := json.MarshalIndent(silences, "", "    ")
+	if err != nil {
+		return errors.New("error encoding silences: " + err.Error())
+	}
+
+	path := filepath.Join(o.dir, SilencesFileName)
+	if err := os.WriteFile(path+".tmp", append(content, '\n'), 0644); err != nil {
+		return errors.New("error writing silences: " + err.Error())
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return errors.New("error writing silences: " + err.Error())
+	}
+
+	return nil
+}
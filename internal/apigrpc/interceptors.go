@@ -0,0 +1,63 @@
+package apigrpc
+
+import (
+	"context"
+	"net"
+	"orchestrator/internal/audit"
+	"orchestrator/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryAuthorization applies the decisions of the authorizer of the HTTP API and records the peer as the audit caller.
+func UnaryAuthorization(authorizer *auth.Authorizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, authorizer)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, request)
+	}
+}
+
+func StreamAuthorization(authorizer *auth.Authorizer) grpc.StreamServerInterceptor {
+	return func(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(stream.Context(), authorizer)
+		if err != nil {
+			return err
+		}
+
+		return handler(server, &authorizedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (o *authorizedStream) Context() context.Context {
+	return o.ctx
+}
+
+func authorize(ctx context.Context, authorizer *auth.Authorizer) (context.Context, error) {
+	callerPeer, ok := auth.PeerFromContext(ctx)
+	if !ok {
+		if grpcPeer, ok := peer.FromContext(ctx); ok {
+			callerPeer.RemoteAddr = grpcPeer.Addr.String()
+			if host, _, err := net.SplitHostPort(callerPeer.RemoteAddr); err == nil {
+				callerPeer.RemoteAddr = host
+			}
+		}
+	}
+
+	if err := authorizer.Authorize(callerPeer); err != nil {
+		return ctx, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return audit.WithCaller(ctx, callerPeer.Caller()), nil
+}
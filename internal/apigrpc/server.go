@@ -0,0 +1,264 @@
+package apigrpc
+
+import (
+	"context"
+	"errors"
+	"orchestrator/internal/apigrpc/pb"
+	"orchestrator/internal/orchestrator"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Server struct {
+	pb.UnimplementedOrchestratorServer
+	instance orchestrator.OrchestratorInterface
+}
+
+func NewServer(
+	instance orchestrator.OrchestratorInterface,
+) *Server {
+	return &Server{
+		instance: instance,
+	}
+}
+
+func (o *Server) Set(ctx context.Context, request *pb.SetRequest) (*pb.SetResponse, error) {
+	if err := o.instance.Set(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.SetResponse{}, nil
+}
+
+func (o *Server) Unset(ctx context.Context, request *pb.UnsetRequest) (*pb.UnsetResponse, error) {
+	if err := o.instance.Unset(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.UnsetResponse{}, nil
+}
+
+func (o *Server) Status(ctx context.Context, request *pb.StatusRequest) (*pb.StatusResponse, error) {
+	statuses, err := o.instance.Status(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.StatusResponse{Executables: make([]*pb.ExecutableStatus, 0, len(statuses))}
+	for _, executableStatus := range statuses {
+		status := &pb.ExecutableStatus{
+			Id:          executableStatus.ID,
+			Name:        executableStatus.Name,
+			Pid:         int64(executableStatus.PID),
+			Running:     executableStatus.Running,
+			State:       executableStatus.State,
+			AutoRestart: executableStatus.AutoRestart,
+			Group:       executableStatus.Group,
+			Source:      executableStatus.Source,
+			BinaryPath:  executableStatus.BinaryPath,
+			WorkingDir:  executableStatus.WorkingDir,
+			LogDir:      executableStatus.LogDir,
+			Actions:     executableStatus.Actions,
+		}
+		status.IntegrityError = executableStatus.IntegrityError
+		status.BuildError = executableStatus.BuildError
+		if deployment := executableStatus.Deployment; deployment != nil {
+			status.Version = deployment.Version
+		}
+		if schedule := executableStatus.Schedule; schedule != nil {
+			status.Schedule = schedule.Expression
+			status.MissedRuns = int64(schedule.MissedRuns)
+			if schedule.NextRunAt != nil {
+				status.NextRunAtUnixNano = schedule.NextRunAt.UnixNano()
+			}
+			if schedule.LastRunAt != nil {
+				status.LastRunAtUnixNano = schedule.LastRunAt.UnixNano()
+			}
+		}
+		response.Executables = append(response.Executables, status)
+	}
+
+	return response, nil
+}
+
+func (o *Server) RunAll(ctx context.Context, request *pb.RunAllRequest) (*pb.RunAllResponse, error) {
+	if err := o.instance.RunAll(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.RunAllResponse{}, nil
+}
+
+func (o *Server) RunGroup(ctx context.Context, request *pb.RunGroupRequest) (*pb.RunGroupResponse, error) {
+	if err := o.instance.RunGroup(ctx, request.GetGroup()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.RunGroupResponse{}, nil
+}
+
+func (o *Server) Run(ctx context.Context, request *pb.RunRequest) (*pb.RunResponse, error) {
+	executableUUID, err := parseID(request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.instance.Run(ctx, executableUUID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.RunResponse{}, nil
+}
+
+func (o *Server) StopAll(ctx context.Context, request *pb.StopAllRequest) (*pb.StopAllResponse, error) {
+	if err := o.instance.StopAll(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.StopAllResponse{}, nil
+}
+
+func (o *Server) StopGroup(ctx context.Context, request *pb.StopGroupRequest) (*pb.StopGroupResponse, error) {
+	if err := o.instance.StopGroup(ctx, request.GetGroup()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.StopGroupResponse{}, nil
+}
+
+func (o *Server) Stop(ctx context.Context, request *pb.StopRequest) (*pb.StopResponse, error) {
+	executableUUID, err := parseID(request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.instance.Stop(ctx, executableUUID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.StopResponse{}, nil
+}
+
+func (o *Server) ExecLogs(ctx context.Context, request *pb.ExecLogsRequest) (*pb.ExecLogsResponse, error) {
+	executableUUID, err := parseID(request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := o.instance.ExecLogs(ctx, request.GetType(), executableUUID, int(request.GetOffset()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ExecLogsResponse{Logs: logs}, nil
+}
+
+func (o *Server) WatchEvents(request *pb.WatchEventsRequest, stream pb.Orchestrator_WatchEventsServer) error {
+	subscription, cancel := o.instance.Subscribe()
+	defer cancel()
+
+	types := make(map[string]bool, len(request.GetTypes()))
+	for _, eventType := range request.GetTypes() {
+		types[eventType] = true
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription:
+			if !ok {
+				return nil
+			}
+
+			if len(types) > 0 && !types[event.Type] {
+				continue
+			}
+			if request.GetGroup() != "" && event.Group != request.GetGroup() {
+				continue
+			}
+
+			err := stream.Send(&pb.Event{
+				TimestampUnixNano: event.Timestamp.UnixNano(),
+				Type:              event.Type,
+				ExecutableId:      event.ExecutableID,
+				ExecutableName:    event.ExecutableName,
+				Group:             event.Group,
+				Message:           event.Message,
+				Attributes:        event.Attributes,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (o *Server) TailLogs(request *pb.TailLogsRequest, stream pb.Orchestrator_TailLogsServer) error {
+	executableUUID, err := parseID(request.GetId())
+	if err != nil {
+		return err
+	}
+
+	chunks, err := o.instance.TailLogs(stream.Context(), request.GetType(), executableUUID, request.GetFromStart())
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for chunk := range chunks {
+		if err := stream.Send(&pb.LogChunk{Data: chunk}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseID(id string) (uuid.UUID, error) {
+	executableUUID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "cannot parse executable ID as UUID: "+err.Error())
+	}
+
+	return executableUUID, nil
+}
+
+// toStatus maps the errors of the orchestrator to gRPC status codes, as the HTTP API maps them to status codes.
+func toStatus(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, orchestrator.ErrExecutableNotFound),
+		errors.Is(err, orchestrator.ErrGroupNotFound),
+		errors.Is(err, orchestrator.ErrLogsNotFound),
+		errors.Is(err, orchestrator.ErrActionNotFound),
+		errors.Is(err, orchestrator.ErrVersionNotFound):
+		code = codes.NotFound
+	case errors.Is(err, orchestrator.ErrExecutablesAlreadySet),
+		errors.Is(err, orchestrator.ErrExecutableExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, orchestrator.ErrExecutablesNotSet),
+		errors.Is(err, orchestrator.ErrExecutableRunning),
+		errors.Is(err, orchestrator.ErrExecutableNotRunning),
+		errors.Is(err, orchestrator.ErrExecutablePaused),
+		errors.Is(err, orchestrator.ErrExecutableNotPaused),
+		errors.Is(err, orchestrator.ErrExecutableStopTimedOut),
+		errors.Is(err, orchestrator.ErrHookFailed),
+		errors.Is(err, orchestrator.ErrTerminalAttached),
+		errors.Is(err, orchestrator.ErrActionFailed),
+		errors.Is(err, orchestrator.ErrRolloutFailed),
+		errors.Is(err, orchestrator.ErrDeployFailed),
+		errors.Is(err, orchestrator.ErrIntegrity),
+		errors.Is(err, orchestrator.ErrBuildFailed):
+		code = codes.FailedPrecondition
+	case errors.Is(err, orchestrator.ErrRolloutInProgress),
+		errors.Is(err, orchestrator.ErrDeployInProgress):
+		code = codes.Aborted
+	case errors.Is(err, orchestrator.ErrInvalidConfiguration),
+		errors.Is(err, orchestrator.ErrInvalidArgument):
+		code = codes.InvalidArgument
+	}
+
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"orchestrator/internal/alerts"
+	"orchestrator/internal/apihttp/dtos"
+	"orchestrator/internal/audit"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AlertsInterface interface {
+	List(echoContext echo.Context) error
+	Rules(echoContext echo.Context) error
+	ReloadRules(echoContext echo.Context) error
+	ListSilences(echoContext echo.Context) error
+	CreateSilence(echoContext echo.Context) error
+	DeleteSilence(echoContext echo.Context) error
+}
+
+type Alerts struct {
+	engine *alerts.Engine
+}
+
+func NewAlerts(
+	engine *alerts.Engine,
+) *Alerts {
+	return &Alerts{
+		engine: engine,
+	}
+}
+
+// List godoc
+//
+//	@Summary		List the alerts
+//	@Description	This endpoint returns the pending and firing alerts, then the most recently resolved ones.
+//	@Tags			alerts
+//	@Produce		json
+//	@Param			state	query	string	false	"Return alerts of this state only"	Enums(pending, firing, resolved)
+//	@Success		200		{array}	alerts.Alert
+//	@Router			/api/v2/alerts [get]
+func (o *Alerts) List(echoContext echo.Context) error {
+	return echoContext.JSON(http.StatusOK, o.engine.Alerts(echoContext.QueryParam("state")))
+}
+
+// Rules godoc
+//
+//	@Summary		List the alert rules
+//	@Description	This endpoint returns the alert rules that are evaluated.
+//	@Tags			alerts
+//	@Produce		json
+//	@Success		200	{array}	alerts.Rule
+//	@Router			/api/v2/alerts/rules [get]
+func (o *Alerts) Rules(echoContext echo.Context) error {
+	return echoContext.JSON(http.StatusOK, o.engine.Rules())
+}
+
+// ReloadRules godoc
+//
+//	@Summary		Reload the alert rules
+//	@Description	This endpoint reads the alert rules and notifiers from alerts.json again and returns the rules. The alerts of removed rules are dropped.
+//	@Tags			alerts
+//	@Produce		json
+//	@Success		200	{array}		alerts.Rule
+//	@Failure		422	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/alerts/rules:reload [post]
+func (o *Alerts) ReloadRules(echoContext echo.Context) error {
+	rules, err := o.engine.Reload()
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, rules)
+}
+
+// ListSilences godoc
+//
+//	@Summary		List the silences
+//	@Description	This endpoint returns the silences that have not expired.
+//	@Tags			alerts
+//	@Produce		json
+//	@Success		200	{array}	alerts.Silence
+//	@Router			/api/v2/silences [get]
+func (o *Alerts) ListSilences(echoContext echo.Context) error {
+	return echoContext.JSON(http.StatusOK, o.engine.Silences())
+}
+
+// CreateSilence godoc
+//
+//	@Summary		Create a silence
+//	@Description	This endpoint mutes the notifications of the alerts of a rule, of a subject (an executable or a group), or of both, for a duration.
+//	@Tags			alerts
+//	@Accept			json
+//	@Produce		json
+//	@Param			silence	body		dtos.SilenceRequest	true	"Matchers, duration and comment of the silence"
+//	@Success		201		{object}	alerts.Silence
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/silences [post]
+func (o *Alerts) CreateSilence(echoContext echo.Context) error {
+	var request dtos.SilenceRequest
+	if err := echoContext.Bind(&request); err != nil {
+		return newErrorResponse(fmt.Errorf("%w: cannot decode silence: %s", alerts.ErrInvalidSilence, err.Error()))
+	}
+
+	duration, err := time.ParseDuration(request.Duration)
+	if err != nil {
+		return newErrorResponse(fmt.Errorf("%w: duration must be a duration such as 2h: %s", alerts.ErrInvalidSilence, request.Duration))
+	}
+
+	silence, err := o.engine.Silence(alerts.Silence{
+		Rule:      request.Rule,
+		Subject:   request.Subject,
+		Comment:   request.Comment,
+		CreatedBy: audit.CallerFromContext(echoContext.Request().Context()),
+	}, duration)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusCreated, silence)
+}
+
+// DeleteSilence godoc
+//
+//	@Summary		Delete a silence
+//	@Description	This endpoint removes a silence before it expires.
+//	@Tags			alerts
+//	@Produce		json
+//	@Param			id	path	string	true	"ID of the silence"	format(uuid)
+//	@Success		204
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/silences/{id} [delete]
+func (o *Alerts) DeleteSilence(echoContext echo.Context) error {
+	err := o.engine.Unsilence(echoContext.Param("id"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.NoContent(http.StatusNoContent)
+}
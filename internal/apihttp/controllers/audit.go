@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+	"orchestrator/internal/apihttp/dtos"
+	"orchestrator/internal/audit"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditInterface interface {
+	Query(echoContext echo.Context) error
+}
+
+type Audit struct {
+	auditor *audit.Auditor
+}
+
+func NewAudit(
+	auditor *audit.Auditor,
+) *Audit {
+	return &Audit{
+		auditor: auditor,
+	}
+}
+
+// Query godoc
+//
+//	@Summary		Query the audit log
+//	@Description	This endpoint returns the audit records of the control-plane actions, filtered by time, action and executable.
+//	@Tags			audit
+//	@Produce		json
+//	@Param			from		query		string	false	"Return records at or after this time"	format(date-time)
+//	@Param			to			query		string	false	"Return records at or before this time"	format(date-time)
+//	@Param			action		query		string	false	"Return records of this action only"
+//	@Param			executable	query		string	false	"Return records that target this executable name or UUID"
+//	@Success		200			{object}	[]audit.Record
+//	@Failure		400			{object}	dtos.GenericResponse
+//	@Failure		500			{object}	dtos.GenericResponse
+//	@Router			/audit [get]
+func (o *Audit) Query(echoContext echo.Context) error {
+	var err error
+	filter := audit.Filter{
+		Action:     echoContext.QueryParam("action"),
+		Executable: echoContext.QueryParam("executable"),
+	}
+
+	if from := echoContext.QueryParam("from"); from != "" {
+		filter.From, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, dtos.GenericResponse{Message: "Cannot parse from as RFC3339 time: " + err.Error()})
+		}
+	}
+
+	if to := echoContext.QueryParam("to"); to != "" {
+		filter.To, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, dtos.GenericResponse{Message: "Cannot parse to as RFC3339 time: " + err.Error()})
+		}
+	}
+
+	records, err := o.auditor.Query(filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, dtos.GenericResponse{Message: "Failed to query audit log: " + err.Error()})
+	}
+
+	return echoContext.JSON(http.StatusOK, records)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"orchestrator/internal/alerts"
+	"orchestrator/internal/apihttp/dtos"
+	"orchestrator/internal/orchestrator"
+	"orchestrator/internal/webhooks"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	ErrorCodeExecutablesAlreadySet = "executables_already_set"
+	ErrorCodeExecutablesNotSet     = "executables_not_set"
+	ErrorCodeExecutableNotFound    = "executable_not_found"
+	ErrorCodeExecutableExists      = "executable_exists"
+	ErrorCodeGroupNotFound         = "group_not_found"
+	ErrorCodeExecutableRunning     = "executable_running"
+	ErrorCodeExecutableNotRunning  = "executable_not_running"
+	ErrorCodeExecutablePaused      = "executable_paused"
+	ErrorCodeExecutableNotPaused   = "executable_not_paused"
+	ErrorCodeStopTimedOut          = "stop_timed_out"
+	ErrorCodeHookFailed            = "hook_failed"
+	ErrorCodeTerminalAttached      = "terminal_attached"
+	ErrorCodeActionNotFound        = "action_not_found"
+	ErrorCodeActionFailed          = "action_failed"
+	ErrorCodeRolloutFailed         = "rollout_failed"
+	ErrorCodeRolloutInProgress     = "rollout_in_progress"
+	ErrorCodeVersionNotFound       = "version_not_found"
+	ErrorCodeDeployFailed          = "deploy_failed"
+	ErrorCodeDeployInProgress      = "deploy_in_progress"
+	ErrorCodeIntegrityFailed       = "integrity_failed"
+	ErrorCodeBuildFailed           = "build_failed"
+	ErrorCodeInvalidConfiguration  = "invalid_configuration"
+	ErrorCodeInvalidArgument       = "invalid_argument"
+	ErrorCodeLogsNotFound          = "logs_not_found"
+	ErrorCodeWebhookNotFound       = "webhook_not_found"
+	ErrorCodeInvalidWebhook        = "invalid_webhook"
+	ErrorCodeSilenceNotFound       = "silence_not_found"
+	ErrorCodeInvalidSilence        = "invalid_silence"
+	ErrorCodeInvalidAlertRules     = "invalid_alert_rules"
+	ErrorCodeUnknownMethod         = "unknown_method"
+	ErrorCodeUnauthorized          = "unauthorized"
+	ErrorCodeInternal              = "internal"
+)
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var errorMappings = []errorMapping{
+	{orchestrator.ErrExecutableNotFound, http.StatusNotFound, ErrorCodeExecutableNotFound},
+	{orchestrator.ErrGroupNotFound, http.StatusNotFound, ErrorCodeGroupNotFound},
+	{orchestrator.ErrLogsNotFound, http.StatusNotFound, ErrorCodeLogsNotFound},
+	{orchestrator.ErrActionNotFound, http.StatusNotFound, ErrorCodeActionNotFound},
+	{orchestrator.ErrVersionNotFound, http.StatusNotFound, ErrorCodeVersionNotFound},
+	{orchestrator.ErrExecutablesAlreadySet, http.StatusConflict, ErrorCodeExecutablesAlreadySet},
+	{orchestrator.ErrExecutableExists, http.StatusConflict, ErrorCodeExecutableExists},
+	{orchestrator.ErrExecutablesNotSet, http.StatusConflict, ErrorCodeExecutablesNotSet},
+	{orchestrator.ErrExecutableRunning, http.StatusConflict, ErrorCodeExecutableRunning},
+	{orchestrator.ErrExecutableNotRunning, http.StatusConflict, ErrorCodeExecutableNotRunning},
+	{orchestrator.ErrExecutablePaused, http.StatusConflict, ErrorCodeExecutablePaused},
+	{orchestrator.ErrExecutableNotPaused, http.StatusConflict, ErrorCodeExecutableNotPaused},
+	{orchestrator.ErrExecutableStopTimedOut, http.StatusConflict, ErrorCodeStopTimedOut},
+	{orchestrator.ErrHookFailed, http.StatusConflict, ErrorCodeHookFailed},
+	{orchestrator.ErrTerminalAttached, http.StatusConflict, ErrorCodeTerminalAttached},
+	{orchestrator.ErrActionFailed, http.StatusConflict, ErrorCodeActionFailed},
+	{orchestrator.ErrRolloutFailed, http.StatusConflict, ErrorCodeRolloutFailed},
+	{orchestrator.ErrRolloutInProgress, http.StatusConflict, ErrorCodeRolloutInProgress},
+	{orchestrator.ErrDeployFailed, http.StatusConflict, ErrorCodeDeployFailed},
+	{orchestrator.ErrDeployInProgress, http.StatusConflict, ErrorCodeDeployInProgress},
+	{orchestrator.ErrIntegrity, http.StatusConflict, ErrorCodeIntegrityFailed},
+	{orchestrator.ErrBuildFailed, http.StatusConflict, ErrorCodeBuildFailed},
+	{orchestrator.ErrInvalidConfiguration, http.StatusUnprocessableEntity, ErrorCodeInvalidConfiguration},
+	{orchestrator.ErrInvalidArgument, http.StatusUnprocessableEntity, ErrorCodeInvalidArgument},
+	{webhooks.ErrWebhookNotFound, http.StatusNotFound, ErrorCodeWebhookNotFound},
+	{webhooks.ErrInvalidWebhook, http.StatusUnprocessableEntity, ErrorCodeInvalidWebhook},
+	{alerts.ErrSilenceNotFound, http.StatusNotFound, ErrorCodeSilenceNotFound},
+	{alerts.ErrInvalidSilence, http.StatusUnprocessableEntity, ErrorCodeInvalidSilence},
+	{alerts.ErrInvalidRules, http.StatusUnprocessableEntity, ErrorCodeInvalidAlertRules},
+}
+
+// newErrorResponse maps the errors of the orchestrator, the webhooks and the alerts to an HTTP status and a machine-readable code.
+func newErrorResponse(err error) *echo.HTTPError {
+	var details any
+	var report *orchestrator.ValidationReport
+	var actionErr *orchestrator.ActionError
+	var rolloutErr *orchestrator.RolloutError
+	var deployErr *orchestrator.DeployError
+	if errors.As(err, &report) {
+		details = report
+	} else if errors.As(err, &actionErr) {
+		details = actionErr.Result
+	} else if errors.As(err, &rolloutErr) {
+		details = rolloutErr.Result
+	} else if errors.As(err, &deployErr) {
+		details = deployErr.Result
+	}
+
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			return echo.NewHTTPError(mapping.status, dtos.ErrorResponse{Code: mapping.code, Message: err.Error(), Details: details})
+		}
+	}
+
+	return echo.NewHTTPError(http.StatusInternalServerError, dtos.ErrorResponse{Code: ErrorCodeInternal, Message: err.Error()})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"orchestrator/internal/apihttp/dtos"
 	"orchestrator/internal/orchestrator"
@@ -150,11 +151,12 @@ func (o *Orchestrator) RunGroup(echoContext echo.Context) error {
 // Run godoc
 //
 //	@Summary		Run an executable
-//	@Description	This endpoint tries to run an executable that is set in the orchestrator.
+//	@Description	This endpoint tries to run an executable that is set in the orchestrator. An executable that is already running is left running and succeeds.
 //	@Tags			orchestrator
 //	@Produce		json
 //	@Param			id	query		string	true	"UUID of the executable to run"	format(uuid)
 //	@Success		200	{object}	dtos.GenericResponse
+//	@Failure		400	{object}	dtos.GenericResponse
 //	@Failure		500	{object}	dtos.GenericResponse
 //	@Router			/run [get]
 func (o *Orchestrator) Run(echoContext echo.Context) error {
@@ -167,7 +169,8 @@ func (o *Orchestrator) Run(echoContext echo.Context) error {
 	}
 
 	err = o.instance.Run(ctx, executableUUID)
-	if err != nil {
+	// The API v1 does not report an executable that is already running.
+	if err != nil && !errors.Is(err, orchestrator.ErrExecutableRunning) {
 		return echo.NewHTTPError(http.StatusInternalServerError, dtos.GenericResponse{Message: "Failed to run process: " + err.Error()})
 	}
 
@@ -259,7 +262,7 @@ func (o *Orchestrator) Stop(echoContext echo.Context) error {
 //	@Tags			orchestrator
 //	@Produce		text/plain
 //	@Param			id		query		string	true	"UUID of the executable to get logs"	format(uuid)
-//	@Param			type	query		string	true	"Type of logs to get"					enum("errors", "out")
+//	@Param			type	query		string	true	"Type of logs to get"					enum("errors", "out", "build")
 //	@Param			offset	query		int		false	"Offset of the logs to get"				default(0)
 //	@Success		200		{string}	string
 //	@Failure		500		{object}	dtos.GenericResponse
@@ -0,0 +1,1124 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"orchestrator/internal/apihttp/dtos"
+	"orchestrator/internal/orchestrator"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+type OrchestratorV2Interface interface {
+	SetExecutables(echoContext echo.Context) error
+	UnsetExecutables(echoContext echo.Context) error
+	ListExecutables(echoContext echo.Context) error
+	StartExecutables(echoContext echo.Context) error
+	StopExecutables(echoContext echo.Context) error
+	GetExecutable(echoContext echo.Context) error
+	CreateExecutable(echoContext echo.Context) error
+	UpdateExecutable(echoContext echo.Context) error
+	DeleteExecutable(echoContext echo.Context) error
+	StartExecutable(echoContext echo.Context) error
+	StopExecutable(echoContext echo.Context) error
+	RestartExecutable(echoContext echo.Context) error
+	PauseExecutable(echoContext echo.Context) error
+	ResumeExecutable(echoContext echo.Context) error
+	ScaleExecutable(echoContext echo.Context) error
+	SignalExecutable(echoContext echo.Context) error
+	DeployExecutable(echoContext echo.Context) error
+	RunExecutableAction(echoContext echo.Context) error
+	ExecutableLogs(echoContext echo.Context) error
+	AttachExecutable(echoContext echo.Context) error
+	GetGroup(echoContext echo.Context) error
+	StartGroup(echoContext echo.Context) error
+	RunGroup(echoContext echo.Context) error
+	StopGroup(echoContext echo.Context) error
+	RestartGroup(echoContext echo.Context) error
+	PauseGroup(echoContext echo.Context) error
+	ResumeGroup(echoContext echo.Context) error
+	SignalGroup(echoContext echo.Context) error
+	GetConfig(echoContext echo.Context) error
+	GetExecutableConfig(echoContext echo.Context) error
+	ReloadConfig(echoContext echo.Context) error
+	ValidateConfig(echoContext echo.Context) error
+}
+
+type OrchestratorV2 struct {
+	instance orchestrator.OrchestratorInterface
+}
+
+func NewOrchestratorV2(
+	instance orchestrator.OrchestratorInterface,
+) *OrchestratorV2 {
+	return &OrchestratorV2{
+		instance: instance,
+	}
+}
+
+// SetExecutables godoc
+//
+//	@Summary		Set the executables
+//	@Description	This endpoint loads the executables from the configuration into the orchestrator and returns their status.
+//	@Tags			v2
+//	@Produce		json
+//	@Success		200	{object}	[]orchestrator.Status
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		422	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables:set [post]
+func (o *OrchestratorV2) SetExecutables(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.Set(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.ListExecutables(echoContext)
+}
+
+// UnsetExecutables godoc
+//
+//	@Summary		Unset the executables
+//	@Description	This endpoint removes the executables from the orchestrator. All executables must be stopped.
+//	@Tags			v2
+//	@Produce		json
+//	@Success		204
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables:unset [post]
+func (o *OrchestratorV2) UnsetExecutables(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.Unset(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.NoContent(http.StatusNoContent)
+}
+
+// ListExecutables godoc
+//
+//	@Summary		List the executables
+//	@Description	This endpoint returns the status of every executable that is set in the orchestrator.
+//	@Tags			v2
+//	@Produce		json
+//	@Success		200	{object}	[]orchestrator.Status
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables [get]
+func (o *OrchestratorV2) ListExecutables(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	statuses, err := o.instance.Status(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, statuses)
+}
+
+// StartExecutables godoc
+//
+//	@Summary		Start all the executables
+//	@Description	This endpoint tries to start all the executables and returns their status.
+//	@Tags			v2
+//	@Produce		json
+//	@Success		200	{object}	[]orchestrator.Status
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables:start [post]
+func (o *OrchestratorV2) StartExecutables(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.RunAll(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.ListExecutables(echoContext)
+}
+
+// StopExecutables godoc
+//
+//	@Summary		Stop all the executables
+//	@Description	This endpoint tries to stop all the executables and returns their status.
+//	@Tags			v2
+//	@Produce		json
+//	@Success		200	{object}	[]orchestrator.Status
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables:stop [post]
+func (o *OrchestratorV2) StopExecutables(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.StopAll(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.ListExecutables(echoContext)
+}
+
+// GetExecutable godoc
+//
+//	@Summary		Get an executable
+//	@Description	This endpoint returns the status of an executable.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path		string	true	"UUID of the executable"	format(uuid)
+//	@Success		200	{object}	orchestrator.Status
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id} [get]
+func (o *OrchestratorV2) GetExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	status, err := o.instance.ExecutableStatus(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, status)
+}
+
+// CreateExecutable godoc
+//
+//	@Summary		Create an executable
+//	@Description	This endpoint validates and adds an executable to the orchestrator without starting it. A body with an unknown field is rejected.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			executable	body		orchestrator.Configuration	true	"Configuration of the executable"
+//	@Success		201			{object}	orchestrator.Status
+//	@Failure		409			{object}	dtos.ErrorResponse
+//	@Failure		422			{object}	dtos.ErrorResponse
+//	@Failure		500			{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables [post]
+func (o *OrchestratorV2) CreateExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	var configuration orchestrator.Configuration
+	if err := decodeExecutable(echoContext, &configuration); err != nil {
+		return newErrorResponse(err)
+	}
+
+	status, err := o.instance.CreateExecutable(ctx, configuration)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusCreated, status)
+}
+
+// UpdateExecutable godoc
+//
+//	@Summary		Update an executable
+//	@Description	This endpoint validates and replaces the configuration of an executable. The response tells whether the configuration was applied immediately or will be applied on the next restart of the running executable. A body with an unknown field is rejected.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string						true	"UUID of the executable"	format(uuid)
+//	@Param			executable	body		orchestrator.Configuration	true	"Configuration of the executable"
+//	@Success		200			{object}	orchestrator.UpdateResult
+//	@Failure		404			{object}	dtos.ErrorResponse
+//	@Failure		409			{object}	dtos.ErrorResponse
+//	@Failure		422			{object}	dtos.ErrorResponse
+//	@Failure		500			{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id} [put]
+func (o *OrchestratorV2) UpdateExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	var configuration orchestrator.Configuration
+	if err := decodeExecutable(echoContext, &configuration); err != nil {
+		return newErrorResponse(err)
+	}
+
+	result, err := o.instance.UpdateExecutable(ctx, executableUUID, configuration)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, result)
+}
+
+// DeleteExecutable godoc
+//
+//	@Summary		Delete an executable
+//	@Description	This endpoint removes a stopped executable from the orchestrator.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path	string	true	"UUID of the executable"	format(uuid)
+//	@Success		204
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id} [delete]
+func (o *OrchestratorV2) DeleteExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	err = o.instance.DeleteExecutable(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.NoContent(http.StatusNoContent)
+}
+
+// StartExecutable godoc
+//
+//	@Summary		Start an executable
+//	@Description	This endpoint starts an executable and returns its status.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path		string	true	"UUID of the executable"	format(uuid)
+//	@Success		200	{object}	orchestrator.Status
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:start [post]
+func (o *OrchestratorV2) StartExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	err = o.instance.Run(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetExecutable(echoContext)
+}
+
+// StopExecutable godoc
+//
+//	@Summary		Stop an executable
+//	@Description	This endpoint sends the graceful exit signal to an executable and returns its status.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path		string	true	"UUID of the executable"	format(uuid)
+//	@Success		200	{object}	orchestrator.Status
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:stop [post]
+func (o *OrchestratorV2) StopExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	err = o.instance.Stop(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetExecutable(echoContext)
+}
+
+// RestartExecutable godoc
+//
+//	@Summary		Restart an executable
+//	@Description	This endpoint stops an executable, waits for it to exit, starts it again and returns its status.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path		string	true	"UUID of the executable"	format(uuid)
+//	@Success		200	{object}	orchestrator.Status
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:restart [post]
+func (o *OrchestratorV2) RestartExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	err = o.instance.Restart(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetExecutable(echoContext)
+}
+
+// PauseExecutable godoc
+//
+//	@Summary		Pause an executable
+//	@Description	This endpoint freezes the processes of a running executable, keeping their memory, and returns its status. The cgroup v2 freezer is used when available, SIGSTOP on its process group otherwise.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path		string	true	"UUID of the executable"	format(uuid)
+//	@Success		200	{object}	orchestrator.Status
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:pause [post]
+func (o *OrchestratorV2) PauseExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	err = o.instance.Pause(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetExecutable(echoContext)
+}
+
+// ResumeExecutable godoc
+//
+//	@Summary		Resume an executable
+//	@Description	This endpoint thaws the processes of a paused executable and returns its status.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path		string	true	"UUID of the executable"	format(uuid)
+//	@Success		200	{object}	orchestrator.Status
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:resume [post]
+func (o *OrchestratorV2) ResumeExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	err = o.instance.Resume(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetExecutable(echoContext)
+}
+
+// ScaleExecutable godoc
+//
+//	@Summary		Scale an executable
+//	@Description	This endpoint changes the number of instances of a replicated executable and returns the status of its instances. Scaling down stops the instances with the highest indices first. New instances are started when the executable is running.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"UUID of any instance of the executable"	format(uuid)
+//	@Param			scale	body		dtos.ScaleRequest	true	"Number of replicas"
+//	@Success		200		{array}		orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		409		{object}	dtos.ErrorResponse
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:scale [post]
+func (o *OrchestratorV2) ScaleExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	var request dtos.ScaleRequest
+	if err := echoContext.Bind(&request); err != nil {
+		return newErrorResponse(fmt.Errorf("%w: cannot decode scale request: %s", orchestrator.ErrInvalidArgument, err.Error()))
+	}
+
+	statuses, err := o.instance.Scale(ctx, executableUUID, request.Replicas)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, statuses)
+}
+
+// DeployExecutable godoc
+//
+//	@Summary		Deploy a new version of the binary of an executable
+//	@Description	This endpoint deploys the binary sent as application/octet-stream, with the version and watch query parameters, or with application/json a file on the host of the server or a version kept in the store. Without a body, the version query parameter references a version kept in the store. The previous binaries are kept in the store. A canary instance is restarted first, then the other running instances once it stayed up for the watch period. When an instance fails during its watch period, the previous version is restored and the result is returned in the details of a deploy_failed error.
+//	@Tags			v2
+//	@Accept			json,octet-stream
+//	@Produce		json
+//	@Param			id		path		string				true	"UUID of any instance of the executable"	format(uuid)
+//	@Param			deploy	body		dtos.DeployRequest	false	"Path or version to deploy"
+//	@Param			version	query		string				false	"Version of an uploaded binary, the beginning of its SHA-256 by default"
+//	@Param			watch	query		string				false	"Watch period of an uploaded binary, e.g. 1m"
+//	@Success		200		{object}	orchestrator.DeployResult
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		409		{object}	dtos.ErrorResponse
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:deploy [post]
+func (o *OrchestratorV2) DeployExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	request := dtos.DeployRequest{Version: echoContext.QueryParam("version"), Watch: echoContext.QueryParam("watch")}
+	deploy := orchestrator.DeployRequest{}
+	if strings.HasPrefix(echoContext.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		if err := echoContext.Bind(&request); err != nil {
+			return newErrorResponse(fmt.Errorf("%w: cannot decode deploy request: %s", orchestrator.ErrInvalidArgument, err.Error()))
+		}
+		deploy.Path = request.Path
+	} else if echoContext.Request().ContentLength != 0 {
+		deploy.Binary = echoContext.Request().Body
+	}
+
+	deploy.Version = request.Version
+	if request.Watch != "" {
+		deploy.Watch, err = time.ParseDuration(request.Watch)
+		if err != nil {
+			return newErrorResponse(fmt.Errorf("%w: watch must be a duration such as 1m: %s", orchestrator.ErrInvalidArgument, request.Watch))
+		}
+	}
+
+	result, err := o.instance.Deploy(ctx, executableUUID, deploy)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, result)
+}
+
+// SignalExecutable godoc
+//
+//	@Summary		Send a signal to an executable
+//	@Description	This endpoint sends a signal such as HUP, USR1, USR2 or QUIT to a running executable and returns its status.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"UUID of the executable"	format(uuid)
+//	@Param			signal	body		dtos.SignalRequest	true	"Signal to send"
+//	@Success		200		{object}	orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		409		{object}	dtos.ErrorResponse
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}:signal [post]
+func (o *OrchestratorV2) SignalExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	var request dtos.SignalRequest
+	if err := echoContext.Bind(&request); err != nil {
+		return newErrorResponse(fmt.Errorf("%w: cannot decode signal request: %s", orchestrator.ErrInvalidArgument, err.Error()))
+	}
+
+	err = o.instance.Signal(ctx, executableUUID, request.Signal)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetExecutable(echoContext)
+}
+
+// RunExecutableAction godoc
+//
+//	@Summary		Run an action of an executable
+//	@Description	This endpoint runs a named action of an executable and returns its result. A signal action requires the executable to be running. The output of a command action is returned, and its result is in the details of the error when it fails.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id		path		string	true	"UUID of the executable"	format(uuid)
+//	@Param			action	path		string	true	"Name of the action"
+//	@Success		200		{object}	orchestrator.ActionResult
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		409		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}/actions/{action}:run [post]
+func (o *OrchestratorV2) RunExecutableAction(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	result, err := o.instance.RunAction(ctx, executableUUID, echoContext.Param("action"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, result)
+}
+
+// ExecutableLogs godoc
+//
+//	@Summary		Get the logs of an executable
+//	@Description	This endpoint returns a log file of an executable. Offset 0 is the most recent file. With follow, the most recent file is streamed from its end, or from its start with from_start, until the client disconnects, and tailing continues in the new file when the executable is started again.
+//	@Tags			v2
+//	@Produce		text/plain
+//	@Param			id			path		string	true	"UUID of the executable"			format(uuid)
+//	@Param			type		query		string	false	"Type of logs to get"				enum(errors, out, build)	default(out)
+//	@Param			offset		query		int		false	"Offset of the logs to get"			default(0)
+//	@Param			follow		query		bool	false	"Stream the most recent log file"	default(false)
+//	@Param			from_start	query		bool	false	"Follow from the start of the file"	default(false)
+//	@Success		200			{string}	string
+//	@Failure		404			{object}	dtos.ErrorResponse
+//	@Failure		422			{object}	dtos.ErrorResponse
+//	@Failure		500			{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}/logs [get]
+func (o *OrchestratorV2) ExecutableLogs(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	logsType := echoContext.QueryParam("type")
+	if logsType == "" {
+		logsType = "out"
+	}
+
+	offsetInt := 0
+	if offset := echoContext.QueryParam("offset"); offset != "" {
+		offsetInt, err = strconv.Atoi(offset)
+		if err != nil || offsetInt < 0 {
+			return newErrorResponse(fmt.Errorf("%w: offset must be a non-negative integer", orchestrator.ErrInvalidArgument))
+		}
+	}
+
+	if follow, _ := strconv.ParseBool(echoContext.QueryParam("follow")); follow {
+		if offsetInt != 0 {
+			return newErrorResponse(fmt.Errorf("%w: offset cannot be used with follow", orchestrator.ErrInvalidArgument))
+		}
+		fromStart, _ := strconv.ParseBool(echoContext.QueryParam("from_start"))
+		return o.followLogs(echoContext, logsType, executableUUID, fromStart)
+	}
+
+	logs, err := o.instance.ExecLogs(ctx, logsType, executableUUID, offsetInt)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.String(http.StatusOK, logs)
+}
+
+// followLogs streams the chunks of the most recent log file as they are written, until the client disconnects.
+func (o *OrchestratorV2) followLogs(echoContext echo.Context, logsType string, executableUUID uuid.UUID, fromStart bool) error {
+	chunks, err := o.instance.TailLogs(echoContext.Request().Context(), logsType, executableUUID, fromStart)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	response := echoContext.Response()
+	response.Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	response.Header().Set("X-Content-Type-Options", "nosniff")
+	response.WriteHeader(http.StatusOK)
+	response.Flush()
+
+	for chunk := range chunks {
+		if _, err := response.Write([]byte(chunk)); err != nil {
+			return nil
+		}
+		response.Flush()
+	}
+
+	return nil
+}
+
+// AttachExecutable godoc
+//
+//	@Summary		Attach to the terminal of an executable
+//	@Description	This endpoint upgrades to a WebSocket connected to the terminal of a running executable started with tty. The output, starting with the recent scrollback, is sent as binary frames. Binary frames from the client are written to the terminal, and text frames carry control messages: {"type": "resize", "rows": 40, "cols": 120} and {"type": "input", "data": "..."}. Any number of read-only attachments is allowed next to one interactive attachment.
+//	@Tags			v2
+//	@Param			id			path	string	true	"UUID of the executable"	format(uuid)
+//	@Param			readonly	query	bool	false	"Only receive the output"	default(false)
+//	@Success		101
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		422	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}/attach [get]
+func (o *OrchestratorV2) AttachExecutable(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	readOnly, _ := strconv.ParseBool(echoContext.QueryParam("readonly"))
+	attachment, err := o.instance.Attach(ctx, executableUUID, readOnly)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+	defer attachment.Close()
+
+	// The caller is authorized by the middlewares, so the origin is not checked.
+	server := websocket.Server{Handler: func(conn *websocket.Conn) {
+		defer conn.Close()
+		conn.PayloadType = websocket.BinaryFrame
+
+		go func() {
+			defer attachment.Close()
+			for {
+				var frame terminalFrame
+				if err := terminalCodec.Receive(conn, &frame); err != nil {
+					return
+				}
+				if err := frame.apply(attachment); err != nil {
+					_ = websocket.JSON.Send(conn, dtos.TerminalMessage{Type: "error", Message: err.Error()})
+				}
+			}
+		}()
+
+		for chunk := range attachment.Output {
+			if _, err := conn.Write(chunk); err != nil {
+				return
+			}
+		}
+	}}
+	server.ServeHTTP(echoContext.Response(), echoContext.Request())
+
+	return nil
+}
+
+// terminalFrame is a frame received from an attachment: input when it is binary, a control message otherwise.
+type terminalFrame struct {
+	binary bool
+	data   []byte
+}
+
+var terminalCodec = websocket.Codec{
+	Unmarshal: func(data []byte, payloadType byte, v any) error {
+		frame := v.(*terminalFrame)
+		frame.binary = payloadType == websocket.BinaryFrame
+		frame.data = data
+		return nil
+	},
+}
+
+func (o terminalFrame) apply(attachment *orchestrator.Attachment) error {
+	if o.binary {
+		_, err := attachment.Write(o.data)
+		return err
+	}
+
+	var message dtos.TerminalMessage
+	if err := json.Unmarshal(o.data, &message); err != nil {
+		return fmt.Errorf("%w: cannot decode terminal message: %s", orchestrator.ErrInvalidArgument, err.Error())
+	}
+
+	switch message.Type {
+	case "resize":
+		return attachment.Resize(message.Rows, message.Cols)
+	case "input":
+		_, err := attachment.Write([]byte(message.Data))
+		return err
+	default:
+		return fmt.Errorf("%w: unknown terminal message type: %s", orchestrator.ErrInvalidArgument, message.Type)
+	}
+}
+
+// GetGroup godoc
+//
+//	@Summary		Get a group of executables
+//	@Description	This endpoint returns the status of the executables of a group.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			name	path		string	true	"Group name"
+//	@Success		200		{object}	[]orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name} [get]
+func (o *OrchestratorV2) GetGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	group := echoContext.Param("name")
+
+	statuses, err := o.instance.Status(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	groupStatuses := make([]orchestrator.Status, 0)
+	for _, status := range statuses {
+		if status.Group == group {
+			groupStatuses = append(groupStatuses, status)
+		}
+	}
+
+	if len(groupStatuses) == 0 {
+		return newErrorResponse(orchestrator.ErrGroupNotFound)
+	}
+
+	return echoContext.JSON(http.StatusOK, groupStatuses)
+}
+
+// StartGroup godoc
+//
+//	@Summary		Start a group of executables
+//	@Description	This endpoint starts the executables of a group and returns their status.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			name	path		string	true	"Group name"
+//	@Success		200		{object}	[]orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name}:start [post]
+func (o *OrchestratorV2) StartGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.RunGroup(ctx, echoContext.Param("name"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetGroup(echoContext)
+}
+
+// RunGroup godoc
+//
+//	@Summary		Run a group and wait for its tasks
+//	@Description	This endpoint starts the executables of a group, waits until every task of the group has finished and returns their aggregated outcome. Services of the group are started but not waited for. When the timeout elapses first, the result is returned with finished set to false.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			name	path		string	true	"Group name"
+//	@Param			timeout	query		string	false	"Maximum time to wait, e.g. 10m"
+//	@Success		200		{object}	orchestrator.GroupResult
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name}:run [post]
+func (o *OrchestratorV2) RunGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	if timeout := echoContext.QueryParam("timeout"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil || duration <= 0 {
+			return newErrorResponse(fmt.Errorf("%w: timeout must be a positive duration such as 10m: %s", orchestrator.ErrInvalidArgument, timeout))
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	result, err := o.instance.RunGroupAndWait(ctx, echoContext.Param("name"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, result)
+}
+
+// StopGroup godoc
+//
+//	@Summary		Stop a group of executables
+//	@Description	This endpoint stops the executables of a group and returns their status.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			name	path		string	true	"Group name"
+//	@Success		200		{object}	[]orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name}:stop [post]
+func (o *OrchestratorV2) StopGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.StopGroup(ctx, echoContext.Param("name"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetGroup(echoContext)
+}
+
+// RestartGroup godoc
+//
+//	@Summary		Rolling restart of a group of executables
+//	@Description	This endpoint restarts the running services of a group in batches of max_unavailable, starting the next batch once the restarted ones stayed up for min_uptime, and returns the state of every member. When a member fails to restart or exits within min_uptime, the remaining batches are not restarted and the result is returned in the details of a rollout_failed error.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string						true	"Group name"
+//	@Param			options	body		dtos.RollingRestartRequest	false	"Rolling restart options"
+//	@Success		200		{object}	orchestrator.RolloutResult
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		409		{object}	dtos.ErrorResponse
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name}:restart [post]
+func (o *OrchestratorV2) RestartGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	var request dtos.RollingRestartRequest
+	if err := echoContext.Bind(&request); err != nil {
+		return newErrorResponse(fmt.Errorf("%w: cannot decode rolling restart request: %s", orchestrator.ErrInvalidArgument, err.Error()))
+	}
+
+	options := orchestrator.RolloutOptions{MaxUnavailable: request.MaxUnavailable}
+	if request.MinUptime != "" {
+		minUptime, err := time.ParseDuration(request.MinUptime)
+		if err != nil {
+			return newErrorResponse(fmt.Errorf("%w: min_uptime must be a duration such as 30s: %s", orchestrator.ErrInvalidArgument, request.MinUptime))
+		}
+		options.MinUptime = minUptime
+	}
+
+	result, err := o.instance.RollingRestart(ctx, echoContext.Param("name"), options)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, result)
+}
+
+// PauseGroup godoc
+//
+//	@Summary		Pause a group of executables
+//	@Description	This endpoint pauses the running executables of a group and returns their status. The executables that are not running or already paused are skipped.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			name	path		string	true	"Group name"
+//	@Success		200		{object}	[]orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name}:pause [post]
+func (o *OrchestratorV2) PauseGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.PauseGroup(ctx, echoContext.Param("name"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetGroup(echoContext)
+}
+
+// ResumeGroup godoc
+//
+//	@Summary		Resume a group of executables
+//	@Description	This endpoint resumes the paused executables of a group and returns their status.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			name	path		string	true	"Group name"
+//	@Success		200		{object}	[]orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name}:resume [post]
+func (o *OrchestratorV2) ResumeGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.ResumeGroup(ctx, echoContext.Param("name"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetGroup(echoContext)
+}
+
+// SignalGroup godoc
+//
+//	@Summary		Send a signal to a group of executables
+//	@Description	This endpoint sends a signal such as HUP, USR1, USR2 or QUIT to the running executables of a group and returns their status. The executables that are not running are skipped.
+//	@Tags			v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string				true	"Group name"
+//	@Param			signal	body		dtos.SignalRequest	true	"Signal to send"
+//	@Success		200		{object}	[]orchestrator.Status
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/groups/{name}:signal [post]
+func (o *OrchestratorV2) SignalGroup(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	var request dtos.SignalRequest
+	if err := echoContext.Bind(&request); err != nil {
+		return newErrorResponse(fmt.Errorf("%w: cannot decode signal request: %s", orchestrator.ErrInvalidArgument, err.Error()))
+	}
+
+	err := o.instance.SignalGroup(ctx, echoContext.Param("name"), request.Signal)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.GetGroup(echoContext)
+}
+
+// GetConfig godoc
+//
+//	@Summary		Get the resolved configuration
+//	@Description	This endpoint returns the configuration of every executable after its defaults, templates and variables are applied. When the executables are not set, the configuration files are resolved.
+//	@Tags			v2
+//	@Produce		json
+//	@Success		200	{object}	[]orchestrator.ResolvedConfiguration
+//	@Failure		422	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/config [get]
+func (o *OrchestratorV2) GetConfig(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	configurations, err := o.instance.ResolvedConfiguration(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, configurations)
+}
+
+// GetExecutableConfig godoc
+//
+//	@Summary		Get the resolved configuration of an executable
+//	@Description	This endpoint returns the configuration of an executable after its defaults, templates and variables are applied.
+//	@Tags			v2
+//	@Produce		json
+//	@Param			id	path		string	true	"UUID of the executable"	format(uuid)
+//	@Success		200	{object}	orchestrator.ResolvedConfiguration
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/executables/{id}/config [get]
+func (o *OrchestratorV2) GetExecutableConfig(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	executableUUID, err := executableIDParam(echoContext)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	configuration, err := o.instance.ExecutableConfiguration(ctx, executableUUID)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, configuration)
+}
+
+// ReloadConfig godoc
+//
+//	@Summary		Reload the configuration
+//	@Description	This endpoint applies the configuration file to the executables that are set. New executables are added, changed executables apply the new configuration on their next start and removed executables are unset.
+//	@Tags			v2
+//	@Produce		json
+//	@Success		200	{object}	[]orchestrator.Status
+//	@Failure		409	{object}	dtos.ErrorResponse
+//	@Failure		422	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/config:reload [post]
+func (o *OrchestratorV2) ReloadConfig(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	err := o.instance.Reload(ctx)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return o.ListExecutables(echoContext)
+}
+
+// ValidateConfig godoc
+//
+//	@Summary		Validate a configuration
+//	@Description	This endpoint strictly decodes and validates the candidate configuration of the request body without applying it. The report lists every problem with its line and column.
+//	@Description	The format is the format parameter, or else the one of the Content-Type (application/yaml or application/toml), JSON by default.
+//	@Tags			v2
+//	@Accept			json
+//	@Accept			application/yaml
+//	@Accept			application/toml
+//	@Produce		json
+//	@Param			format	query		string							false	"Format of the configuration"	Enums(json, yaml, toml)
+//	@Param			config	body		[]orchestrator.Configuration	true	"Candidate configuration"
+//	@Success		200		{object}	orchestrator.ValidationReport
+//	@Failure		422		{object}	orchestrator.ValidationReport
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/config:validate [post]
+//	@Router			/config/validate [post]
+func (o *OrchestratorV2) ValidateConfig(echoContext echo.Context) error {
+	ctx := echoContext.Request().Context()
+
+	report, err := o.instance.Validate(ctx, echoContext.Request().Body, configurationFormat(echoContext))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	if !report.Valid {
+		return echoContext.JSON(http.StatusUnprocessableEntity, report)
+	}
+
+	return echoContext.JSON(http.StatusOK, report)
+}
+
+// configurationFormat is the format of a configuration in the request body, from the format parameter or the Content-Type.
+func configurationFormat(echoContext echo.Context) string {
+	if format := echoContext.QueryParam("format"); format != "" {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(echoContext.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return orchestrator.FormatYAML
+	case "application/toml", "text/toml":
+		return orchestrator.FormatTOML
+	default:
+		return orchestrator.FormatJSON
+	}
+}
+
+// decodeExecutable decodes the configuration of an executable strictly, so that a misspelled field is reported instead of ignored.
+func decodeExecutable(echoContext echo.Context, configuration *orchestrator.Configuration) error {
+	decoder := json.NewDecoder(echoContext.Request().Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(configuration); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: cannot decode executable: the body is empty", orchestrator.ErrInvalidArgument)
+		}
+		return fmt.Errorf("%w: cannot decode executable: %s", orchestrator.ErrInvalidArgument, strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	return nil
+}
+
+func executableIDParam(echoContext echo.Context) (uuid.UUID, error) {
+	executableUUID, err := uuid.Parse(echoContext.Param("id"))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: cannot parse executable ID as UUID", orchestrator.ErrExecutableNotFound)
+	}
+
+	return executableUUID, nil
+}
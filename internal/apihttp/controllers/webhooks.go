@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"orchestrator/internal/apihttp/dtos"
+	"orchestrator/internal/webhooks"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhooksInterface interface {
+	List(echoContext echo.Context) error
+	Create(echoContext echo.Context) error
+	Get(echoContext echo.Context) error
+	Delete(echoContext echo.Context) error
+	Ping(echoContext echo.Context) error
+	Deliveries(echoContext echo.Context) error
+}
+
+type Webhooks struct {
+	dispatcher *webhooks.Dispatcher
+}
+
+func NewWebhooks(
+	dispatcher *webhooks.Dispatcher,
+) *Webhooks {
+	return &Webhooks{
+		dispatcher: dispatcher,
+	}
+}
+
+// List godoc
+//
+//	@Summary		List the webhooks
+//	@Description	This endpoint returns the registered webhooks, without their secrets.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Success		200	{array}	webhooks.Webhook
+//	@Router			/api/v2/webhooks [get]
+func (o *Webhooks) List(echoContext echo.Context) error {
+	return echoContext.JSON(http.StatusOK, o.dispatcher.Webhooks())
+}
+
+// Create godoc
+//
+//	@Summary		Register a webhook
+//	@Description	This endpoint registers an endpoint that receives the events as JSON, signed in the X-Orchestrator-Signature header with HMAC-SHA256 of the body. A secret is generated when none is given. The response is the only one that contains the secret.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body		dtos.WebhookRequest	true	"Endpoint, secret and filters of the webhook"
+//	@Success		201		{object}	webhooks.Webhook
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Failure		500		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/webhooks [post]
+func (o *Webhooks) Create(echoContext echo.Context) error {
+	var request dtos.WebhookRequest
+	if err := echoContext.Bind(&request); err != nil {
+		return newErrorResponse(fmt.Errorf("%w: cannot decode webhook: %s", webhooks.ErrInvalidWebhook, err.Error()))
+	}
+
+	webhook, err := o.dispatcher.Register(webhooks.Webhook{
+		URL:    request.URL,
+		Secret: request.Secret,
+		Events: request.Events,
+		Groups: request.Groups,
+	})
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusCreated, webhook)
+}
+
+// Get godoc
+//
+//	@Summary		Get a webhook
+//	@Description	This endpoint returns a webhook, without its secret.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path		string	true	"ID of the webhook"	format(uuid)
+//	@Success		200	{object}	webhooks.Webhook
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/webhooks/{id} [get]
+func (o *Webhooks) Get(echoContext echo.Context) error {
+	webhook, err := o.dispatcher.Webhook(echoContext.Param("id"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, webhook)
+}
+
+// Delete godoc
+//
+//	@Summary		Unregister a webhook
+//	@Description	This endpoint removes a webhook. Its pending deliveries are abandoned.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path	string	true	"ID of the webhook"	format(uuid)
+//	@Success		204
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Failure		500	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/webhooks/{id} [delete]
+func (o *Webhooks) Delete(echoContext echo.Context) error {
+	err := o.dispatcher.Unregister(echoContext.Param("id"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.NoContent(http.StatusNoContent)
+}
+
+// Ping godoc
+//
+//	@Summary		Ping a webhook
+//	@Description	This endpoint queues a ping event for a webhook and returns its delivery, to check that the endpoint is reachable.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path		string	true	"ID of the webhook"	format(uuid)
+//	@Success		202	{object}	webhooks.Delivery
+//	@Failure		404	{object}	dtos.ErrorResponse
+//	@Router			/api/v2/webhooks/{id}:ping [post]
+func (o *Webhooks) Ping(echoContext echo.Context) error {
+	delivery, err := o.dispatcher.Ping(echoContext.Param("id"))
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusAccepted, delivery)
+}
+
+// Deliveries godoc
+//
+//	@Summary		Get the delivery log of a webhook
+//	@Description	This endpoint returns the deliveries of a webhook, the most recent first, with their attempts and outcome.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id		path		string	true	"ID of the webhook"						format(uuid)
+//	@Param			status	query		string	false	"Return deliveries of this status only"	Enums(pending, succeeded, failed)
+//	@Param			limit	query		int		false	"Return at most this number of deliveries"
+//	@Success		200		{array}		webhooks.Delivery
+//	@Failure		404		{object}	dtos.ErrorResponse
+//	@Failure		422		{object}	dtos.ErrorResponse
+//	@Router			/api/v2/webhooks/{id}/deliveries [get]
+func (o *Webhooks) Deliveries(echoContext echo.Context) error {
+	limit := 0
+	if value := echoContext.QueryParam("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return newErrorResponse(fmt.Errorf("%w: limit must be a non-negative integer", webhooks.ErrInvalidWebhook))
+		}
+	}
+
+	deliveries, err := o.dispatcher.Deliveries(echoContext.Param("id"), echoContext.QueryParam("status"), limit)
+	if err != nil {
+		return newErrorResponse(err)
+	}
+
+	return echoContext.JSON(http.StatusOK, deliveries)
+}
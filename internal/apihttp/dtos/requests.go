@@ -1 +1,53 @@
 package dtos
+
+type ScaleRequest struct {
+	Replicas int `json:"replicas"`
+}
+
+type SignalRequest struct {
+	// Signal is a name such as HUP or SIGHUP.
+	Signal string `json:"signal"`
+}
+
+// DeployRequest deploys a file on the host of the server, or a version kept in the store when there is no path.
+type DeployRequest struct {
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Watch is how long the restarted instances must stay up on the new version, such as 1m. 30s by default.
+	Watch string `json:"watch,omitempty"`
+}
+
+type RollingRestartRequest struct {
+	// MaxUnavailable is how many executables are restarted at once, 1 by default.
+	MaxUnavailable int `json:"max_unavailable,omitempty"`
+	// MinUptime is how long the restarted executables must stay up before the next batch, such as 30s. 10s by default.
+	MinUptime string `json:"min_uptime,omitempty"`
+}
+
+type WebhookRequest struct {
+	URL string `json:"url"`
+	// Secret signs the deliveries, one is generated when it is empty.
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+type SilenceRequest struct {
+	Rule    string `json:"rule,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	// Duration is how long the silence lasts, such as 2h.
+	Duration string `json:"duration"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+/*
+TerminalMessage is a control message of an attachment, sent as a text frame. Clients send "resize" with rows and
+cols, or "input" with data as an alternative to binary frames. The server sends "error" with a message.
+*/
+type TerminalMessage struct {
+	Type    string `json:"type"`
+	Data    string `json:"data,omitempty"`
+	Rows    int    `json:"rows,omitempty"`
+	Cols    int    `json:"cols,omitempty"`
+	Message string `json:"message,omitempty"`
+}
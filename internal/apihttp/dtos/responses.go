@@ -3,3 +3,9 @@ package dtos
 type GenericResponse struct {
 	Message string `json:"message"`
 }
+
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
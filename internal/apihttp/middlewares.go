@@ -0,0 +1,77 @@
+package apihttp
+
+import (
+	"net/http"
+	"orchestrator/internal/apihttp/controllers"
+	"orchestrator/internal/apihttp/dtos"
+	"orchestrator/internal/audit"
+	"orchestrator/internal/auth"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// authorize rejects the requests of peers that the authorizer does not allow.
+func authorize(authorizer *auth.Authorizer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echoContext echo.Context) error {
+			peer, ok := auth.PeerFromContext(echoContext.Request().Context())
+			if !ok {
+				peer = auth.Peer{RemoteAddr: echoContext.RealIP()}
+			}
+
+			if err := authorizer.Authorize(peer); err != nil {
+				return echo.NewHTTPError(http.StatusForbidden, dtos.ErrorResponse{Code: controllers.ErrorCodeUnauthorized, Message: err.Error()})
+			}
+
+			return next(echoContext)
+		}
+	}
+}
+
+// auditCaller stores the peer credentials, or else the remote IP of the request, as the caller of any control-plane action it triggers.
+func auditCaller(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(echoContext echo.Context) error {
+		request := echoContext.Request()
+
+		caller := echoContext.RealIP()
+		if peer, ok := auth.PeerFromContext(request.Context()); ok {
+			caller = peer.Caller()
+		}
+
+		ctx := audit.WithCaller(request.Context(), caller)
+		echoContext.SetRequest(request.WithContext(ctx))
+
+		return next(echoContext)
+	}
+}
+
+// customMethods routes `POST /resource/{param}:method` requests to the handler of the method. Echo cannot route on a
+// colon after a path parameter, so the handlers receive the parameter with the method suffix stripped.
+func customMethods(param string, handlers map[string]echo.HandlerFunc) echo.HandlerFunc {
+	return func(echoContext echo.Context) error {
+		value := echoContext.Param(param)
+
+		separator := strings.LastIndex(value, ":")
+		if separator < 0 {
+			return echo.NewHTTPError(http.StatusNotFound, dtos.ErrorResponse{Code: controllers.ErrorCodeUnknownMethod, Message: "custom method is required"})
+		}
+
+		method := value[separator+1:]
+		handler, ok := handlers[method]
+		if !ok {
+			return echo.NewHTTPError(http.StatusNotFound, dtos.ErrorResponse{Code: controllers.ErrorCodeUnknownMethod, Message: "unknown custom method: " + method})
+		}
+
+		names := echoContext.ParamNames()
+		values := echoContext.ParamValues()
+		for i, name := range names {
+			if name == param {
+				values[i] = value[:separator]
+			}
+		}
+		echoContext.SetParamValues(values...)
+
+		return handler(echoContext)
+	}
+}
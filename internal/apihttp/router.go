@@ -3,20 +3,36 @@ package apihttp
 import (
 	_ "orchestrator/docs"
 	"orchestrator/internal/apihttp/controllers"
+	"orchestrator/internal/auth"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
 type Router struct {
-	Orchestrator controllers.OrchestratorInterface
+	Orchestrator   controllers.OrchestratorInterface
+	OrchestratorV2 controllers.OrchestratorV2Interface
+	Audit          controllers.AuditInterface
+	Webhooks       controllers.WebhooksInterface
+	Alerts         controllers.AlertsInterface
+	Authorizer     *auth.Authorizer
 }
 
 func NewRouter(
 	orchestrator controllers.OrchestratorInterface,
+	orchestratorV2 controllers.OrchestratorV2Interface,
+	audit controllers.AuditInterface,
+	webhooks controllers.WebhooksInterface,
+	alerts controllers.AlertsInterface,
+	authorizer *auth.Authorizer,
 ) *Router {
 	return &Router{
-		Orchestrator: orchestrator,
+		Orchestrator:   orchestrator,
+		OrchestratorV2: orchestratorV2,
+		Audit:          audit,
+		Webhooks:       webhooks,
+		Alerts:         alerts,
+		Authorizer:     authorizer,
 	}
 }
 
@@ -26,6 +42,9 @@ func NewRouter(
 
 // @BasePath
 func (o *Router) Route(e *echo.Echo) {
+	e.Use(authorize(o.Authorizer))
+	e.Use(auditCaller)
+
 	// Generic
 	e.GET("/set", o.Orchestrator.Set)
 	e.GET("/unset", o.Orchestrator.Unset)
@@ -44,6 +63,71 @@ func (o *Router) Route(e *echo.Echo) {
 	// Logs
 	e.GET("/execlogs", o.Orchestrator.ExecLogs)
 
+	// Config
+	e.POST("/config/validate", o.OrchestratorV2.ValidateConfig)
+
+	// V2
+	v2 := e.Group("/api/v2")
+	v2.GET("/executables", o.OrchestratorV2.ListExecutables)
+	v2.POST("/executables", o.OrchestratorV2.CreateExecutable)
+	v2.POST("/executables\\:set", o.OrchestratorV2.SetExecutables)
+	v2.POST("/executables\\:unset", o.OrchestratorV2.UnsetExecutables)
+	v2.POST("/executables\\:start", o.OrchestratorV2.StartExecutables)
+	v2.POST("/executables\\:stop", o.OrchestratorV2.StopExecutables)
+	v2.GET("/executables/:id", o.OrchestratorV2.GetExecutable)
+	v2.PUT("/executables/:id", o.OrchestratorV2.UpdateExecutable)
+	v2.DELETE("/executables/:id", o.OrchestratorV2.DeleteExecutable)
+	v2.POST("/executables/:id", customMethods("id", map[string]echo.HandlerFunc{
+		"start":   o.OrchestratorV2.StartExecutable,
+		"stop":    o.OrchestratorV2.StopExecutable,
+		"restart": o.OrchestratorV2.RestartExecutable,
+		"pause":   o.OrchestratorV2.PauseExecutable,
+		"resume":  o.OrchestratorV2.ResumeExecutable,
+		"scale":   o.OrchestratorV2.ScaleExecutable,
+		"signal":  o.OrchestratorV2.SignalExecutable,
+		"deploy":  o.OrchestratorV2.DeployExecutable,
+	}))
+	v2.POST("/executables/:id/actions/:action", customMethods("action", map[string]echo.HandlerFunc{
+		"run": o.OrchestratorV2.RunExecutableAction,
+	}))
+	v2.GET("/executables/:id/logs", o.OrchestratorV2.ExecutableLogs)
+	v2.GET("/executables/:id/attach", o.OrchestratorV2.AttachExecutable)
+	v2.GET("/executables/:id/config", o.OrchestratorV2.GetExecutableConfig)
+	v2.GET("/groups/:name", o.OrchestratorV2.GetGroup)
+	v2.POST("/groups/:name", customMethods("name", map[string]echo.HandlerFunc{
+		"start":   o.OrchestratorV2.StartGroup,
+		"stop":    o.OrchestratorV2.StopGroup,
+		"run":     o.OrchestratorV2.RunGroup,
+		"restart": o.OrchestratorV2.RestartGroup,
+		"pause":   o.OrchestratorV2.PauseGroup,
+		"resume":  o.OrchestratorV2.ResumeGroup,
+		"signal":  o.OrchestratorV2.SignalGroup,
+	}))
+	v2.GET("/config", o.OrchestratorV2.GetConfig)
+	v2.POST("/config\\:reload", o.OrchestratorV2.ReloadConfig)
+	v2.POST("/config\\:validate", o.OrchestratorV2.ValidateConfig)
+
+	// Webhooks
+	v2.GET("/webhooks", o.Webhooks.List)
+	v2.POST("/webhooks", o.Webhooks.Create)
+	v2.GET("/webhooks/:id", o.Webhooks.Get)
+	v2.DELETE("/webhooks/:id", o.Webhooks.Delete)
+	v2.POST("/webhooks/:id", customMethods("id", map[string]echo.HandlerFunc{
+		"ping": o.Webhooks.Ping,
+	}))
+	v2.GET("/webhooks/:id/deliveries", o.Webhooks.Deliveries)
+
+	// Alerts
+	v2.GET("/alerts", o.Alerts.List)
+	v2.GET("/alerts/rules", o.Alerts.Rules)
+	v2.POST("/alerts/rules\\:reload", o.Alerts.ReloadRules)
+	v2.GET("/silences", o.Alerts.ListSilences)
+	v2.POST("/silences", o.Alerts.CreateSilence)
+	v2.DELETE("/silences/:id", o.Alerts.DeleteSilence)
+
+	// Audit
+	e.GET("/audit", o.Audit.Query)
+
 	// Swagger
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
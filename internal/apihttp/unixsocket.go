@@ -0,0 +1,65 @@
+package apihttp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"orchestrator/internal/auth"
+	orchestratorlogger "orchestrator/internal/logger"
+	"os"
+)
+
+// ServeUnixSocket serves the handler on a Unix socket. Every connection carries the credentials of its peer,
+// so that the authorization middleware can check them against the allowlists.
+func ServeUnixSocket(handler http.Handler, path string, mode os.FileMode, logger *log.Logger) (func(), error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, errors.New("unix socket path exists and is not a socket: " + path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, errors.New("error removing stale unix socket: " + err.Error())
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.New("error listening on unix socket: " + err.Error())
+	}
+
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+		return nil, errors.New("error setting unix socket permissions: " + err.Error())
+	}
+
+	server := &http.Server{
+		Handler: handler,
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			peer := auth.Peer{RemoteAddr: "unix:" + path}
+
+			credentials, err := auth.PeerCredentials(conn)
+			if err != nil {
+				logger.Print(orchestratorlogger.LogErr + err.Error())
+				// Unknown credentials never match the allowlists.
+				credentials = &auth.Credentials{UID: -1, GID: -1, PID: -1}
+			}
+			peer.Credentials = credentials
+
+			return auth.WithPeer(ctx, peer)
+		},
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Print(orchestratorlogger.LogErr + "Unix socket server stopped: " + err.Error())
+		}
+	}()
+
+	cleanup := func() {
+		_ = server.Close()
+		_ = os.Remove(path)
+	}
+
+	return cleanup, nil
+}
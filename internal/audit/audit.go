@@ -0,0 +1,305 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	AuditFilePrefix     = "audit-"
+	AuditFileExtension  = ".jsonl"
+	AuditFileDateFormat = "2006-01-02"
+	CallerOrchestrator  = "orchestrator"
+	ResultSuccess       = "success"
+	ResultFailure       = "failure"
+	// ResultIntegrityFailure is a binary that failed its checksum or signature verification.
+	ResultIntegrityFailure = "integrity_failure"
+)
+
+// Classified is implemented by errors that are recorded with a result of their own instead of failure.
+type Classified interface {
+	AuditResult() string
+}
+
+type contextKey struct{}
+
+type Record struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Caller      string    `json:"caller"`
+	Action      string    `json:"action"`
+	Executables []Target  `json:"executables"`
+	Result      string    `json:"result"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type Target struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Source string `json:"source,omitempty"`
+}
+
+type Filter struct {
+	From       time.Time
+	To         time.Time
+	Action     string
+	Executable string
+}
+
+// Auditor appends records to one JSON Lines file per day and prunes the files that are older than the retention.
+type Auditor struct {
+	mu            sync.Mutex
+	dir           string
+	retentionDays int
+	file          *os.File
+	fileDate      string
+}
+
+func NewAuditor(dir string, retentionDays int) (*Auditor, func(), error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, errors.New("error creating audit directory: " + err.Error())
+	}
+
+	auditor := &Auditor{
+		dir:           dir,
+		retentionDays: retentionDays,
+	}
+
+	if err := auditor.prune(time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		auditor.mu.Lock()
+		defer auditor.mu.Unlock()
+
+		if auditor.file != nil {
+			_ = auditor.file.Close()
+			auditor.file = nil
+		}
+	}
+
+	return auditor, cleanup, nil
+}
+
+// WithCaller returns a context that carries the identity of the caller of a control-plane action.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, contextKey{}, caller)
+}
+
+// CallerFromContext returns the caller stored in the context. Actions without a caller are internal decisions of the orchestrator.
+func CallerFromContext(ctx context.Context) string {
+	caller, ok := ctx.Value(contextKey{}).(string)
+	if !ok || caller == "" {
+		return CallerOrchestrator
+	}
+
+	return caller
+}
+
+func (o *Auditor) Record(ctx context.Context, action string, targets []Target, err error) error {
+	record := Record{
+		Timestamp:   time.Now().UTC(),
+		Caller:      CallerFromContext(ctx),
+		Action:      action,
+		Executables: targets,
+		Result:      ResultSuccess,
+	}
+	if record.Executables == nil {
+		record.Executables = []Target{}
+	}
+	if err != nil {
+		record.Result = ResultFailure
+		record.Error = err.Error()
+
+		var classified Classified
+		if errors.As(err, &classified) {
+			record.Result = classified.AuditResult()
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.New("error encoding audit record: " + err.Error())
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	file, err := o.currentFile(record.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return errors.New("error writing audit record: " + err.Error())
+	}
+
+	return nil
+}
+
+func (o *Auditor) Query(filter Filter) ([]Record, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	files, err := o.files()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0)
+	for _, name := range files {
+		date, err := time.Parse(AuditFileDateFormat, strings.TrimSuffix(strings.TrimPrefix(name, AuditFilePrefix), AuditFileExtension))
+		if err != nil {
+			continue
+		}
+		if !filter.From.IsZero() && date.Add(24*time.Hour).Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && date.After(filter.To) {
+			continue
+		}
+
+		fileRecords, err := readRecords(filepath.Join(o.dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range fileRecords {
+			if filter.matches(record) {
+				records = append(records, record)
+			}
+		}
+	}
+
+	return records, nil
+}
+
+func (o *Auditor) currentFile(now time.Time) (*os.File, error) {
+	date := now.Format(AuditFileDateFormat)
+	if o.file != nil && o.fileDate == date {
+		return o.file, nil
+	}
+
+	if o.file != nil {
+		_ = o.file.Close()
+		o.file = nil
+	}
+
+	if err := o.prune(now); err != nil {
+		return nil, err
+	}
+
+	filePath := filepath.Join(o.dir, AuditFilePrefix+date+AuditFileExtension)
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, errors.New("error opening audit file: " + err.Error())
+	}
+
+	o.file = file
+	o.fileDate = date
+
+	return file, nil
+}
+
+// prune removes the audit files that are out of the retention window. A retention of zero keeps every file.
+func (o *Auditor) prune(now time.Time) error {
+	if o.retentionDays <= 0 {
+		return nil
+	}
+
+	files, err := o.files()
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.UTC().AddDate(0, 0, -o.retentionDays).Format(AuditFileDateFormat)
+	for _, name := range files {
+		date := strings.TrimSuffix(strings.TrimPrefix(name, AuditFilePrefix), AuditFileExtension)
+		if date < cutoff {
+			if err := os.Remove(filepath.Join(o.dir, name)); err != nil {
+				return fmt.Errorf("error removing expired audit file %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *Auditor) files() ([]string, error) {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, errors.New("error reading audit directory: " + err.Error())
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), AuditFilePrefix) && filepath.Ext(entry.Name()) == AuditFileExtension {
+			files = append(files, entry.Name())
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func (o Filter) matches(record Record) bool {
+	if !o.From.IsZero() && record.Timestamp.Before(o.From) {
+		return false
+	}
+	if !o.To.IsZero() && record.Timestamp.After(o.To) {
+		return false
+	}
+	if o.Action != "" && record.Action != o.Action {
+		return false
+	}
+	if o.Executable != "" {
+		found := false
+		for _, target := range record.Executables {
+			if target.ID == o.Executable || target.Name == o.Executable {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func readRecords(filePath string) ([]Record, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.New("error opening audit file: " + err.Error())
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("error reading audit file: " + err.Error())
+	}
+
+	return records, nil
+}
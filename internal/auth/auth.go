@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+var ErrUnauthorized = errors.New("unauthorized")
+
+type contextKey struct{}
+
+// Peer describes the other end of a connection. Credentials are only available for Unix socket connections.
+type Peer struct {
+	RemoteAddr  string
+	Credentials *Credentials
+}
+
+type Credentials struct {
+	UID int
+	GID int
+	PID int
+}
+
+/*
+Authorizer decides whether a peer may use the control API.
+Peers on the TCP port are allowed, as they have always been. Peers on the Unix socket are authorized by their
+credentials: their uid or gid must be in the allowlists. With empty allowlists, root and the uid of the
+orchestrator itself are allowed.
+*/
+type Authorizer struct {
+	allowedUIDs map[int]bool
+	allowedGIDs map[int]bool
+}
+
+func NewAuthorizer(allowedUIDs []int, allowedGIDs []int) *Authorizer {
+	authorizer := &Authorizer{
+		allowedUIDs: make(map[int]bool),
+		allowedGIDs: make(map[int]bool),
+	}
+
+	for _, uid := range allowedUIDs {
+		authorizer.allowedUIDs[uid] = true
+	}
+	for _, gid := range allowedGIDs {
+		authorizer.allowedGIDs[gid] = true
+	}
+
+	if len(authorizer.allowedUIDs) == 0 && len(authorizer.allowedGIDs) == 0 {
+		authorizer.allowedUIDs[0] = true
+		authorizer.allowedUIDs[os.Getuid()] = true
+	}
+
+	return authorizer
+}
+
+func (o *Authorizer) Authorize(peer Peer) error {
+	if peer.Credentials == nil {
+		return nil
+	}
+
+	if o.allowedUIDs[peer.Credentials.UID] || o.allowedGIDs[peer.Credentials.GID] {
+		return nil
+	}
+
+	return fmt.Errorf("%w: uid %d gid %d is not allowed", ErrUnauthorized, peer.Credentials.UID, peer.Credentials.GID)
+}
+
+// Caller identifies the peer in the audit log.
+func (o Peer) Caller() string {
+	if o.Credentials != nil {
+		return "uid:" + strconv.Itoa(o.Credentials.UID) + " gid:" + strconv.Itoa(o.Credentials.GID) + " pid:" + strconv.Itoa(o.Credentials.PID)
+	}
+
+	return o.RemoteAddr
+}
+
+func WithPeer(ctx context.Context, peer Peer) context.Context {
+	return context.WithValue(ctx, contextKey{}, peer)
+}
+
+func PeerFromContext(ctx context.Context) (Peer, bool) {
+	peer, ok := ctx.Value(contextKey{}).(Peer)
+
+	return peer, ok
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// PeerCredentials reads the credentials of the process on the other end of a Unix socket with SO_PEERCRED.
+func PeerCredentials(conn net.Conn) (*Credentials, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, errors.New("peer credentials are only available on unix sockets")
+	}
+
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return nil, errors.New("error accessing unix socket: " + err.Error())
+	}
+
+	var ucred *syscall.Ucred
+	var sockoptErr error
+	err = rawConn.Control(func(fd uintptr) {
+		ucred, sockoptErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, errors.New("error accessing unix socket: " + err.Error())
+	}
+	if sockoptErr != nil {
+		return nil, errors.New("error reading peer credentials: " + sockoptErr.Error())
+	}
+
+	return &Credentials{UID: int(ucred.Uid), GID: int(ucred.Gid), PID: int(ucred.Pid)}, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package auth
+
+import (
+	"errors"
+	"net"
+)
+
+func PeerCredentials(conn net.Conn) (*Credentials, error) {
+	return nil, errors.New("peer credentials are not supported on this platform")
+}
@@ -13,6 +13,19 @@ type Config struct {
 	HTTP_PORT             string `envconfig:"HTTP_PORT"  required:"true"`
 	EXECUTABLES_JSON_PATH string `envconfig:"EXECUTABLES_JSON_PATH" required:"true"`
 	AUTOSETRUN            bool   `envconfig:"AUTOSETRUN" required:"true"`
+	PERSIST_EXECUTABLES   bool   `envconfig:"PERSIST_EXECUTABLES" default:"false"`
+	AUDIT_LOG_DIR         string `envconfig:"AUDIT_LOG_DIR" default:"audit"`
+	AUDIT_RETENTION_DAYS  int    `envconfig:"AUDIT_RETENTION_DAYS" default:"365"`
+	WEBHOOKS_DIR          string `envconfig:"WEBHOOKS_DIR" default:"webhooks"`
+	ALERTS_DIR            string `envconfig:"ALERTS_DIR" default:"alerts"`
+	CGROUP_DIR            string `envconfig:"CGROUP_DIR"`
+	RELEASES_DIR          string `envconfig:"RELEASES_DIR"`
+	TRUSTED_KEYS_PATH     string `envconfig:"TRUSTED_KEYS_PATH"`
+	GRPC_PORT             string `envconfig:"GRPC_PORT"`
+	UNIX_SOCKET_PATH      string `envconfig:"UNIX_SOCKET_PATH"`
+	UNIX_SOCKET_MODE      string `envconfig:"UNIX_SOCKET_MODE" default:"0660"`
+	UNIX_SOCKET_UIDS      []int  `envconfig:"UNIX_SOCKET_UIDS"`
+	UNIX_SOCKET_GIDS      []int  `envconfig:"UNIX_SOCKET_GIDS"`
 }
 
 func load() (*Config, error) {
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	SubscriberBufferSize = 256
+)
+
+type Event struct {
+	Timestamp      time.Time         `json:"timestamp"`
+	Type           string            `json:"type"`
+	ExecutableID   string            `json:"executable_id,omitempty"`
+	ExecutableName string            `json:"executable_name,omitempty"`
+	Group          string            `json:"group,omitempty"`
+	Message        string            `json:"message,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"`
+}
+
+// Bus fans out the lifecycle events of the orchestrator to its subscribers. Publishing never blocks:
+// a subscriber that does not keep up loses the events that do not fit in its buffer.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[int]chan Event
+	next        int
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[int]chan Event),
+	}
+}
+
+func (o *Bus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, subscriber := range o.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of the events published from now on and a function that cancels the subscription.
+func (o *Bus) Subscribe() (<-chan Event, func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	id := o.next
+	o.next++
+
+	subscriber := make(chan Event, SubscriberBufferSize)
+	o.subscribers[id] = subscriber
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+
+			delete(o.subscribers, id)
+			close(subscriber)
+		})
+	}
+
+	return subscriber, cancel
+}
@@ -15,6 +15,7 @@ var (
 	OrchestratorLogs       = "/logs/"
 	LogTypeError           = "errors"
 	LogTypeOut             = "out"
+	LogTypeBuild           = "build"
 )
 
 func NewLogger() (*log.Logger, func()) {
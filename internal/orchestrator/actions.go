@@ -0,0 +1,330 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/internal/logger"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ActionSignal      = "signal"
+	ActionSignalGroup = "signal_group"
+	ActionRunAction   = "run_action"
+)
+
+var (
+	EventSignalSent      = "signal_sent"
+	EventActionSucceeded = "action_succeeded"
+	EventActionFailed    = "action_failed"
+)
+
+var (
+	DefaultActionTimeout = 30 * time.Second
+	// ActionOutputBytes is the size of the end of the output of a command action that is returned.
+	ActionOutputBytes = 64 * 1024
+)
+
+// Signals are the signals that can be sent to executables, by name without the SIG prefix.
+var Signals = map[string]syscall.Signal{
+	"HUP":   syscall.SIGHUP,
+	"INT":   syscall.SIGINT,
+	"QUIT":  syscall.SIGQUIT,
+	"KILL":  syscall.SIGKILL,
+	"USR1":  syscall.SIGUSR1,
+	"USR2":  syscall.SIGUSR2,
+	"TERM":  syscall.SIGTERM,
+	"ALRM":  syscall.SIGALRM,
+	"WINCH": syscall.SIGWINCH,
+}
+
+var actionNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+/*
+Action is a named control action of an executable: either a signal sent to its process, such as HUP to reload its
+configuration, or a command run with its working directory and environment, such as a flush script.
+*/
+type Action struct {
+	Signal string `json:"signal,omitempty" yaml:"signal,omitempty" toml:"signal,omitempty"`
+	// Command is the binary and the arguments of the command.
+	Command []string `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"`
+	// Timeout is a duration such as 10s, 30s by default. It only applies to commands.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+}
+
+type ActionResult struct {
+	Action string `json:"action"`
+	Signal string `json:"signal,omitempty"`
+	// ExitCode and Output are only set for commands.
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Output   string `json:"output,omitempty"`
+}
+
+// ActionError is a command action that failed, with its result.
+type ActionError struct {
+	Result ActionResult
+	err    error
+}
+
+func (o *ActionError) Error() string {
+	return fmt.Sprintf("%s: action %s: %s", ErrActionFailed.Error(), o.Result.Action, o.err.Error())
+}
+
+func (o *ActionError) Unwrap() error {
+	return ErrActionFailed
+}
+
+// parseSignal returns the signal of a name such as HUP, SIGHUP or hup, if it is one of the signals that can be sent.
+func parseSignal(name string) (syscall.Signal, error) {
+	signal, ok := Signals[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		names := make([]string, 0, len(Signals))
+		for name := range Signals {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return 0, fmt.Errorf("%w: signal must be one of %s: %s", ErrInvalidArgument, strings.Join(names, ", "), name)
+	}
+
+	return signal, nil
+}
+
+func (o Action) timeout() time.Duration {
+	timeout, err := time.ParseDuration(o.Timeout)
+	if err != nil || timeout <= 0 {
+		return DefaultActionTimeout
+	}
+
+	return timeout
+}
+
+// actionNames returns the names of the actions of the configuration, sorted.
+func (o Configuration) actionNames() []string {
+	names := make([]string, 0, len(o.Actions))
+	for name := range o.Actions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Signal sends a signal to the process of a running executable.
+func (o *Orchestrator) Signal(ctx context.Context, processUUID uuid.UUID, signalName string) (err error) {
+	executable := o.executable(processUUID)
+	defer func() {
+		o.audit(ctx, ActionSignal, Executables{executable}, err)
+	}()
+
+	if executable == nil {
+		return ErrExecutableNotFound
+	}
+
+	signal, err := parseSignal(signalName)
+	if err != nil {
+		return err
+	}
+
+	return o.signalExecutable(executable, signal)
+}
+
+// SignalGroup sends a signal to the running executables of a group. The executables that are not running are skipped.
+func (o *Orchestrator) SignalGroup(ctx context.Context, group string, signalName string) (err error) {
+	executables := o.executables()
+	executablesGroup := Executables{}
+	for _, executable := range executables {
+		if executable.Group == group && executable.Process.running() {
+			executablesGroup = append(executablesGroup, executable)
+		}
+	}
+
+	defer func() {
+		o.audit(ctx, ActionSignalGroup, executablesGroup, err)
+	}()
+
+	if !slices.ContainsFunc(executables, func(executable *Executable) bool { return executable.Group == group }) {
+		return ErrGroupNotFound
+	}
+
+	signal, err := parseSignal(signalName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, executable := range executablesGroup {
+		errs = append(errs, o.signalExecutable(executable, signal))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (o *Orchestrator) signalExecutable(executable *Executable, signal syscall.Signal) error {
+	process := executable.CMD
+	if process == nil || process.Process == nil || !executable.Process.running() {
+		return fmt.Errorf("%w: %s", ErrExecutableNotRunning, executable.Name)
+	}
+
+	if err := process.Process.Signal(signal); err != nil {
+		return fmt.Errorf("failed to signal executable %s : %w", executable.Name, err)
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Sent %s to executable %s", signalName(signal), executable.Name)
+	o.publish(EventSignalSent, executable, "", map[string]string{"signal": signalName(signal), "pid": strconv.Itoa(executable.PID)})
+
+	return nil
+}
+
+// signalName returns the name of a signal that can be sent, without the SIG prefix.
+func signalName(signal syscall.Signal) string {
+	for name, known := range Signals {
+		if known == signal {
+			return name
+		}
+	}
+
+	return signal.String()
+}
+
+/*
+RunAction runs a named action of an executable. A signal action requires the executable to be running. A command
+action runs whether it is running or not, with ORCHESTRATOR_EXECUTABLE, ORCHESTRATOR_ACTION and, when it is
+running, ORCHESTRATOR_PID, and its output is returned. A command that fails returns an ActionError with its result.
+*/
+func (o *Orchestrator) RunAction(ctx context.Context, processUUID uuid.UUID, name string) (result ActionResult, err error) {
+	executable := o.executable(processUUID)
+	defer func() {
+		o.audit(ctx, ActionRunAction, Executables{executable}, err)
+	}()
+
+	if executable == nil {
+		return ActionResult{}, ErrExecutableNotFound
+	}
+
+	action, ok := executable.Actions[name]
+	if !ok {
+		return ActionResult{}, fmt.Errorf("%w: %s of %s", ErrActionNotFound, name, executable.Name)
+	}
+
+	result = ActionResult{Action: name}
+	if action.Signal != "" {
+		signal, err := parseSignal(action.Signal)
+		if err != nil {
+			return ActionResult{}, err
+		}
+
+		result.Signal = signalName(signal)
+		return result, o.signalExecutable(executable, signal)
+	}
+
+	pid := 0
+	if executable.Process.running() {
+		pid = executable.PID
+	}
+
+	result, err = executable.runAction(name, action, executable.Paths.Actions[name], pid)
+	if err != nil {
+		o.Logger.Printf(logger.LogErr+"Action %s of executable %s failed: %s", name, executable.Name, err.Error())
+		o.publish(EventActionFailed, executable, err.Error(), map[string]string{"action": name})
+		return result, &ActionError{Result: result, err: err}
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Action %s of executable %s succeeded", name, executable.Name)
+	o.publish(EventActionSucceeded, executable, "", map[string]string{"action": name})
+
+	return result, nil
+}
+
+func (o *Executable) runAction(name string, action Action, path string, pid int) (ActionResult, error) {
+	result := ActionResult{Action: name}
+
+	ctx, cancel := context.WithTimeout(context.Background(), action.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, action.Command[1:]...)
+	cmd.Dir = o.Paths.WorkingDir
+	cmd.Env = o.commandEnvironment(pid, "ORCHESTRATOR_ACTION="+name)
+
+	output, err := cmd.CombinedOutput()
+	if len(output) > ActionOutputBytes {
+		output = output[len(output)-ActionOutputBytes:]
+	}
+	result.Output = strings.ToValidUTF8(string(output), "")
+
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		exitCode := 0
+		if exitErr != nil {
+			exitCode = exitErr.ExitCode()
+		}
+		result.ExitCode = &exitCode
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result, errors.New("timed out after " + action.timeout().String())
+	}
+
+	return result, err
+}
+
+// commandEnvironment is the environment of the commands run for an executable, such as hooks and actions.
+func (o *Executable) commandEnvironment(pid int, variables ...string) []string {
+	environment := append(os.Environ(), "ORCHESTRATOR_EXECUTABLE="+o.Name)
+	environment = append(environment, variables...)
+	if pid != 0 {
+		environment = append(environment, "ORCHESTRATOR_PID="+strconv.Itoa(pid))
+	}
+	for key, value := range o.Env {
+		environment = append(environment, key+"="+value)
+	}
+
+	return environment
+}
+
+// validateActions checks the actions of the configuration, with their resolved commands.
+func (o *Executable) validateActions() []error {
+	var errs []error
+	for _, name := range o.actionNames() {
+		action := o.Actions[name]
+		invalid := func(message string) {
+			errs = append(errs, &FieldError{Field: "actions", Message: name + ": " + message})
+		}
+
+		if !actionNamePattern.MatchString(name) {
+			invalid("name must be lowercase letters, digits, - and _: " + o.Name)
+		}
+
+		switch {
+		case action.Signal != "" && len(action.Command) > 0:
+			invalid("signal and command are mutually exclusive: " + o.Name)
+		case action.Signal != "":
+			if _, err := parseSignal(action.Signal); err != nil {
+				invalid(strings.TrimPrefix(err.Error(), ErrInvalidArgument.Error()+": "))
+			}
+		case len(action.Command) > 0:
+			if info, err := os.Stat(o.Paths.Actions[name]); err != nil {
+				invalid("error stating command: " + action.Command[0])
+			} else if info.IsDir() || info.Mode()&0111 == 0 {
+				invalid("command is not executable: " + action.Command[0])
+			}
+		default:
+			invalid("signal or command is required: " + o.Name)
+		}
+
+		if timeout, err := time.ParseDuration(action.Timeout); action.Timeout != "" && (err != nil || timeout <= 0) {
+			invalid("timeout must be a positive duration such as 10s: " + action.Timeout)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,269 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"orchestrator/internal/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	DefaultBuildTimeout = 5 * time.Minute
+	// BuildErrorTailBytes is the size of the end of the build log that is attached to the error of a failed build.
+	BuildErrorTailBytes = 2048
+)
+
+/*
+Build is a command that produces the binary of an executable, run before every start. It is skipped when the
+output exists and the hash of the inputs, the command and the environment is the one of the last successful build.
+*/
+type Build struct {
+	Command   string   `json:"command" yaml:"command" toml:"command"`
+	Arguments []string `json:"arguments,omitempty" yaml:"arguments,omitempty" toml:"arguments,omitempty"`
+	// WorkingDir is where the command runs, the working directory of the executable by default.
+	WorkingDir string `json:"working_dir,omitempty" yaml:"working_dir,omitempty" toml:"working_dir,omitempty"`
+	// Inputs are glob patterns of the files the output is built from. Matched directories count with all their files.
+	Inputs []string `json:"inputs,omitempty" yaml:"inputs,omitempty" toml:"inputs,omitempty"`
+	// Output is the artifact the command produces, usually the binary path.
+	Output string `json:"output" yaml:"output" toml:"output"`
+	// Timeout is a duration such as 2m, 5m by default.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+}
+
+// BuildPaths are the resolved command, working directory, input patterns and output of the build.
+type BuildPaths struct {
+	Command    string
+	WorkingDir string
+	Inputs     []string
+	Output     string
+}
+
+func (o Build) timeout() time.Duration {
+	timeout, err := time.ParseDuration(o.Timeout)
+	if err != nil || timeout <= 0 {
+		return DefaultBuildTimeout
+	}
+
+	return timeout
+}
+
+func (o Build) clone() *Build {
+	o.Arguments = slices.Clone(o.Arguments)
+	o.Inputs = slices.Clone(o.Inputs)
+
+	return &o
+}
+
+// stampPath is the file next to the output that keeps the hash of the inputs of the last successful build.
+func (o BuildPaths) stampPath() string {
+	return filepath.Join(filepath.Dir(o.Output), "."+filepath.Base(o.Output)+".build-sha256")
+}
+
+/*
+build runs the build of the executable, if it has one and its inputs changed. The instances of an executable share
+their build, so that one of them builds while the others wait and find it up to date. A failed build is returned
+with the end of its output.
+*/
+func (o *Orchestrator) build(executable *Executable) error {
+	if executable.Build == nil {
+		return nil
+	}
+
+	lock, _ := o.builds.LoadOrStore(executable.Declared.Name, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	err := o.rebuild(executable)
+	for _, instance := range o.instances(executable) {
+		instance.buildErr = err
+	}
+
+	return err
+}
+
+// rebuild runs the build command unless the output is up to date.
+func (o *Orchestrator) rebuild(executable *Executable) error {
+	paths := executable.Paths.Build
+	hash, err := executable.buildHash()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrBuildFailed, executable.Name, err.Error())
+	}
+
+	stamp, _ := os.ReadFile(paths.stampPath())
+	if _, err := os.Stat(paths.Output); err == nil && string(stamp) == hash {
+		o.Logger.Printf(logger.LogInfo+"Build of executable %s is up to date", executable.Name)
+		return nil
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Building executable %s", executable.Name)
+	started := time.Now()
+	logFilePath, err := executable.runBuild()
+	if err == nil {
+		if _, statErr := os.Stat(paths.Output); statErr != nil {
+			err = errors.New("the build did not produce " + paths.Output)
+		}
+	}
+	if err != nil {
+		if tail := logTail(logFilePath, BuildErrorTailBytes); tail != "" {
+			err = errors.New(err.Error() + "\n" + tail)
+		}
+		_ = os.Remove(paths.stampPath())
+		o.Logger.Printf(logger.LogErr+"Build of executable %s failed: %s", executable.Name, err.Error())
+		o.publish(EventBuildFailed, executable, err.Error(), map[string]string{"log": logFilePath})
+		return fmt.Errorf("%w: %s: %s", ErrBuildFailed, executable.Name, err.Error())
+	}
+
+	if err := os.WriteFile(paths.stampPath(), []byte(hash), 0644); err != nil {
+		o.Logger.Printf(logger.LogErr+"Error recording the build of executable %s: %s", executable.Name, err.Error())
+	}
+	o.Logger.Printf(logger.LogInfo+"Build of executable %s succeeded", executable.Name)
+	o.publish(EventBuildSucceeded, executable, "", map[string]string{"duration": time.Since(started).Round(time.Millisecond).String(), "log": logFilePath})
+
+	return nil
+}
+
+// runBuild runs the build command, with its output written to "<log_file_name>.build-<timestamp>.log" in the log directory.
+func (o *Executable) runBuild() (string, error) {
+	timestamp := time.Now().Format(logger.LoggingTimestampFormat)
+	logFilePath := o.Paths.LogDir + "/" + fmt.Sprintf("%s.%s-%s.log", o.LogFileName, logger.LogTypeBuild, timestamp)
+
+	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", errors.New("failed to open build log file: " + err.Error())
+	}
+	defer logFile.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Build.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, o.Paths.Build.Command, o.Build.Arguments...)
+	cmd.Dir = o.Paths.Build.WorkingDir
+	cmd.Env = o.commandEnvironment(0)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return logFilePath, errors.New("timed out after " + o.Build.timeout().String())
+	}
+
+	return logFilePath, err
+}
+
+/*
+buildHash hashes the build command, the environment of the executable and the content of its input files. The output,
+the log directory and hidden files, such as the stamp of the build, are not inputs. Without inputs, every file of the
+working directory of the build is.
+*/
+func (o *Executable) buildHash() (string, error) {
+	paths := o.Paths.Build
+	patterns := paths.Inputs
+	if len(patterns) == 0 {
+		patterns = []string{paths.WorkingDir}
+	}
+
+	excluded := func(match string, path string) bool {
+		return (path != match && strings.HasPrefix(filepath.Base(path), ".")) || path == paths.Output ||
+			(o.Paths.LogDir != "" && (path == o.Paths.LogDir || strings.HasPrefix(path, o.Paths.LogDir+string(filepath.Separator))))
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", errors.New("invalid input pattern: " + pattern)
+		}
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if excluded(match, path) {
+					if entry.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if entry.Type().IsRegular() {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return "", errors.New("error reading build inputs: " + err.Error())
+			}
+		}
+	}
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", paths.Command, strings.Join(o.Build.Arguments, "\x00"), paths.WorkingDir)
+	keys := slices.Sorted(maps.Keys(o.Env))
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\x00", key, o.Env[key])
+	}
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", errors.New("error reading build input: " + err.Error())
+		}
+		fmt.Fprintf(hash, "%s\x00", path)
+		_, err = io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			return "", errors.New("error reading build input: " + err.Error())
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// validateBuild checks the build of the configuration, with its resolved command and paths.
+func (o *Executable) validateBuild() []error {
+	if o.Build == nil {
+		return nil
+	}
+
+	var errs []error
+	invalid := func(message string) {
+		errs = append(errs, &FieldError{Field: "build", Message: message})
+	}
+
+	if o.Build.Command == "" {
+		invalid("command is required: " + o.Name)
+	} else if info, err := os.Stat(o.Paths.Build.Command); err != nil {
+		invalid("error stating command: " + o.Build.Command)
+	} else if info.IsDir() || info.Mode()&0111 == 0 {
+		invalid("command is not executable: " + o.Build.Command)
+	}
+
+	if info, err := os.Stat(o.Paths.Build.WorkingDir); err != nil || !info.IsDir() {
+		invalid("working directory is not a directory: " + o.Paths.Build.WorkingDir)
+	}
+	for _, pattern := range o.Paths.Build.Inputs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			invalid("invalid input pattern: " + pattern)
+		}
+	}
+	if o.Build.Output == "" {
+		invalid("output is required: " + o.Name)
+	}
+	if timeout, err := time.ParseDuration(o.Build.Timeout); o.Build.Timeout != "" && (err != nil || timeout <= 0) {
+		invalid("timeout must be a positive duration such as 2m: " + o.Build.Timeout)
+	}
+
+	return errs
+}
@@ -0,0 +1,164 @@
+package orchestrator
+
+import (
+	"bufio"
+	"errors"
+	"orchestrator/internal/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// CgroupsDirName is the cgroup created under the one of the orchestrator for the cgroups of the executables.
+	CgroupsDirName = "orchestrator"
+	// CgroupFreezeTimeout is how long freezing or thawing a cgroup is waited for.
+	CgroupFreezeTimeout = 5 * time.Second
+)
+
+/*
+Cgroups places every executable in its own cgroup v2, so that all of its processes can be frozen and thawed at once
+by the freezer. The cgroups are created under a parent directory that the orchestrator can write to: by default a
+cgroup named after CgroupsDirName below the cgroup of the orchestrator.
+*/
+type Cgroups struct {
+	dir string
+}
+
+/*
+newCgroups prepares the parent cgroup of the executables. An empty dir is detected from the cgroup v2 hierarchy of the
+orchestrator. It fails when there is no cgroup v2 hierarchy, or the freezer cannot be used in it, e.g. without the
+permission to create cgroups.
+*/
+func newCgroups(dir string) (*Cgroups, error) {
+	if dir == "" {
+		detected, err := cgroupDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(detected, CgroupsDirName)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.New("error creating cgroup: " + err.Error())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cgroup.freeze")); err != nil {
+		return nil, errors.New("cgroup freezer is not available in " + dir)
+	}
+
+	// The cgroups left by a previous run, e.g. when children outlived an executable, are removed once they are empty.
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				removeCgroup(filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	return &Cgroups{dir: dir}, nil
+}
+
+// setupCgroups uses the cgroup freezer to pause the executables when it is available, and signals otherwise.
+func (o *Orchestrator) setupCgroups(dir string) {
+	cgroups, err := newCgroups(dir)
+	if err != nil {
+		o.Logger.Printf(logger.LogInfo+"Executables are paused with signals, the cgroup freezer is not available: %s", err.Error())
+		return
+	}
+
+	o.Cgroups = cgroups
+	o.Logger.Printf(logger.LogInfo+"Executables are started in cgroups under %s", cgroups.dir)
+}
+
+// cgroupDir returns the directory of the cgroup v2 of the orchestrator, from its mount point and /proc/self/cgroup.
+func cgroupDir() (string, error) {
+	mountPoint := ""
+	mounts, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "", errors.New("error reading mounts: " + err.Error())
+	}
+	defer mounts.Close()
+
+	scanner := bufio.NewScanner(mounts)
+	for scanner.Scan() {
+		// The filesystem type follows the " - " separator, the mount point is the fifth field.
+		fields, filesystem, ok := strings.Cut(scanner.Text(), " - ")
+		if ok && strings.HasPrefix(filesystem, "cgroup2 ") {
+			if parts := strings.Fields(fields); len(parts) >= 5 {
+				mountPoint = parts[4]
+				break
+			}
+		}
+	}
+	if mountPoint == "" {
+		return "", errors.New("no cgroup v2 hierarchy is mounted")
+	}
+
+	content, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", errors.New("error reading cgroup: " + err.Error())
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return filepath.Join(mountPoint, path), nil
+		}
+	}
+
+	return "", errors.New("the orchestrator is not in a cgroup v2")
+}
+
+// create makes the cgroup of an executable, thawed, and opens it for a process to be started in it.
+func (o *Cgroups) create(id uuid.UUID) (string, *os.File, error) {
+	path := filepath.Join(o.dir, id.String())
+	if err := os.Mkdir(path, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", nil, errors.New("error creating cgroup: " + err.Error())
+	}
+	if err := freezeCgroup(path, false); err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		return "", nil, errors.New("error opening cgroup: " + err.Error())
+	}
+
+	return path, dir, nil
+}
+
+// removeCgroup removes the cgroup of an executable once its processes exited. It stays while other processes are in it.
+func removeCgroup(path string) {
+	_ = os.Remove(path)
+}
+
+// freezeCgroup freezes or thaws the processes of a cgroup and waits until they are.
+func freezeCgroup(path string, frozen bool) error {
+	state := "0"
+	if frozen {
+		state = "1"
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "cgroup.freeze"), []byte(state), 0644); err != nil {
+		return errors.New("error writing cgroup.freeze: " + err.Error())
+	}
+
+	deadline := time.Now().Add(CgroupFreezeTimeout)
+	for {
+		events, err := os.ReadFile(filepath.Join(path, "cgroup.events"))
+		if err != nil {
+			return errors.New("error reading cgroup.events: " + err.Error())
+		}
+		for _, line := range strings.Split(string(events), "\n") {
+			if line == "frozen "+state {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return errors.New("cgroup did not change its frozen state in time: " + path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,26 @@
+package orchestrator
+
+import (
+	"os/exec"
+
+	"github.com/google/uuid"
+)
+
+/*
+placeInCgroup makes the command start in the cgroup of the executable, when there are cgroups. It returns the cgroup,
+empty when the process is not placed in one, and a function that releases the cgroup once the process started.
+*/
+func placeInCgroup(cmd *exec.Cmd, cgroups *Cgroups, id uuid.UUID) (string, func()) {
+	if cgroups == nil {
+		return "", func() {}
+	}
+
+	path, dir, err := cgroups.create(id)
+	if err != nil {
+		return "", func() {}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+
+	return path, func() { dir.Close() }
+}
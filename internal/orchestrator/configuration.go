@@ -0,0 +1,698 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ValidationIssue struct {
+	Source     string `json:"source,omitempty"`
+	Index      int    `json:"index"`
+	Executable string `json:"executable,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Message    string `json:"message"`
+}
+
+// ValidationReport collects every decoding and validation problem of a configuration, with the position of each one.
+type ValidationReport struct {
+	Valid  bool              `json:"valid"`
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// position is a 1-based line and column in the source of a configuration.
+type position struct {
+	Line   int
+	Column int
+}
+
+// rawField is a key of an executable in the source, decoded lazily so that each field reports its own type errors.
+type rawField struct {
+	Key           string
+	KeyPosition   position
+	ValuePosition position
+	decode        func(target any) error
+}
+
+type rawExecutable struct {
+	Index    int
+	Template string
+	Position position
+	Fields   []rawField
+}
+
+// rawDocument is a parsed configuration: its executables, and the defaults and templates they are merged with.
+type rawDocument struct {
+	Defaults    *rawExecutable
+	Templates   []rawExecutable
+	Executables []rawExecutable
+}
+
+func (o ValidationIssue) String() string {
+	var builder strings.Builder
+	if o.Source != "" {
+		builder.WriteString(o.Source + ":")
+	}
+	if o.Line > 0 {
+		builder.WriteString(strconv.Itoa(o.Line) + ":")
+	}
+	if o.Column > 0 {
+		builder.WriteString(strconv.Itoa(o.Column) + ":")
+	}
+	if builder.Len() > 0 {
+		builder.WriteString(" ")
+	}
+	if o.Executable != "" {
+		builder.WriteString(o.Executable + ": ")
+	} else if o.Index >= 0 {
+		builder.WriteString("executable #" + strconv.Itoa(o.Index) + ": ")
+	}
+	if o.Field != "" {
+		builder.WriteString(o.Field + ": ")
+	}
+	builder.WriteString(o.Message)
+
+	return builder.String()
+}
+
+func (o *ValidationReport) Error() string {
+	messages := make([]string, 0, len(o.Issues))
+	for _, issue := range o.Issues {
+		messages = append(messages, issue.String())
+	}
+
+	return fmt.Sprintf("%s: %d issue(s): %s", ErrInvalidConfiguration.Error(), len(o.Issues), strings.Join(messages, "; "))
+}
+
+func (o *ValidationReport) Unwrap() error {
+	return ErrInvalidConfiguration
+}
+
+func (o *ValidationReport) add(issue ValidationIssue) {
+	o.Issues = append(o.Issues, issue)
+	o.Valid = false
+}
+
+// sort orders the issues by source and position.
+func (o *ValidationReport) sort() {
+	sort.SliceStable(o.Issues, func(i, j int) bool {
+		if o.Issues[i].Source != o.Issues[j].Source {
+			return o.Issues[i].Source < o.Issues[j].Source
+		}
+		if o.Issues[i].Line != o.Issues[j].Line {
+			return o.Issues[i].Line < o.Issues[j].Line
+		}
+		return o.Issues[i].Column < o.Issues[j].Column
+	})
+}
+
+// ValidateConfigurationFile decodes and validates the configuration files without applying them.
+func ValidateConfigurationFile(path string) (*ValidationReport, error) {
+	_, report, err := loadConfiguration(path)
+
+	return report, err
+}
+
+// loadExecutables reads the executables from the configuration files and validates them.
+func loadExecutables(path string) (Executables, error) {
+	executables, report, err := loadConfiguration(path)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Valid {
+		return nil, report
+	}
+
+	return executables, nil
+}
+
+/*
+loadConfiguration decodes every file of the configuration path and merges their executables in the order of the
+files. The names of the executables must be unique across all the files.
+*/
+func loadConfiguration(path string) (Executables, *ValidationReport, error) {
+	files, err := configurationFiles(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &ValidationReport{Valid: true, Issues: make([]ValidationIssue, 0)}
+	executables := make(Executables, 0)
+	names := make(map[string]string)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, errors.New("error opening executables file: " + err.Error())
+		}
+
+		fileExecutables, fileReport := decodeExecutables(content, file, ConfigurationFormat(file), names)
+		for _, issue := range fileReport.Issues {
+			report.add(issue)
+		}
+		executables = append(executables, fileExecutables...)
+	}
+	report.sort()
+
+	return executables, report, nil
+}
+
+/*
+configurationFiles lists the files of a configuration path in lexical order: the path itself, the JSON, YAML and
+TOML files of a directory (e.g. conf.d), or the matches of a glob (e.g. conf.d/*.json).
+*/
+func configurationFiles(path string) ([]string, error) {
+	if strings.ContainsAny(path, "*?[") {
+		files, err := filepath.Glob(path)
+		if err != nil {
+			return nil, errors.New("invalid executables path pattern: " + err.Error())
+		}
+		if len(files) == 0 {
+			return nil, errors.New("no executables file matches: " + path)
+		}
+		sort.Strings(files)
+
+		return files, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.New("error opening executables file: " + err.Error())
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, errors.New("error reading executables directory: " + err.Error())
+	}
+
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml", ".toml":
+		default:
+			continue
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files = append(files, filepath.Join(path, entry.Name()))
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no executables file found in directory: " + path)
+	}
+
+	return files, nil
+}
+
+// readExecutables decodes and validates a configuration in the given format that is not stored in a file, e.g. a request body.
+func readExecutables(reader io.Reader, format string) (Executables, *ValidationReport, error) {
+	switch format {
+	case "":
+		format = FormatJSON
+	case FormatJSON, FormatYAML, FormatTOML:
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown configuration format %s", ErrInvalidArgument, format)
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, errors.New("error reading configuration: " + err.Error())
+	}
+
+	executables, report := decodeExecutables(content, "", format, make(map[string]string))
+
+	return executables, report, nil
+}
+
+/*
+decodeExecutables strictly decodes a configuration in the given format: unknown fields and values of the wrong
+type are reported with their line and column, and every executable is validated. Decoding does not stop at the
+first problem, so the report lists everything that needs fixing. The names map records the source of every name
+already in use, so that a name collision with another file identifies both of them.
+*/
+func decodeExecutables(content []byte, source string, format string, names map[string]string) (Executables, *ValidationReport) {
+	report := &ValidationReport{Valid: true, Issues: make([]ValidationIssue, 0)}
+
+	document, issues := parseConfiguration(content, format)
+	resolver, resolverIssues := newTemplateResolver(document)
+	issues = append(issues, resolverIssues...)
+	for _, issue := range issues {
+		issue.Source = source
+		report.add(issue)
+	}
+
+	baseDir := sourceDir(source)
+	executables := make(Executables, 0, len(document.Executables))
+	for _, raw := range document.Executables {
+		index := raw.Index
+		raw, resolveIssues := resolver.resolve(raw)
+		instances, issues := buildExecutables(index, raw, baseDir)
+		issues = append(resolveIssues, issues...)
+
+		for _, executable := range instances {
+			executable.Source = source
+
+			if first, ok := names[executable.Name]; ok && executable.Name != "" {
+				message := "executable name is used more than once: " + executable.Name
+				if first != source {
+					message = "executable name is already defined in " + first + ": " + executable.Name
+				}
+				issues = append(issues, ValidationIssue{
+					Index:      index,
+					Executable: executable.Name,
+					Field:      "name",
+					Line:       raw.fieldPosition("name").Line,
+					Column:     raw.fieldPosition("name").Column,
+					Message:    message,
+				})
+			} else {
+				names[executable.Name] = source
+			}
+		}
+
+		for _, issue := range issues {
+			issue.Source = source
+			if issue.Executable == "" && len(instances) > 0 {
+				issue.Executable = instances[0].Declared.Name
+			}
+			report.add(issue)
+		}
+
+		executables = append(executables, instances...)
+	}
+
+	report.sort()
+
+	return executables, report
+}
+
+/*
+buildExecutables assigns the fields of the source to a configuration and builds its instances: the variables of
+every instance are interpolated, its paths resolved against the base directory, and it is validated. Only the
+problems of the first instance that has some are reported, since the instances share their configuration.
+*/
+func buildExecutables(index int, raw rawExecutable, baseDir string) (Executables, []ValidationIssue) {
+	declared, issues := decodeConfiguration(index, raw)
+
+	undecoded := make(map[string]bool)
+	for _, issue := range issues {
+		undecoded[issue.Field] = true
+	}
+
+	fieldIssue := func(executable *Executable, err error) ValidationIssue {
+		issue := ValidationIssue{Index: index, Executable: executable.Name, Message: err.Error()}
+
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			issue.Field = fieldErr.Field
+		}
+
+		position := raw.fieldPosition(issue.Field)
+		issue.Line, issue.Column = position.Line, position.Column
+
+		return issue
+	}
+
+	executables := make(Executables, 0, declared.replicaCount())
+	reported := false
+	for instance := 0; instance < declared.replicaCount(); instance++ {
+		executable := newInstance(declared, instance)
+
+		var instanceIssues []ValidationIssue
+		failed := make(map[string]bool)
+		for _, err := range unwrapErrors(executable.prepare(baseDir)) {
+			issue := fieldIssue(executable, err)
+			failed[issue.Field] = true
+			if !undecoded[issue.Field] {
+				instanceIssues = append(instanceIssues, issue)
+			}
+		}
+
+		for _, err := range unwrapErrors(executable.validate()) {
+			issue := fieldIssue(executable, err)
+			// A field that could not be decoded or resolved is already reported.
+			if !undecoded[issue.Field] && !failed[issue.Field] {
+				instanceIssues = append(instanceIssues, issue)
+			}
+		}
+
+		if !reported && len(instanceIssues) > 0 {
+			issues = append(issues, instanceIssues...)
+			reported = true
+		}
+
+		executables = append(executables, executable)
+	}
+
+	return executables, issues
+}
+
+// decodeConfiguration assigns the fields of the source to a configuration, reporting unknown, repeated and mistyped ones.
+func decodeConfiguration(index int, raw rawExecutable) (Configuration, []ValidationIssue) {
+	var issues []ValidationIssue
+	configuration := Configuration{}
+
+	fields := configurationFields()
+	seen := make(map[string]bool, len(raw.Fields))
+	target := reflect.ValueOf(&configuration).Elem()
+
+	for _, field := range raw.Fields {
+		issue := ValidationIssue{Index: index, Field: field.Key, Line: field.KeyPosition.Line, Column: field.KeyPosition.Column}
+
+		fieldIndex, ok := fields[field.Key]
+		if !ok {
+			issue.Message = "unknown field"
+			issues = append(issues, issue)
+			continue
+		}
+
+		if seen[field.Key] {
+			issue.Message = "field is set more than once"
+			issues = append(issues, issue)
+			continue
+		}
+		seen[field.Key] = true
+
+		value := target.FieldByIndex(fieldIndex)
+		err := field.decode(value.Addr().Interface())
+		if err != nil {
+			issue.Line, issue.Column = field.ValuePosition.Line, field.ValuePosition.Column
+			issue.Message = "invalid value, expected " + describeType(value.Type())
+			// The problem inside an object, e.g. an unknown key of a hook, is worth showing.
+			valueType := value.Type()
+			if valueType.Kind() == reflect.Pointer {
+				valueType = valueType.Elem()
+			}
+			if valueType.Kind() == reflect.Struct {
+				issue.Message += ": " + strings.TrimPrefix(err.Error(), "json: ")
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	return configuration, issues
+}
+
+// validationReport reports the validation errors of an executable that has no source, e.g. one created through the API.
+func validationReport(executable *Executable, err error) *ValidationReport {
+	report := &ValidationReport{Valid: true, Issues: make([]ValidationIssue, 0)}
+
+	for _, err := range unwrapErrors(err) {
+		issue := ValidationIssue{Index: -1, Executable: executable.Name, Message: err.Error()}
+
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			issue.Field = fieldErr.Field
+		}
+
+		report.add(issue)
+	}
+
+	return report
+}
+
+// unwrapErrors flattens joined errors, also when they are joined again, e.g. the ones of prepare and validate.
+func unwrapErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, unwrapErrors(err)...)
+		}
+		return errs
+	}
+
+	return []error{err}
+}
+
+// fieldPosition returns the position of the key in the source, or of the executable when the key is missing.
+func (o rawExecutable) fieldPosition(key string) position {
+	for _, field := range o.Fields {
+		if field.Key == key {
+			return field.KeyPosition
+		}
+	}
+
+	return o.Position
+}
+
+// configurationFields maps the JSON keys of the configuration to the index of their struct field.
+func configurationFields() map[string][]int {
+	fields := make(map[string][]int)
+
+	configurationType := reflect.TypeOf(Configuration{})
+	for i := 0; i < configurationType.NumField(); i++ {
+		field := configurationType.Field(i)
+
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+
+		fields[key] = field.Index
+	}
+
+	return fields
+}
+
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array of " + strings.TrimPrefix(strings.TrimPrefix(describeType(t.Elem()), "a "), "an ") + "s"
+	case reflect.Map:
+		return "an object of " + strings.TrimPrefix(strings.TrimPrefix(describeType(t.Elem()), "a "), "an ") + "s"
+	case reflect.Pointer:
+		return describeType(t.Elem())
+	default:
+		return "an object"
+	}
+}
+
+// parseJSON splits a JSON configuration into its executables, defaults and templates, keeping the position of every key and value.
+func parseJSON(content []byte) (rawDocument, []ValidationIssue) {
+	lines := newLineIndex(content)
+
+	var whole json.RawMessage
+	if err := json.Unmarshal(content, &whole); err != nil {
+		offset := int64(len(content))
+		message := "unexpected end of configuration"
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) && syntaxErr.Offset < int64(len(content)) {
+			offset, message = syntaxErr.Offset, strings.TrimPrefix(err.Error(), "json: ")
+		}
+		position := lines.position(offset)
+
+		return rawDocument{}, []ValidationIssue{{Index: -1, Line: position.Line, Column: position.Column, Message: message}}
+	}
+
+	start := skipSeparators(content, 0)
+	switch content[start] {
+	case '[':
+		executables, issues := parseJSONExecutables(content[start:], start, lines)
+		return rawDocument{Executables: executables}, issues
+	case '{':
+	default:
+		position := lines.position(start)
+		return rawDocument{}, []ValidationIssue{{Index: -1, Line: position.Line, Column: position.Column, Message: "configuration must be an array of executables or an object"}}
+	}
+
+	var document rawDocument
+	var issues []ValidationIssue
+	members, _ := jsonMembers(content[start:], start)
+	for _, member := range members {
+		position := lines.position(member.ValueOffset)
+
+		switch member.Key {
+		case "executables":
+			if !bytes.HasPrefix(member.Value, []byte("[")) {
+				issues = append(issues, ValidationIssue{Index: -1, Field: member.Key, Line: position.Line, Column: position.Column, Message: "executables must be an array"})
+				continue
+			}
+			executables, executablesIssues := parseJSONExecutables(member.Value, member.ValueOffset, lines)
+			document.Executables = append(document.Executables, executables...)
+			issues = append(issues, executablesIssues...)
+		case "defaults":
+			defaults, ok := parseJSONObject(member.Value, member.ValueOffset, lines)
+			if !ok {
+				issues = append(issues, ValidationIssue{Index: -1, Field: member.Key, Line: position.Line, Column: position.Column, Message: "defaults must be an object"})
+				continue
+			}
+			defaults.Index = -1
+			document.Defaults = &defaults
+		case "templates":
+			templates, ok := jsonMembers(member.Value, member.ValueOffset)
+			if !ok {
+				issues = append(issues, ValidationIssue{Index: -1, Field: member.Key, Line: position.Line, Column: position.Column, Message: "templates must be an object"})
+				continue
+			}
+			for _, template := range templates {
+				raw, ok := parseJSONObject(template.Value, template.ValueOffset, lines)
+				if !ok {
+					position := lines.position(template.ValueOffset)
+					issues = append(issues, ValidationIssue{Index: -1, Executable: templateLabel(template.Key), Line: position.Line, Column: position.Column, Message: "template must be an object"})
+					continue
+				}
+				raw.Index, raw.Template = -1, template.Key
+				raw.Position = lines.position(template.KeyOffset)
+				document.Templates = append(document.Templates, raw)
+			}
+		default:
+			position := lines.position(member.KeyOffset)
+			issues = append(issues, ValidationIssue{Index: -1, Field: member.Key, Line: position.Line, Column: position.Column, Message: "unknown field"})
+		}
+	}
+
+	return document, issues
+}
+
+// parseJSONExecutables splits a JSON array of executables into their fields.
+func parseJSONExecutables(array []byte, base int64, lines lineIndex) ([]rawExecutable, []ValidationIssue) {
+	var executables []rawExecutable
+	var issues []ValidationIssue
+	for index, element := range jsonElements(array, base) {
+		executable, ok := parseJSONObject(element.Value, element.ValueOffset, lines)
+		if !ok {
+			position := lines.position(element.ValueOffset)
+			issues = append(issues, ValidationIssue{Index: index, Line: position.Line, Column: position.Column, Message: "executable must be an object"})
+			continue
+		}
+		executable.Index = index
+		executables = append(executables, executable)
+	}
+
+	return executables, issues
+}
+
+func parseJSONObject(element []byte, base int64, lines lineIndex) (rawExecutable, bool) {
+	executable := rawExecutable{Position: lines.position(base)}
+
+	members, ok := jsonMembers(element, base)
+	if !ok {
+		return executable, false
+	}
+
+	for _, member := range members {
+		value := member.Value
+		executable.Fields = append(executable.Fields, rawField{
+			Key:           member.Key,
+			KeyPosition:   lines.position(member.KeyOffset),
+			ValuePosition: lines.position(member.ValueOffset),
+			decode: func(target any) error {
+				decoder := json.NewDecoder(bytes.NewReader(value))
+				decoder.DisallowUnknownFields()
+				return decoder.Decode(target)
+			},
+		})
+	}
+
+	return executable, true
+}
+
+// jsonMember is a key of a JSON object, or an element of a JSON array, with the offsets of its key and value.
+type jsonMember struct {
+	Key         string
+	KeyOffset   int64
+	ValueOffset int64
+	Value       json.RawMessage
+}
+
+// jsonMembers splits a valid JSON object into its members. It returns false when the value is not an object.
+func jsonMembers(element []byte, base int64) ([]jsonMember, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(element))
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('{') {
+		return nil, false
+	}
+
+	var members []jsonMember
+	for decoder.More() {
+		member := jsonMember{KeyOffset: base + skipSeparators(element, decoder.InputOffset())}
+		token, err := decoder.Token()
+		if err != nil {
+			return members, false
+		}
+		member.Key, _ = token.(string)
+
+		member.ValueOffset = base + skipSeparators(element, decoder.InputOffset())
+		if err := decoder.Decode(&member.Value); err != nil {
+			return members, false
+		}
+		members = append(members, member)
+	}
+
+	return members, true
+}
+
+// jsonElements splits a valid JSON array into its elements.
+func jsonElements(array []byte, base int64) []jsonMember {
+	decoder := json.NewDecoder(bytes.NewReader(array))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil
+	}
+
+	var elements []jsonMember
+	for decoder.More() {
+		element := jsonMember{ValueOffset: base + skipSeparators(array, decoder.InputOffset())}
+		if err := decoder.Decode(&element.Value); err != nil {
+			return elements
+		}
+		elements = append(elements, element)
+	}
+
+	return elements
+}
+
+// skipSeparators returns the offset of the first byte from offset that is not whitespace, a comma or a colon.
+func skipSeparators(content []byte, offset int64) int64 {
+	for offset < int64(len(content)) && strings.IndexByte(" \t\r\n,:", content[offset]) >= 0 {
+		offset++
+	}
+
+	return offset
+}
+
+// lineIndex converts byte offsets to line and column positions.
+type lineIndex []int64
+
+func newLineIndex(content []byte) lineIndex {
+	index := lineIndex{0}
+	for offset, b := range content {
+		if b == '\n' {
+			index = append(index, int64(offset+1))
+		}
+	}
+
+	return index
+}
+
+func (o lineIndex) position(offset int64) position {
+	line := sort.Search(len(o), func(i int) bool { return o[i] > offset }) - 1
+	if line < 0 {
+		line = 0
+	}
+
+	return position{Line: line + 1, Column: int(offset-o[line]) + 1}
+}
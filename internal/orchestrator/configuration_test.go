@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidationReportFlattensNestedJoinedErrors(t *testing.T) {
+	err := errors.Join(
+		errors.Join(&FieldError{Field: "binary_path", Message: "unknown variable: ${nope}"}, errors.New("no field")),
+		errors.Join(&FieldError{Field: "working_dir", Message: "executable working directory is required: web"}),
+	)
+
+	report := validationReport(&Executable{Configuration: Configuration{Name: "web"}}, err)
+
+	if report.Valid {
+		t.Fatal("report is valid, want invalid")
+	}
+	want := []ValidationIssue{
+		{Index: -1, Executable: "web", Field: "binary_path", Message: "unknown variable: ${nope}"},
+		{Index: -1, Executable: "web", Message: "no field"},
+		{Index: -1, Executable: "web", Field: "working_dir", Message: "executable working directory is required: web"},
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("got %d issues %v, want %d", len(report.Issues), report.Issues, len(want))
+	}
+	for i, issue := range report.Issues {
+		if issue != want[i] {
+			t.Errorf("issue %d = %+v, want %+v", i, issue, want[i])
+		}
+	}
+}
+
+func TestValidationReportOfPrepareAndValidate(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "executables.json")
+	_, err := instantiate(Configuration{Name: "web", BinaryPath: "${nope}", Group: "1"}, 0, 1, source)
+
+	var report *ValidationReport
+	if !errors.As(err, &report) {
+		t.Fatalf("got error %v, want a validation report", err)
+	}
+	fields := make(map[string]bool)
+	for _, issue := range report.Issues {
+		if strings.Contains(issue.Message, "\n") {
+			t.Errorf("issue %+v has several errors in its message", issue)
+		}
+		if issue.Field == "" {
+			t.Errorf("issue %+v has no field", issue)
+		}
+		fields[issue.Field] = true
+	}
+	for _, field := range []string{"binary_path", "working_dir", "log_dir"} {
+		if !fields[field] {
+			t.Errorf("no issue for %s in %v", field, report.Issues)
+		}
+	}
+}
+
+func TestValidationReportWithoutError(t *testing.T) {
+	report := validationReport(&Executable{}, nil)
+
+	if !report.Valid || len(report.Issues) != 0 {
+		t.Errorf("got %+v, want a valid report without issues", report)
+	}
+}
@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"strconv"
+	"time"
+)
+
+var (
+	EventCrashLoopEntered = "crash_loop_entered"
+)
+
+var (
+	// CrashLoopRestarts automatic restarts within CrashLoopWindow make a crash loop.
+	CrashLoopRestarts = 5
+	CrashLoopWindow   = 5 * time.Minute
+)
+
+// CrashLoop keeps the recent automatic restarts of an executable. It is only used by the consumer of the notifications.
+type CrashLoop struct {
+	restarts []time.Time
+	entered  bool
+}
+
+/*
+trackRestart records an automatic restart of the executable and publishes crash_loop_entered when it makes a crash
+loop. The crash loop is left once the restarts within the window fall below the threshold again.
+*/
+func (o *Orchestrator) trackRestart(executable *Executable) {
+	if executable.CrashLoop == nil {
+		executable.CrashLoop = &CrashLoop{}
+	}
+	crashLoop := executable.CrashLoop
+
+	now := time.Now()
+	restarts := make([]time.Time, 0, len(crashLoop.restarts)+1)
+	for _, restart := range crashLoop.restarts {
+		if now.Sub(restart) < CrashLoopWindow {
+			restarts = append(restarts, restart)
+		}
+	}
+	crashLoop.restarts = append(restarts, now)
+
+	if len(crashLoop.restarts) < CrashLoopRestarts {
+		crashLoop.entered = false
+		return
+	}
+	if crashLoop.entered {
+		return
+	}
+
+	crashLoop.entered = true
+	o.publish(EventCrashLoopEntered, executable, "", map[string]string{
+		"restarts":       strconv.Itoa(len(crashLoop.restarts)),
+		"window_seconds": strconv.Itoa(int(CrashLoopWindow.Seconds())),
+	})
+}
@@ -0,0 +1,313 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orchestrator/internal/config"
+	"orchestrator/internal/logger"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+var (
+	EventExecutableCreated = "executable_created"
+	EventExecutableUpdated = "executable_updated"
+	EventExecutableDeleted = "executable_deleted"
+)
+
+var (
+	AppliedImmediately   = "immediately"
+	AppliedOnNextRestart = "next_restart"
+)
+
+type UpdateResult struct {
+	Status  Status `json:"status"`
+	Applied string `json:"applied"`
+}
+
+// CreateExecutable adds an executable, with all of its replicas, and returns the status of its first instance.
+func (o *Orchestrator) CreateExecutable(ctx context.Context, configuration Configuration) (status Status, err error) {
+	executables := Executables{&Executable{Configuration: configuration}}
+	defer func() {
+		o.audit(ctx, ActionCreate, executables, err)
+	}()
+
+	instances, err := instantiate(configuration, 0, configuration.replicaCount(), "")
+	if err != nil {
+		return Status{}, err
+	}
+	executables = instances
+
+	o.mu.Lock()
+	for _, executable := range executables {
+		if o.Executables.byName(executable.Name) != nil {
+			o.mu.Unlock()
+			return Status{}, fmt.Errorf("%w: %s", ErrExecutableExists, executable.Name)
+		}
+		executable.ID = uuid.New()
+	}
+
+	o.Executables = append(append(make(Executables, 0, len(o.Executables)+len(executables)), o.Executables...), executables...)
+	o.syncSchedules()
+	o.syncWatches()
+	source := o.persistEntry("", "", &executables[0].Declared)
+	for _, executable := range executables {
+		executable.Source = source
+	}
+	o.mu.Unlock()
+
+	for _, executable := range executables {
+		o.publish(EventExecutableCreated, executable, "", nil)
+	}
+
+	return o.statusOf(executables[0]), nil
+}
+
+/*
+UpdateExecutable replaces the configuration of an executable, and of the other instances of a replicated executable.
+A stopped executable applies the whole configuration immediately. A running executable applies its name, group,
+auto restart and actions immediately, while the rest of the configuration is applied on its next start. The number
+of replicas is changed with Scale.
+*/
+func (o *Orchestrator) UpdateExecutable(ctx context.Context, processUUID uuid.UUID, configuration Configuration) (result UpdateResult, err error) {
+	executable := o.executable(processUUID)
+	var targets Executables
+	defer func() {
+		o.audit(ctx, ActionUpdate, targets, err)
+	}()
+
+	if executable == nil {
+		return UpdateResult{}, ErrExecutableNotFound
+	}
+
+	instances := o.instances(executable)
+	targets = instances
+
+	if configuration.replicaCount() != executable.Declared.replicaCount() {
+		return UpdateResult{}, fmt.Errorf("%w: replicas cannot be updated, scale the executable instead", ErrInvalidArgument)
+	}
+
+	candidates, err := instantiate(configuration, 0, len(instances), executable.Source)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+
+	o.mu.Lock()
+	if o.Executables.byID(executable.ID) == nil {
+		o.mu.Unlock()
+		return UpdateResult{}, ErrExecutableNotFound
+	}
+	for _, candidate := range candidates {
+		existing := o.Executables.byName(candidate.Name)
+		if existing != nil && existing.Declared.Name != executable.Declared.Name {
+			o.mu.Unlock()
+			return UpdateResult{}, fmt.Errorf("%w: %s", ErrExecutableExists, candidate.Name)
+		}
+	}
+
+	previous := executable.Declared.Name
+	result.Applied = AppliedImmediately
+	for i, instance := range instances {
+		if instance.status().Running && !candidates[i].Configuration.appliesImmediately(instance.Configuration) {
+			result.Applied = AppliedOnNextRestart
+		}
+	}
+
+	for i, instance := range instances {
+		instance.Configuration = candidates[i].Configuration
+		instance.Declared = candidates[i].Declared
+		instance.Paths = candidates[i].Paths
+		o.Logger.Printf(logger.LogInfo+"Executable %s updated, applied %s", instance.Name, result.Applied)
+
+		o.publish(EventExecutableUpdated, instance, "", map[string]string{"applied": result.Applied})
+	}
+	o.syncSchedules()
+	o.syncWatches()
+	o.persistEntry(executable.Source, previous, &executable.Declared)
+	o.mu.Unlock()
+
+	result.Status = o.statusOf(executable)
+
+	return result, nil
+}
+
+// DeleteExecutable removes an executable, and the other instances of a replicated executable. All of them must be stopped.
+func (o *Orchestrator) DeleteExecutable(ctx context.Context, processUUID uuid.UUID) (err error) {
+	executable := o.executable(processUUID)
+	var targets Executables
+	defer func() {
+		o.audit(ctx, ActionDelete, targets, err)
+	}()
+
+	if executable == nil {
+		return ErrExecutableNotFound
+	}
+
+	// The instances are looked up and checked with the write lock held, so that the ones removed are the ones checked.
+	o.mu.Lock()
+	if o.Executables.byID(executable.ID) == nil {
+		o.mu.Unlock()
+		return ErrExecutableNotFound
+	}
+
+	executables := make(Executables, 0, len(o.Executables))
+	for _, existing := range o.Executables {
+		if existing.Declared.Name == executable.Declared.Name {
+			targets = append(targets, existing)
+		} else {
+			executables = append(executables, existing)
+		}
+	}
+	for _, instance := range targets {
+		if instance.status().Running {
+			o.mu.Unlock()
+			return fmt.Errorf("%w: cannot delete executable %s", ErrExecutableRunning, instance.Name)
+		}
+	}
+
+	o.Executables = executables
+	o.syncSchedules()
+	o.syncWatches()
+	o.persistEntry(executable.Source, executable.Declared.Name, nil)
+	o.mu.Unlock()
+
+	for _, instance := range targets {
+		o.publish(EventExecutableDeleted, instance, "", nil)
+	}
+
+	return nil
+}
+
+// appliesImmediately reports whether the changes from the current configuration only affect decisions of the orchestrator.
+func (o Configuration) appliesImmediately(current Configuration) bool {
+	current.Name = o.Name
+	current.Group = o.Group
+	current.AutoRestart = o.AutoRestart
+	// Actions are read when they run.
+	current.Actions = o.Actions
+	// The watch is applied by replacing the watcher.
+	current.Watch = o.Watch
+
+	currentJSON, _ := json.Marshal(current)
+	candidateJSON, _ := json.Marshal(o)
+
+	return string(currentJSON) == string(candidateJSON)
+}
+
+func (o Executables) byName(name string) *Executable {
+	for _, executable := range o {
+		if executable.Name == name {
+			return executable
+		}
+	}
+
+	return nil
+}
+
+/*
+persistEntry writes the change of an executable back to its configuration file when it is enabled, so that the files
+stay the source of truth. The entry named previous is replaced with the declared configuration, removed when it is
+nil, and the configuration is added when previous is empty, to the first file for executables created through the
+API. The other entries, the defaults and the templates of the file are kept as they are parsed, and the other files
+are not written. A YAML file keeps its comments, while a TOML file that has comments is not written, since they would
+be lost. It returns the file of the entry. The write lock is held.
+*/
+func (o *Orchestrator) persistEntry(source string, previous string, declared *Configuration) string {
+	c := config.GetConfig()
+	if !c.PERSIST_EXECUTABLES {
+		return source
+	}
+
+	if source == "" {
+		files, err := configurationFiles(c.EXECUTABLES_JSON_PATH)
+		if err != nil {
+			if info, statErr := os.Stat(c.EXECUTABLES_JSON_PATH); statErr == nil && info.IsDir() || strings.ContainsAny(c.EXECUTABLES_JSON_PATH, "*?[") {
+				o.Logger.Printf(logger.LogErr+"Error persisting executables: %s", err.Error())
+				return ""
+			}
+			files = []string{c.EXECUTABLES_JSON_PATH}
+		}
+		source = files[0]
+	}
+
+	document, ok := o.documents[source]
+	if !ok {
+		var err error
+		document, err = readDocument(source)
+		if err != nil {
+			o.Logger.Printf(logger.LogErr+"Error persisting executables to %s: %s", source, err.Error())
+			return source
+		}
+		if o.documents == nil {
+			o.documents = make(map[string]*configurationDocument)
+		}
+		o.documents[source] = document
+	}
+
+	changed, err := document.set(previous, declared)
+	if err != nil {
+		o.Logger.Printf(logger.LogErr+"Error persisting executables to %s: %s", source, err.Error())
+		return source
+	}
+	if !changed {
+		return source
+	}
+
+	content, err := document.encode()
+	if err == nil {
+		err = writeFileAtomic(source, content)
+	}
+	if err != nil {
+		// The document is parsed from the file again on the next change.
+		delete(o.documents, source)
+		o.Logger.Printf(logger.LogErr+"Error persisting executables to %s: %s", source, err.Error())
+	}
+
+	return source
+}
+
+// writeFileAtomic replaces the file with the content, so that readers never see a partially written file.
+func writeFileAtomic(path string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return errors.New("error creating temporary file: " + err.Error())
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return errors.New("error writing temporary file: " + err.Error())
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.New("error syncing temporary file: " + err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.New("error closing temporary file: " + err.Error())
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return errors.New("error setting file permissions: " + err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.New("error replacing file: " + err.Error())
+	}
+
+	return nil
+}
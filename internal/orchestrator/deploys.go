@@ -0,0 +1,217 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"orchestrator/internal/logger"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ActionDeploy   = "deploy"
+	ActionRollback = "rollback"
+)
+
+var (
+	EventDeployed   = "deployed"
+	EventRolledBack = "rolled_back"
+)
+
+var (
+	DefaultDeployWatch = 30 * time.Second
+)
+
+type DeployRequest struct {
+	// Binary is the content of the new binary.
+	Binary io.Reader
+	// Path is a file on the host of the orchestrator to deploy, when there is no Binary.
+	Path string
+	// Version names the new binary. Without a Binary nor a Path, it references a version kept in the store.
+	Version string
+	// Watch is how long the restarted instances must stay up on the new version, DefaultDeployWatch by default.
+	Watch time.Duration
+}
+
+type DeployResult struct {
+	Executable string `json:"executable"`
+	Version    string `json:"version"`
+	Previous   string `json:"previous"`
+	// Outcome is deployed, or rolled_back when an instance failed on the new version and the previous one was restored.
+	Outcome   string          `json:"outcome"`
+	Instances []RolloutMember `json:"instances"`
+}
+
+// DeployError is a deployment that failed and was rolled back, with its result.
+type DeployError struct {
+	Result DeployResult
+	err    error
+}
+
+func (o *DeployError) Error() string {
+	return fmt.Sprintf("%s: %s %s: %s", ErrDeployFailed.Error(), o.Result.Executable, o.Result.Version, o.err.Error())
+}
+
+func (o *DeployError) Unwrap() error {
+	return ErrDeployFailed
+}
+
+/*
+Deploy installs a new version of the binary of an executable and restarts its running instances onto it: first one
+canary, then the others once it stayed up for the watch period. The previous binaries are kept in the store. When an
+instance fails to start or exits during its watch period, the previous version is installed again, the restarted
+instances are restarted onto it, and a DeployError is returned. Instances that are not running, and tasks, run the
+new version on their next start.
+*/
+func (o *Orchestrator) Deploy(ctx context.Context, processUUID uuid.UUID, request DeployRequest) (result DeployResult, err error) {
+	executable := o.executable(processUUID)
+	var instances Executables
+	defer func() {
+		o.audit(ctx, ActionDeploy, instances, err)
+	}()
+
+	if executable == nil {
+		return DeployResult{}, ErrExecutableNotFound
+	}
+	if request.Watch < 0 {
+		return DeployResult{}, fmt.Errorf("%w: watch must be positive: %s", ErrInvalidArgument, request.Watch)
+	}
+	if request.Watch == 0 {
+		request.Watch = DefaultDeployWatch
+	}
+
+	name, binaryPath := executable.Declared.Name, executable.Paths.BinaryPath
+	if _, running := o.rollouts.LoadOrStore(ActionDeploy+":"+name, struct{}{}); running {
+		return DeployResult{}, fmt.Errorf("%w: %s", ErrDeployInProgress, name)
+	}
+	defer o.rollouts.Delete(ActionDeploy + ":" + name)
+
+	previous, err := o.Releases.snapshot(name, binaryPath)
+	if err != nil {
+		return DeployResult{}, err
+	}
+
+	release, err := o.storeRelease(name, binaryPath, request)
+	if err != nil {
+		return DeployResult{}, err
+	}
+	if release.SHA256 == previous.SHA256 {
+		return DeployResult{}, fmt.Errorf("%w: version %s of %s is already deployed", ErrInvalidArgument, previous.Version, name)
+	}
+
+	instances = o.instances(executable)
+	result = DeployResult{Executable: name, Version: release.Version, Previous: previous.Version, Outcome: ReleaseOutcomeDeployed}
+	if err := o.installRelease(name, release.Version, binaryPath, instances); err != nil {
+		return DeployResult{}, err
+	}
+
+	// The canary is the first running instance, the others follow together once it stayed up.
+	var restarted Executables
+	var batches []Executables
+	members := map[*Executable]*RolloutMember{}
+	for _, instance := range instances {
+		result.Instances = append(result.Instances, RolloutMember{ID: instance.ID.String(), Name: instance.Name, State: RolloutMemberSkipped})
+	}
+	for i, instance := range instances {
+		if instance.isTask() || !instance.Process.running() {
+			continue
+		}
+		if len(batches) < 2 {
+			batches = append(batches, nil)
+		}
+		batches[len(batches)-1] = append(batches[len(batches)-1], instance)
+		result.Instances[i].State = RolloutMemberPending
+		result.Instances[i].Batch = len(batches)
+		members[instance] = &result.Instances[i]
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Deploying version %s of %s over %s", release.Version, name, previous.Version)
+	var failure error
+	for _, batch := range batches {
+		restarted = append(restarted, batch...)
+		if failure = o.restartBatch(ctx, batch, members, request.Watch); failure != nil {
+			break
+		}
+	}
+
+	if failure == nil {
+		release.Outcome, release.DeployedAt = ReleaseOutcomeDeployed, time.Now()
+		if err := o.Releases.record(name, release); err != nil {
+			return result, err
+		}
+
+		o.Logger.Printf(logger.LogInfo+"Deployed version %s of %s", release.Version, name)
+		o.publish(EventDeployed, executable, "", map[string]string{"version": release.Version, "previous": previous.Version})
+		return result, nil
+	}
+
+	result.Outcome = ReleaseOutcomeRolledBack
+	rollbackErr := o.rollback(ctx, name, previous.Version, binaryPath, instances, restarted)
+	release.Outcome, release.DeployedAt, release.Error = ReleaseOutcomeRolledBack, time.Now(), failure.Error()
+	if err := o.Releases.record(name, release); err != nil {
+		rollbackErr = errors.Join(rollbackErr, err)
+	}
+
+	o.Logger.Printf(logger.LogErr+"Deployment of version %s of %s rolled back to %s: %s", release.Version, name, previous.Version, failure.Error())
+	o.publish(EventRolledBack, executable, failure.Error(), map[string]string{"version": release.Version, "previous": previous.Version})
+
+	return result, &DeployError{Result: result, err: errors.Join(failure, rollbackErr)}
+}
+
+// storeRelease adds the binary of the request to the store, or returns the stored version it references.
+func (o *Orchestrator) storeRelease(name string, binaryPath string, request DeployRequest) (Release, error) {
+	switch {
+	case request.Binary != nil:
+		return o.Releases.add(name, request.Version, binaryPath, request.Binary)
+	case request.Path != "":
+		file, err := os.Open(request.Path)
+		if err != nil {
+			return Release{}, fmt.Errorf("%w: cannot open binary: %s", ErrInvalidArgument, err.Error())
+		}
+		defer file.Close()
+
+		return o.Releases.add(name, request.Version, binaryPath, file)
+	case request.Version != "":
+		return o.Releases.stored(name, request.Version, binaryPath)
+	default:
+		return Release{}, fmt.Errorf("%w: a binary, a path or a version is required", ErrInvalidArgument)
+	}
+}
+
+// installRelease installs a version onto the binary of every instance, which is shared unless it depends on the index.
+func (o *Orchestrator) installRelease(name string, version string, binaryPath string, instances Executables) error {
+	installed := map[string]bool{}
+	for _, instance := range instances {
+		if installed[instance.Paths.BinaryPath] {
+			continue
+		}
+		if err := o.Releases.install(name, version, binaryPath, instance.Paths.BinaryPath); err != nil {
+			return err
+		}
+		installed[instance.Paths.BinaryPath] = true
+	}
+
+	return nil
+}
+
+// rollback installs the previous version again and restarts the instances that were restarted onto the failed one.
+func (o *Orchestrator) rollback(ctx context.Context, name string, version string, binaryPath string, instances Executables, restarted Executables) (err error) {
+	defer func() {
+		o.audit(ctx, ActionRollback, restarted, err)
+	}()
+
+	if err := o.installRelease(name, version, binaryPath, instances); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, instance := range restarted {
+		errs = append(errs, o.restartExecutable(instance))
+	}
+
+	return errors.Join(errs...)
+}
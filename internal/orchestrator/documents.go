@@ -0,0 +1,516 @@
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+configurationDocument is a configuration file as it is written: its defaults, its templates and the raw entries of
+its executables. Persisting an executable changes its entry only, so that the entries keep extending their templates
+and the fields they leave to them. A YAML file is written back from its parsed nodes, which keeps its comments.
+*/
+type configurationDocument struct {
+	path      string
+	defaults  *rawExecutable
+	templates []rawExecutable
+	resolver  *templateResolver
+	entries   []documentEntry
+	// node is the parsed YAML file, and sequences its sequences of executables.
+	node      *yaml.Node
+	sequences []*yaml.Node
+}
+
+// documentEntry is an executable of a document, with the configuration it declares once merged with its templates.
+type documentEntry struct {
+	declared Configuration
+	// value is the entry as it is encoded, with only the fields that are written.
+	value any
+	// node is the entry in the sequence of a YAML file, and changed tells whether value differs from it.
+	node     *yaml.Node
+	sequence *yaml.Node
+	changed  bool
+}
+
+// readDocument parses a configuration file. A file that does not exist yet is an empty document.
+func readDocument(path string) (*configurationDocument, error) {
+	document := &configurationDocument{path: path}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		document.resolver, _ = newTemplateResolver(rawDocument{})
+		return document, nil
+	}
+	if err != nil {
+		return nil, errors.New("error opening executables file: " + err.Error())
+	}
+
+	report := &ValidationReport{Valid: true, Issues: make([]ValidationIssue, 0)}
+	parsed, issues := parseConfiguration(content, ConfigurationFormat(path))
+	resolver, resolverIssues := newTemplateResolver(parsed)
+	issues = append(issues, resolverIssues...)
+
+	document.defaults, document.templates, document.resolver = parsed.Defaults, parsed.Templates, resolver
+	for _, raw := range parsed.Executables {
+		resolved, resolveIssues := resolver.resolve(raw)
+		declared, decodeIssues := decodeConfiguration(raw.Index, resolved)
+		value, _ := layerValue(raw)
+		issues = append(append(issues, resolveIssues...), decodeIssues...)
+
+		document.entries = append(document.entries, documentEntry{declared: declared, value: value})
+	}
+
+	// A file that was changed since it was loaded and does not decode anymore is not overwritten.
+	for _, issue := range issues {
+		issue.Source = path
+		report.add(issue)
+	}
+	if !report.Valid {
+		report.sort()
+		return nil, report
+	}
+
+	switch ConfigurationFormat(path) {
+	case FormatYAML:
+		document.bindYAML(content)
+	case FormatTOML:
+		// The TOML encoder does not write comments, a file that has some is left to be edited by hand.
+		if tomlComments(content) {
+			return nil, errors.New("the file has comments, which would be lost: changes are not persisted to it")
+		}
+	}
+
+	return document, nil
+}
+
+// bindYAML keeps the nodes of a YAML file, and the node of each entry in its sequence of executables.
+func (o *configurationDocument) bindYAML(content []byte) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil || len(node.Content) == 0 {
+		return
+	}
+
+	root := resolveYAMLAlias(node.Content[0])
+	switch root.Kind {
+	case yaml.SequenceNode:
+		o.sequences = append(o.sequences, root)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "executables" {
+				o.sequences = append(o.sequences, resolveYAMLAlias(root.Content[i+1]))
+			}
+		}
+	default:
+		return
+	}
+
+	index := 0
+	for _, sequence := range o.sequences {
+		for _, item := range sequence.Content {
+			if index < len(o.entries) {
+				o.entries[index].node, o.entries[index].sequence = item, sequence
+			}
+			index++
+		}
+	}
+	o.node = &node
+}
+
+// tomlComments tells whether a TOML file has comments, before its expressions, after them or in its arrays.
+func tomlComments(content []byte) bool {
+	var hasComment func(node *unstable.Node) bool
+	hasComment = func(node *unstable.Node) bool {
+		for ; node != nil && node.Valid(); node = node.Next() {
+			if node.Kind == unstable.Comment {
+				return true
+			}
+			children := node.Children()
+			for children.Next() {
+				if hasComment(children.Node()) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	parser := unstable.Parser{KeepComments: true}
+	parser.Reset(content)
+	for parser.NextExpression() {
+		if hasComment(parser.Expression()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+set replaces the entry named previous with the declared configuration, removes it when the configuration is nil, and
+adds the configuration when there is no such entry. It tells whether the document changed.
+*/
+func (o *configurationDocument) set(previous string, declared *Configuration) (bool, error) {
+	index := -1
+	for i, entry := range o.entries {
+		if previous != "" && entry.declared.Name == previous {
+			index = i
+			break
+		}
+	}
+
+	if declared == nil {
+		if index < 0 {
+			return false, nil
+		}
+		o.entries = append(o.entries[:index:index], o.entries[index+1:]...)
+		return true, nil
+	}
+
+	configuration := declared.clone()
+	configuration.Source = ""
+	var entry documentEntry
+	if index >= 0 {
+		if reflect.DeepEqual(o.entries[index].declared, configuration) {
+			return false, nil
+		}
+		entry = o.entries[index]
+	}
+
+	value, err := o.entryValue(entry, configuration)
+	if err != nil {
+		return false, errors.New("executable " + configuration.Name + ": " + err.Error())
+	}
+	entry.declared, entry.value, entry.changed = configuration, value, true
+
+	if index < 0 {
+		o.entries = append(o.entries, entry)
+	} else {
+		o.entries[index] = entry
+	}
+
+	return true, nil
+}
+
+/*
+entryValue writes the declared configuration as a layer over the defaults and the template the entry extends. The
+fields that did not change are kept as they are written, the others are written when they differ from what the
+entry inherits: arrays with the values that follow the inherited ones, and objects with the keys that differ. The
+configuration of an executable is read with its variables replaced, so a value is also unchanged or inherited when
+it is the written one once interpolated: "out-web" leaves "out-${name}" in place.
+*/
+func (o *configurationDocument) entryValue(entry documentEntry, declared Configuration) (any, error) {
+	var fields []reflect.StructField
+	var values []reflect.Value
+	keep := func(key string) {
+		if field, value, ok := layerField(entry.value, key); ok {
+			fields = append(fields, reflect.StructField{Name: field.Name, Type: field.Type, Tag: field.Tag})
+			values = append(values, value)
+		}
+	}
+
+	inherited := rawExecutable{Index: -1}
+	if _, extends, ok := layerField(entry.value, ExtendsField); ok {
+		inherited.Fields = append(inherited.Fields, rawField{Key: ExtendsField, decode: func(target any) error {
+			return decodeGeneric(extends.Interface(), target)
+		}})
+		keep(ExtendsField)
+	}
+	resolved, _ := o.resolver.resolve(inherited)
+	base, _ := decodeConfiguration(-1, resolved)
+
+	original := entry.declared
+	if entry.value == nil {
+		original = base
+	}
+	interpolatedOriginal, interpolatedBase := interpolated(original, declared), interpolated(base, declared)
+
+	configurationType := reflect.TypeOf(Configuration{})
+	for i := 0; i < configurationType.NumField(); i++ {
+		field := configurationType.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+
+		current := reflect.ValueOf(declared).Field(i)
+		if sameValue(current, reflect.ValueOf(original).Field(i), reflect.ValueOf(interpolatedOriginal).Field(i)) {
+			keep(key)
+			continue
+		}
+
+		value, ok, err := overrideValue(key, reflect.ValueOf(base).Field(i), reflect.ValueOf(interpolatedBase).Field(i), current)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		// An override is written even when it is empty, since leaving it out would inherit the value.
+		tag := reflect.StructTag(strings.ReplaceAll(string(field.Tag), ",omitempty", ""))
+		fields = append(fields, reflect.StructField{Name: field.Name, Type: field.Type, Tag: tag})
+		values = append(values, value)
+	}
+
+	layer := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, value := range values {
+		layer.Field(i).Set(value)
+	}
+
+	return layer.Interface(), nil
+}
+
+// layerField returns the field of an encoded layer with the key, and its value.
+func layerField(layer any, key string) (reflect.StructField, reflect.Value, bool) {
+	if layer == nil {
+		return reflect.StructField{}, reflect.Value{}, false
+	}
+
+	value := reflect.ValueOf(layer)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name == key {
+			return field, value.Field(i), true
+		}
+	}
+
+	return reflect.StructField{}, reflect.Value{}, false
+}
+
+/*
+overrideValue returns what a field must be set to over the inherited value to resolve to the current one, if anything.
+A value is inherited when it is the inherited one as written or as interpolated.
+*/
+func overrideValue(key string, inherited reflect.Value, interpolated reflect.Value, current reflect.Value) (reflect.Value, bool, error) {
+	switch current.Kind() {
+	case reflect.Slice:
+		if current.Len() < inherited.Len() {
+			return reflect.Value{}, false, errors.New(key + " cannot leave out the values inherited from the defaults or templates")
+		}
+		for i := 0; i < inherited.Len(); i++ {
+			if !sameValue(current.Index(i), inherited.Index(i), interpolated.Index(i)) {
+				return reflect.Value{}, false, errors.New(key + " cannot leave out the values inherited from the defaults or templates")
+			}
+		}
+		appended := current.Slice(inherited.Len(), current.Len())
+
+		return appended, appended.Len() > 0, nil
+	case reflect.Map:
+		override := reflect.MakeMap(current.Type())
+		for _, name := range inherited.MapKeys() {
+			if !current.MapIndex(name).IsValid() {
+				return reflect.Value{}, false, errors.New(key + " cannot leave out the key " + name.String() + " inherited from the defaults or templates")
+			}
+		}
+		iterator := current.MapRange()
+		for iterator.Next() {
+			value := inherited.MapIndex(iterator.Key())
+			if !value.IsValid() || !sameValue(iterator.Value(), value, interpolated.MapIndex(iterator.Key())) {
+				override.SetMapIndex(iterator.Key(), iterator.Value())
+			}
+		}
+
+		return override, override.Len() > 0, nil
+	default:
+		return current, !sameValue(current, inherited, interpolated), nil
+	}
+}
+
+// sameValue tells whether the current value is the written one, or the written one once interpolated.
+func sameValue(current reflect.Value, written reflect.Value, interpolated reflect.Value) bool {
+	return reflect.DeepEqual(current.Interface(), written.Interface()) || reflect.DeepEqual(current.Interface(), interpolated.Interface())
+}
+
+// interpolated returns a configuration with its variables replaced as they are for the first instance of the declared one.
+func interpolated(configuration Configuration, declared Configuration) Configuration {
+	name, group := configuration.Name, configuration.Group
+	configuration.Name, configuration.Group = declared.Name, declared.Group
+
+	instance := newInstance(configuration, 0)
+	_ = instance.interpolate()
+	instance.Configuration.Name, instance.Configuration.Group = name, group
+
+	return instance.Configuration
+}
+
+// encode encodes the document in the format of its file, with its defaults and templates.
+func (o *configurationDocument) encode() ([]byte, error) {
+	if o.node != nil {
+		return o.encodeYAML()
+	}
+
+	encoded := encodedDocument{Executables: make([]any, 0, len(o.entries))}
+	for _, entry := range o.entries {
+		encoded.Executables = append(encoded.Executables, entry.value)
+	}
+	if o.defaults != nil {
+		encoded.Defaults, _ = layerValue(*o.defaults)
+	}
+	for _, template := range o.templates {
+		if encoded.Templates == nil {
+			encoded.Templates = make(map[string]any)
+		}
+		encoded.Templates[template.Template], _ = layerValue(template)
+	}
+
+	return encodeDocument(encoded, ConfigurationFormat(o.path))
+}
+
+/*
+encodeYAML writes the entries into the parsed YAML file, so that its comments, its defaults and templates and the
+entries that did not change are written as they were read. New entries are added to the last sequence of executables.
+*/
+func (o *configurationDocument) encodeYAML() ([]byte, error) {
+	if len(o.sequences) == 0 {
+		root := o.node.Content[0]
+		sequence := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "executables"}, sequence)
+		o.sequences = append(o.sequences, sequence)
+	}
+
+	for _, entry := range o.entries {
+		if entry.node != nil && entry.changed {
+			detachAnchors(o.node, entry.node)
+		}
+	}
+	for _, sequence := range o.sequences {
+		sequence.Content = nil
+	}
+	for i := range o.entries {
+		entry := &o.entries[i]
+		if entry.node == nil || entry.changed {
+			node, err := spliceYAML(entry.node, entry.value)
+			if err != nil {
+				return nil, errors.New("error encoding configuration as YAML: " + err.Error())
+			}
+			entry.node, entry.changed = node, false
+		}
+		if entry.sequence == nil {
+			entry.sequence = o.sequences[len(o.sequences)-1]
+		}
+		if len(entry.sequence.Content) == 0 {
+			entry.sequence.Style &^= yaml.FlowStyle
+		}
+		entry.sequence.Content = append(entry.sequence.Content, entry.node)
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(o.node); err != nil {
+		return nil, errors.New("error encoding configuration as YAML: " + err.Error())
+	}
+	encoder.Close()
+
+	return buffer.Bytes(), nil
+}
+
+/*
+spliceYAML writes the value of an entry into its mapping. The keys whose values did not change are kept with their
+comments, the changed values are replaced and the new keys are added after the others. The keys merged with "<<"
+are written to the entry instead, since the value has all of them.
+*/
+func spliceYAML(mapping *yaml.Node, value any) (*yaml.Node, error) {
+	encoded := &yaml.Node{}
+	if err := encoded.Encode(value); err != nil {
+		return nil, err
+	}
+	if mapping == nil {
+		return encoded, nil
+	}
+	if mapping.Kind != yaml.MappingNode {
+		encoded.HeadComment, encoded.LineComment, encoded.FootComment = mapping.HeadComment, mapping.LineComment, mapping.FootComment
+		return encoded, nil
+	}
+
+	values := make(map[string]*yaml.Node, len(encoded.Content)/2)
+	for i := 0; i+1 < len(encoded.Content); i += 2 {
+		values[encoded.Content[i].Value] = encoded.Content[i+1]
+	}
+
+	content := make([]*yaml.Node, 0, len(encoded.Content))
+	written := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, previous := mapping.Content[i], mapping.Content[i+1]
+		current, ok := values[key.Value]
+		if key.Tag == "!!merge" || !ok {
+			continue
+		}
+
+		var previousValue, currentValue any
+		if previous.Decode(&previousValue) != nil || current.Decode(&currentValue) != nil || !reflect.DeepEqual(previousValue, currentValue) {
+			current.HeadComment, current.LineComment, current.FootComment = previous.HeadComment, previous.LineComment, previous.FootComment
+			previous = current
+		}
+		content = append(content, key, previous)
+		written[key.Value] = true
+	}
+	for i := 0; i+1 < len(encoded.Content); i += 2 {
+		if !written[encoded.Content[i].Value] {
+			content = append(content, encoded.Content[i], encoded.Content[i+1])
+		}
+	}
+	mapping.Content = content
+
+	return mapping, nil
+}
+
+/*
+detachAnchors replaces the aliases to the anchors of an entry that changes with copies of what they refer to, so that
+the other entries and the entry itself keep their values, and removes the anchors.
+*/
+func detachAnchors(root *yaml.Node, entry *yaml.Node) {
+	anchored := make(map[*yaml.Node]bool)
+	var collect func(node *yaml.Node)
+	collect = func(node *yaml.Node) {
+		if node.Anchor != "" {
+			anchored[node] = true
+		}
+		if node.Kind != yaml.AliasNode {
+			for _, child := range node.Content {
+				collect(child)
+			}
+		}
+	}
+	collect(entry)
+	if len(anchored) == 0 {
+		return
+	}
+
+	var copyNode func(node *yaml.Node) *yaml.Node
+	copyNode = func(node *yaml.Node) *yaml.Node {
+		if node.Kind == yaml.AliasNode && anchored[node.Alias] {
+			return copyNode(node.Alias)
+		}
+		copied := *node
+		copied.Anchor = ""
+		if node.Kind != yaml.AliasNode {
+			copied.Content = make([]*yaml.Node, len(node.Content))
+			for i, child := range node.Content {
+				copied.Content[i] = copyNode(child)
+			}
+		}
+		return &copied
+	}
+
+	var replace func(node *yaml.Node)
+	replace = func(node *yaml.Node) {
+		for i, child := range node.Content {
+			if child.Kind == yaml.AliasNode && anchored[child.Alias] {
+				node.Content[i] = copyNode(child.Alias)
+			} else if child.Kind != yaml.AliasNode {
+				replace(child)
+			}
+		}
+	}
+	replace(root)
+
+	for node := range anchored {
+		node.Anchor = ""
+	}
+}
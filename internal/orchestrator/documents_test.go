@@ -0,0 +1,241 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeDocument writes a configuration file and parses it as a document.
+func writeDocument(t *testing.T, name string, content string) *configurationDocument {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	document, err := readDocument(path)
+	if err != nil {
+		t.Fatalf("readDocument: %v", err)
+	}
+
+	return document
+}
+
+// setEntry changes the entry named previous with change, and returns the encoded document.
+func setEntry(t *testing.T, document *configurationDocument, previous string, change func(*Configuration)) string {
+	t.Helper()
+
+	var declared Configuration
+	for _, entry := range document.entries {
+		if entry.declared.Name == previous {
+			declared = entry.declared.clone()
+		}
+	}
+	change(&declared)
+
+	if _, err := document.set(previous, &declared); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	encoded, err := document.encode()
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	return string(encoded)
+}
+
+// reread parses the encoded document again, and returns the declared configurations by name.
+func reread(t *testing.T, document *configurationDocument, encoded string) map[string]Configuration {
+	t.Helper()
+
+	if err := os.WriteFile(document.path, []byte(encoded), 0644); err != nil {
+		t.Fatal(err)
+	}
+	written, err := readDocument(document.path)
+	if err != nil {
+		t.Fatalf("readDocument of\n%s\n: %v", encoded, err)
+	}
+
+	declared := make(map[string]Configuration)
+	for _, entry := range written.entries {
+		declared[entry.declared.Name] = entry.declared
+	}
+
+	return declared
+}
+
+const yamlDocument = `# The services of the team.
+defaults:
+  log_file_name: out-${name} # one log per executable
+  log_dir: /var/log
+templates:
+  sleeper:
+    binary_path: /bin/sleep
+    arguments: ["10"]
+    env:
+      LANG: C
+executables:
+  # alpha is the first service.
+  - name: alpha # inline
+    extends: sleeper
+    working_dir: /srv/alpha
+    group: "1"
+  - name: beta
+    extends: sleeper
+    working_dir: /srv/beta
+    group: "2"
+`
+
+func TestDocumentKeepsInterpolatedValuesInherited(t *testing.T) {
+	document := writeDocument(t, "executables.yaml", yamlDocument)
+
+	// The status shows the interpolated value, which is sent back as it is.
+	encoded := setEntry(t, document, "alpha", func(configuration *Configuration) {
+		configuration.LogFileName = "out-alpha"
+		configuration.Group = "3"
+	})
+
+	if strings.Contains(encoded, "out-alpha") {
+		t.Errorf("the interpolated log file name is written:\n%s", encoded)
+	}
+	declared := reread(t, document, encoded)
+	if declared["alpha"].LogFileName != "out-${name}" || declared["alpha"].Group != "3" {
+		t.Errorf("alpha = %+v, want the log file name of the defaults and group 3", declared["alpha"])
+	}
+}
+
+func TestDocumentKeepsYAMLComments(t *testing.T) {
+	document := writeDocument(t, "executables.yaml", yamlDocument)
+
+	encoded := setEntry(t, document, "alpha", func(configuration *Configuration) {
+		configuration.WorkingDir = "/srv/alpha2"
+	})
+
+	for _, comment := range []string{"# The services of the team.", "# one log per executable", "# alpha is the first service.", "# inline"} {
+		if !strings.Contains(encoded, comment) {
+			t.Errorf("comment %q is lost:\n%s", comment, encoded)
+		}
+	}
+	if !strings.Contains(encoded, "log_file_name: out-${name}") || !strings.Contains(encoded, "sleeper:") {
+		t.Errorf("the defaults or templates changed:\n%s", encoded)
+	}
+	declared := reread(t, document, encoded)
+	if declared["alpha"].WorkingDir != "/srv/alpha2" {
+		t.Errorf("alpha working_dir = %s, want /srv/alpha2", declared["alpha"].WorkingDir)
+	}
+	if !reflect.DeepEqual(declared["beta"], document.entries[1].declared) {
+		t.Errorf("beta = %+v, want it unchanged", declared["beta"])
+	}
+}
+
+func TestDocumentWritesOverridesOverTemplates(t *testing.T) {
+	document := writeDocument(t, "executables.yaml", yamlDocument)
+
+	encoded := setEntry(t, document, "beta", func(configuration *Configuration) {
+		configuration.Arguments = append(configuration.Arguments, "20")
+		configuration.Env["TZ"] = "UTC"
+	})
+
+	declared := reread(t, document, encoded)
+	if want := []string{"10", "20"}; !reflect.DeepEqual(declared["beta"].Arguments, want) {
+		t.Errorf("beta arguments = %v, want %v", declared["beta"].Arguments, want)
+	}
+	if want := map[string]string{"LANG": "C", "TZ": "UTC"}; !reflect.DeepEqual(declared["beta"].Env, want) {
+		t.Errorf("beta env = %v, want %v", declared["beta"].Env, want)
+	}
+	if strings.Count(encoded, "LANG: C") != 1 {
+		t.Errorf("the inherited env is written to the entry:\n%s", encoded)
+	}
+}
+
+func TestDocumentRejectsDroppingInheritedValues(t *testing.T) {
+	document := writeDocument(t, "executables.yaml", yamlDocument)
+
+	declared := document.entries[0].declared.clone()
+	declared.Arguments = []string{"20"}
+
+	if _, err := document.set("alpha", &declared); err == nil {
+		t.Error("set dropped the inherited arguments, want an error")
+	}
+}
+
+func TestDocumentAddsAndRemovesEntries(t *testing.T) {
+	document := writeDocument(t, "executables.json", `{
+    "defaults": {"log_dir": "/var/log"},
+    "executables": [{"name": "alpha", "binary_path": "/bin/sleep", "working_dir": "/srv", "log_file_name": "out", "error_file_name": "err", "group": "1"}]
+}`)
+
+	gamma := Configuration{Name: "gamma", BinaryPath: "/bin/true", WorkingDir: "/srv", LogDir: "/var/log", LogFileName: "out", ErrorFileName: "err", Group: "1"}
+	if changed, err := document.set("", &gamma); !changed || err != nil {
+		t.Fatalf("set gamma = %v, %v", changed, err)
+	}
+	if changed, err := document.set("alpha", nil); !changed || err != nil {
+		t.Fatalf("remove alpha = %v, %v", changed, err)
+	}
+	encoded, err := document.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	declared := reread(t, document, string(encoded))
+	if _, ok := declared["alpha"]; ok || len(declared) != 1 {
+		t.Errorf("got %v, want gamma only", declared)
+	}
+	if !reflect.DeepEqual(declared["gamma"], gamma) {
+		t.Errorf("gamma = %+v, want %+v", declared["gamma"], gamma)
+	}
+	if !strings.Contains(string(encoded), `"defaults"`) || strings.Count(string(encoded), "/var/log") != 1 {
+		t.Errorf("the defaults are not kept, or written to the entry:\n%s", encoded)
+	}
+}
+
+func TestDocumentKeepsAliasedValues(t *testing.T) {
+	document := writeDocument(t, "executables.yaml", `- &alpha
+  name: alpha
+  binary_path: /bin/sleep
+  working_dir: /srv
+  log_dir: /var/log
+  log_file_name: out
+  error_file_name: err
+  group: "1"
+- <<: *alpha
+  name: beta
+`)
+	beta := document.entries[1].declared
+
+	encoded := setEntry(t, document, "alpha", func(configuration *Configuration) {
+		configuration.WorkingDir = "/srv/alpha"
+	})
+
+	declared := reread(t, document, encoded)
+	if declared["alpha"].WorkingDir != "/srv/alpha" {
+		t.Errorf("alpha working_dir = %s, want /srv/alpha", declared["alpha"].WorkingDir)
+	}
+	if !reflect.DeepEqual(declared["beta"], beta) {
+		t.Errorf("beta = %+v, want %+v", declared["beta"], beta)
+	}
+}
+
+func TestDocumentRefusesCommentedTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "executables.toml")
+	content := "# The services.\n[[executables]]\nname = \"alpha\"\nbinary_path = \"/bin/sleep\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readDocument(path); err == nil || !strings.Contains(err.Error(), "comments") {
+		t.Errorf("readDocument = %v, want an error about the comments", err)
+	}
+}
+
+func TestDocumentUnchangedEntry(t *testing.T) {
+	document := writeDocument(t, "executables.yaml", yamlDocument)
+
+	declared := document.entries[0].declared.clone()
+	if changed, err := document.set("alpha", &declared); changed || err != nil {
+		t.Errorf("set = %v, %v, want the document unchanged", changed, err)
+	}
+}
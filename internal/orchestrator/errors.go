@@ -0,0 +1,30 @@
+package orchestrator
+
+import "errors"
+
+var (
+	ErrExecutablesAlreadySet  = errors.New("executables already set")
+	ErrExecutablesNotSet      = errors.New("no executables set")
+	ErrExecutableNotFound     = errors.New("executable not found")
+	ErrExecutableExists       = errors.New("executable already exists")
+	ErrGroupNotFound          = errors.New("no executables found in group")
+	ErrExecutableRunning      = errors.New("executable is running")
+	ErrExecutableNotRunning   = errors.New("executable is not running")
+	ErrExecutablePaused       = errors.New("executable is paused")
+	ErrExecutableNotPaused    = errors.New("executable is not paused")
+	ErrInvalidConfiguration   = errors.New("invalid configuration")
+	ErrInvalidArgument        = errors.New("invalid argument")
+	ErrLogsNotFound           = errors.New("no logs found")
+	ErrExecutableStopTimedOut = errors.New("executable did not stop in time")
+	ErrHookFailed             = errors.New("hook failed")
+	ErrTerminalAttached       = errors.New("terminal already has an interactive attachment")
+	ErrActionNotFound         = errors.New("action not found")
+	ErrActionFailed           = errors.New("action failed")
+	ErrRolloutFailed          = errors.New("rolling restart aborted")
+	ErrRolloutInProgress      = errors.New("rolling restart already in progress")
+	ErrVersionNotFound        = errors.New("version not found")
+	ErrDeployFailed           = errors.New("deployment rolled back")
+	ErrDeployInProgress       = errors.New("deployment already in progress")
+	ErrIntegrity              = errors.New("binary integrity check failed")
+	ErrBuildFailed            = errors.New("build failed")
+)
@@ -0,0 +1,57 @@
+package orchestrator
+
+import (
+	"errors"
+	"orchestrator/internal/events"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+var (
+	EventStarted          = "started"
+	EventStartFailed      = "start_failed"
+	EventStopRequested    = "stop_requested"
+	EventExited           = "exited"
+	EventRestartScheduled = "restart_scheduled"
+	EventConfigSet        = "config_set"
+	EventConfigUnset      = "config_unset"
+	EventConfigReloaded   = "config_reloaded"
+)
+
+func (o *Orchestrator) publish(eventType string, executable *Executable, message string, attributes map[string]string) {
+	event := events.Event{
+		Type:       eventType,
+		Message:    message,
+		Attributes: attributes,
+	}
+
+	if executable != nil {
+		event.ExecutableID = executable.ID.String()
+		event.ExecutableName = executable.Name
+		event.Group = executable.Group
+	}
+
+	o.Events.Publish(event)
+}
+
+// exitAttributes describes how a process exited: its exit code, or the signal that terminated it.
+func exitAttributes(err error) map[string]string {
+	attributes := map[string]string{"exit_code": "0"}
+	if err == nil {
+		return attributes
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		attributes["error"] = err.Error()
+		return attributes
+	}
+
+	attributes["exit_code"] = strconv.Itoa(exitErr.ExitCode())
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		attributes["signal"] = status.Signal().String()
+	}
+
+	return attributes
+}
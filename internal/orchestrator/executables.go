@@ -8,6 +8,9 @@ import (
 	"orchestrator/internal/logger"
 	"os"
 	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -24,18 +27,55 @@ type Executables []*Executable
 type Executable struct {
 	Configuration
 	Process
+	Paths     Paths
+	Declared  Configuration
+	Index     int
+	Job       *Job
+	Task      *Task
+	CrashLoop *CrashLoop
+	// integrityErr is the last failed verification of the binary, cleared once it passes.
+	integrityErr error
+	// buildErr is the last failed build, cleared once a build succeeds or is up to date.
+	buildErr error
+	// restarting is set while the executable is restarted, so that its exit is not auto-restarted.
+	restarting atomic.Bool
 }
 
 type Configuration struct {
-	Name          string   `json:"name"`
-	BinaryPath    string   `json:"binary_path"`
-	WorkingDir    string   `json:"working_dir"`
-	LogDir        string   `json:"log_dir"`
-	Arguments     []string `json:"arguments"`
-	LogFileName   string   `json:"log_file_name"`
-	ErrorFileName string   `json:"error_file_name"`
-	AutoRestart   bool     `json:"auto_restart"`
-	Group         string   `json:"group"`
+	Name              string            `json:"name" yaml:"name" toml:"name"`
+	BinaryPath        string            `json:"binary_path" yaml:"binary_path" toml:"binary_path"`
+	WorkingDir        string            `json:"working_dir" yaml:"working_dir" toml:"working_dir"`
+	LogDir            string            `json:"log_dir" yaml:"log_dir" toml:"log_dir"`
+	Arguments         []string          `json:"arguments" yaml:"arguments" toml:"arguments"`
+	Env               map[string]string `json:"env,omitempty" yaml:"env,omitempty" toml:"env,omitempty"`
+	LogFileName       string            `json:"log_file_name" yaml:"log_file_name" toml:"log_file_name"`
+	ErrorFileName     string            `json:"error_file_name" yaml:"error_file_name" toml:"error_file_name"`
+	AutoRestart       bool              `json:"auto_restart" yaml:"auto_restart" toml:"auto_restart"`
+	Group             string            `json:"group" yaml:"group" toml:"group"`
+	Replicas          int               `json:"replicas,omitempty" yaml:"replicas,omitempty" toml:"replicas,omitempty"`
+	Schedule          string            `json:"schedule,omitempty" yaml:"schedule,omitempty" toml:"schedule,omitempty"`
+	Timezone          string            `json:"timezone,omitempty" yaml:"timezone,omitempty" toml:"timezone,omitempty"`
+	ConcurrencyPolicy string            `json:"concurrency_policy,omitempty" yaml:"concurrency_policy,omitempty" toml:"concurrency_policy,omitempty"`
+	Type              string            `json:"type,omitempty" yaml:"type,omitempty" toml:"type,omitempty"`
+	SuccessCodes      []int             `json:"success_codes,omitempty" yaml:"success_codes,omitempty" toml:"success_codes,omitempty"`
+	MaxRuntime        string            `json:"max_runtime,omitempty" yaml:"max_runtime,omitempty" toml:"max_runtime,omitempty"`
+	Retries           int               `json:"retries,omitempty" yaml:"retries,omitempty" toml:"retries,omitempty"`
+	Hooks             *Hooks            `json:"hooks,omitempty" yaml:"hooks,omitempty" toml:"hooks,omitempty"`
+	// StartingDeadline is how late a run missed while the orchestrator was down may still start, such as 1h. Missed runs are not started by default.
+	StartingDeadline string `json:"starting_deadline,omitempty" yaml:"starting_deadline,omitempty" toml:"starting_deadline,omitempty"`
+	// Actions are the named control actions of the executable, e.g. reload: {signal: HUP}.
+	Actions map[string]Action `json:"actions,omitempty" yaml:"actions,omitempty" toml:"actions,omitempty"`
+	// Build produces the binary before every start, unless its inputs did not change.
+	Build *Build `json:"build,omitempty" yaml:"build,omitempty" toml:"build,omitempty"`
+	// Watch restarts the executable when its files change, optionally after a build.
+	Watch *Watch `json:"watch,omitempty" yaml:"watch,omitempty" toml:"watch,omitempty"`
+	// TTY starts the executable with a pseudo-terminal that can be attached to. Its output, stderr included, goes to the out log file.
+	TTY bool `json:"tty,omitempty" yaml:"tty,omitempty" toml:"tty,omitempty"`
+	// SHA256, the entry of the binary in ChecksumsFile and the ed25519 signature in SignatureFile are verified before every start.
+	SHA256        string `json:"sha256,omitempty" yaml:"sha256,omitempty" toml:"sha256,omitempty"`
+	ChecksumsFile string `json:"checksums_file,omitempty" yaml:"checksums_file,omitempty" toml:"checksums_file,omitempty"`
+	SignatureFile string `json:"signature_file,omitempty" yaml:"signature_file,omitempty" toml:"signature_file,omitempty"`
+	Source        string `json:"-" yaml:"-" toml:"-"`
 }
 
 type Process struct {
@@ -44,6 +84,14 @@ type Process struct {
 	CMD                 *exec.Cmd
 	OutLogFileHandle    *os.File
 	ErrorsLogFileHandle *os.File
+	// Terminal is only set for executables started with tty.
+	Terminal *Terminal
+	// Cgroup is the cgroup the process was started in, when the freezer is available.
+	Cgroup string
+	// Paused is set while the process is paused. It is cleared when the process exits, and read with pause.
+	Paused  *Pause
+	pauseMu sync.Mutex
+	done    chan struct{}
 }
 
 type Status struct {
@@ -51,21 +99,71 @@ type Status struct {
 	Name        string `json:"name"`
 	PID         int    `json:"pid"`
 	Running     bool   `json:"running"`
+	State       string `json:"state"`
 	AutoRestart bool   `json:"auto_restart"`
 	Group       string `json:"group"`
+	Source      string `json:"source,omitempty"`
+	BinaryPath  string `json:"binary_path"`
+	WorkingDir  string `json:"working_dir"`
+	LogDir      string `json:"log_dir"`
+	Index       int    `json:"index"`
+	Replicas    int    `json:"replicas"`
+	// Schedule is only set for scheduled executables.
+	Schedule *ScheduleStatus `json:"schedule,omitempty"`
+	// Task is only set for tasks.
+	Task *TaskStatus `json:"task,omitempty"`
+	// PausedAt is only set while the executable is paused.
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+	// Actions are the names of the actions of the executable.
+	Actions []string `json:"actions,omitempty"`
+	// IntegrityError is set while the binary fails its verification, and the executable is not started.
+	IntegrityError string `json:"integrity_error,omitempty"`
+	// BuildError is set while the last build failed, and the executable is not started.
+	BuildError string `json:"build_error,omitempty"`
+	// Watch is only set for executables with a watch.
+	Watch *WatchStatus `json:"watch,omitempty"`
+	// Deployment is only set for executables that were deployed.
+	Deployment *DeploymentStatus `json:"deployment,omitempty"`
 }
 
-func (o *Executable) start() error {
-	if o.status().Running {
-		return errors.New("executable is already running: " + o.Name)
+func (o *Executable) start(cgroups *Cgroups) error {
+	if o.Process.running() {
+		return fmt.Errorf("%w: %s", ErrExecutableRunning, o.Name)
 	}
 
-	var err error
+	process, err := o.spawn(cgroups)
+	if err != nil {
+		return err
+	}
+
+	o.CMD = process.CMD
+	o.OutLogFileHandle = process.OutLogFileHandle
+	o.ErrorsLogFileHandle = process.ErrorsLogFileHandle
+	o.PID = process.PID
+	o.Terminal = process.Terminal
+	o.Cgroup = process.Cgroup
+	o.setPause(nil)
+	o.done = process.done
+
+	return nil
+}
+
+/*
+spawn starts a new process of the executable, with its own log files. The process leads its own process group, and
+is started in the cgroup of the executable when there are cgroups, so that it can be paused with its children.
+*/
+func (o *Executable) spawn(cgroups *Cgroups) (*Process, error) {
+	// A tampered or partially copied binary is not started.
+	err := o.verifyBinary()
+	o.integrityErr = err
+	if err != nil {
+		return nil, err
+	}
 
 	timestamp := time.Now().Format(logger.LoggingTimestampFormat)
 
-	logFilePath := o.LogDir + "/" + fmt.Sprintf("%s-%s.log", o.LogFileName, timestamp)
-	errFilePath := o.LogDir + "/" + fmt.Sprintf("%s-%s.log", o.ErrorFileName, timestamp)
+	logFilePath := o.Paths.LogDir + "/" + fmt.Sprintf("%s-%s.log", o.LogFileName, timestamp)
+	errFilePath := o.Paths.LogDir + "/" + fmt.Sprintf("%s-%s.log", o.ErrorFileName, timestamp)
 
 	var outLogF, errLogF *os.File
 	defer func() {
@@ -81,41 +179,119 @@ func (o *Executable) start() error {
 
 	outLogF, err = os.OpenFile(logFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to open log file for %s: %w", o.Name, err)
+		return nil, fmt.Errorf("failed to open log file for %s: %w", o.Name, err)
 	}
 
 	errLogF, err = os.OpenFile(errFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to open error file for %s: %w", o.Name, err)
+		return nil, fmt.Errorf("failed to open error file for %s: %w", o.Name, err)
 	}
 
-	cmd := exec.Command(o.BinaryPath, o.Arguments...)
-	cmd.Dir = o.WorkingDir
-	cmd.Stdout = io.MultiWriter(outLogF)
-	cmd.Stderr = io.MultiWriter(errLogF)
-
-	err = cmd.Start()
+	cmd := exec.Command(o.Paths.BinaryPath, o.Arguments...)
+	cmd.Dir = o.Paths.WorkingDir
+	cmd.Env = o.environment()
+	// A terminal starts a new session, which makes the process the leader of its group already.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: !o.TTY}
+
+	cgroup, release := placeInCgroup(cmd, cgroups, o.ID)
+	defer release()
+
+	var terminal *Terminal
+	if o.TTY {
+		terminal, err = startTerminal(cmd, outLogF)
+	} else {
+		cmd.Stdout = io.MultiWriter(outLogF)
+		cmd.Stderr = io.MultiWriter(errLogF)
+		err = cmd.Start()
+	}
 	if err != nil {
-		return errors.New("error running executable command: " + o.Name + err.Error())
+		if cgroup != "" {
+			removeCgroup(cgroup)
+		}
+		return nil, errors.New("error running executable command: " + o.Name + err.Error())
 	}
 
-	o.CMD = cmd
-	o.OutLogFileHandle = outLogF
-	o.ErrorsLogFileHandle = errLogF
+	return &Process{
+		PID:                 cmd.Process.Pid,
+		CMD:                 cmd,
+		OutLogFileHandle:    outLogF,
+		ErrorsLogFileHandle: errLogF,
+		Terminal:            terminal,
+		Cgroup:              cgroup,
+		done:                make(chan struct{}),
+	}, nil
+}
+
+// environment returns the environment of the orchestrator with the variables of the configuration added. A terminal gets a TERM by default.
+func (o *Executable) environment() []string {
+	if len(o.Env) == 0 && (!o.TTY || os.Getenv("TERM") != "") {
+		return nil
+	}
 
-	o.PID = cmd.Process.Pid
+	environment := os.Environ()
+	if _, ok := o.Env["TERM"]; o.TTY && !ok && os.Getenv("TERM") == "" {
+		environment = append(environment, "TERM="+DefaultTerminalType)
+	}
+	for key, value := range o.Env {
+		environment = append(environment, key+"="+value)
+	}
 
-	return nil
+	return environment
 }
 
-func (o *Executable) wait(stopNotifications chan Notification) {
+// wait blocks until the process exits and releases its log files. Its done channel is closed by the caller.
+func (o *Process) wait() error {
 	err := o.CMD.Wait()
 
+	if o.Terminal != nil {
+		o.Terminal.close()
+	}
 	o.OutLogFileHandle.Close()
 	o.ErrorsLogFileHandle.Close()
+	if o.Cgroup != "" {
+		removeCgroup(o.Cgroup)
+	}
 	o.CMD = nil
+	o.setPause(nil)
+
+	return err
+}
+
+// pause returns the pause of the process, which is nil while it is not paused.
+func (o *Process) pause() *Pause {
+	o.pauseMu.Lock()
+	defer o.pauseMu.Unlock()
+
+	return o.Paused
+}
+
+func (o *Process) setPause(pause *Pause) {
+	o.pauseMu.Lock()
+	defer o.pauseMu.Unlock()
+
+	o.Paused = pause
+}
+
+func (o *Process) running() bool {
+	if o.CMD == nil || o.CMD.Process == nil {
+		return false
+	}
+
+	return o.CMD.Process.Signal(syscall.Signal(0)) == nil
+}
+
+// waitStopped blocks until the current process of the executable has exited and its resources are released.
+func (o *Executable) waitStopped(timeout time.Duration) error {
+	if o.done == nil {
+		return nil
+	}
 
-	stopNotifications <- Notification{Executable: o, err: err}
+	select {
+	case <-o.done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("%w: %s", ErrExecutableStopTimedOut, o.Name)
+	}
 }
 
 func (o *Executable) status() Status {
@@ -125,15 +301,34 @@ func (o *Executable) status() Status {
 	status.PID = o.PID
 	status.AutoRestart = o.AutoRestart
 	status.Group = o.Group
+	status.Source = o.Source
+	status.BinaryPath = o.Paths.BinaryPath
+	status.WorkingDir = o.Paths.WorkingDir
+	status.LogDir = o.Paths.LogDir
+	status.Index = o.Index
+	status.Replicas = o.replicaCount()
+	status.Running = o.Process.running() || o.Job.overlapping() > 0
+
+	status.State = o.state()
+	if pause := o.pause(); pause != nil && status.State == StatePaused {
+		status.PausedAt = &pause.Since
+	}
+	if len(o.Actions) > 0 {
+		status.Actions = o.actionNames()
+	}
+	if o.integrityErr != nil {
+		status.IntegrityError = o.integrityErr.Error()
+	}
+	if o.buildErr != nil {
+		status.BuildError = o.buildErr.Error()
+	}
 
-	running := true
-	switch {
-	case o.CMD == nil, o.CMD.Process == nil:
-		running = false
-	default:
-		running = o.CMD.Process.Signal(syscall.Signal(0)) == nil
+	if o.Schedule != "" {
+		status.Schedule = o.scheduleStatus()
+	}
+	if o.isTask() {
+		status.Task = o.taskStatus()
 	}
-	status.Running = running
 
 	return status
 }
@@ -150,84 +345,169 @@ func (o *Executable) stop() error {
 	return nil
 }
 
+// FieldError is a validation error of a field of the configuration, named by its JSON key.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (o *FieldError) Error() string {
+	return o.Message
+}
+
+// validate checks every field of the configuration, with its resolved paths, and returns all the problems it finds, joined as FieldErrors.
 func (o *Executable) validate() error {
+	var errs []error
+	invalid := func(field string, message string) {
+		errs = append(errs, &FieldError{Field: field, Message: message})
+	}
+
 	// Name
 	if o.Name == "" {
-		return errors.New("executable name is required: " + o.Name)
+		invalid("name", "executable name is required: "+o.Name)
 	}
 
-	// Binary Path
+	// Binary Path, which a build may produce on the first start
 	if o.BinaryPath == "" {
-		return errors.New("binary path is required: " + o.Name)
-	}
-
-	binaryPathInfo, err := os.Stat(o.BinaryPath)
-	if err != nil {
-		return errors.New("error stating binary path: " + o.Name)
-	}
-	if binaryPathInfo.IsDir() {
-		return errors.New("binary path is a directory: " + o.Name)
-	}
-
-	if binaryPathInfo.Mode()&0111 == 0 {
-		return errors.New("binary path is not executable: " + o.Name)
+		invalid("binary_path", "binary path is required: "+o.Name)
+	} else if binaryPathInfo, err := os.Stat(o.Paths.BinaryPath); err != nil {
+		if o.Build == nil || !os.IsNotExist(err) {
+			invalid("binary_path", "error stating binary path: "+o.Name)
+		}
+	} else if binaryPathInfo.IsDir() {
+		invalid("binary_path", "binary path is a directory: "+o.Name)
+	} else if binaryPathInfo.Mode()&0111 == 0 {
+		invalid("binary_path", "binary path is not executable: "+o.Name)
 	}
 
 	// Working Directory
 	if o.WorkingDir == "" {
-		return errors.New("executable working directory is required: " + o.Name)
+		invalid("working_dir", "executable working directory is required: "+o.Name)
+	} else if workingDirectoryInfo, err := os.Stat(o.Paths.WorkingDir); err != nil {
+		invalid("working_dir", "error stating working directory: "+o.Name)
+	} else if !workingDirectoryInfo.IsDir() {
+		invalid("working_dir", "working directory is not a directory of service: "+o.Name)
+	} else if !isWritableDir(o.Paths.WorkingDir) {
+		invalid("working_dir", "cannot write to working directory: "+o.Name)
 	}
 
-	workingDirectoryInfo, err := os.Stat(o.WorkingDir)
-	if err != nil {
-		return errors.New("error stating working directory: " + o.Name)
-	}
-	if !workingDirectoryInfo.IsDir() {
-		return errors.New("working directory is not a directory of service: " + o.Name)
+	// Log Directory
+	if o.LogDir == "" {
+		invalid("log_dir", "log directory is required: "+o.Name)
+	} else if logDirectoryInfo, err := os.Stat(o.Paths.LogDir); err != nil {
+		invalid("log_dir", "error stating log directory: "+o.Name)
+	} else if !logDirectoryInfo.IsDir() {
+		invalid("log_dir", "log directory is not a directory: "+o.Name)
+	} else if !isWritableDir(o.Paths.LogDir) {
+		invalid("log_dir", "cannot write to log directory: "+o.Name)
 	}
 
-	testFile := o.WorkingDir + "/.testwrite"
-	f, err := os.Create(testFile)
-	if err != nil {
-		return errors.New("cannot write to working directory: " + o.Name)
+	// Log & Error File Names
+	if o.LogFileName == "" {
+		invalid("log_file_name", "log file name is required: "+o.Name)
 	}
-	f.Close()
-	os.Remove(testFile)
 
-	// Log Directory
-	if o.LogDir == "" {
-		return errors.New("log directory is required: " + o.Name)
+	if o.ErrorFileName == "" {
+		invalid("error_file_name", "error file name is required: "+o.Name)
 	}
 
-	logDirectoryInfo, err := os.Stat(o.LogDir)
-	if err != nil {
-		return errors.New("error stating log directory: " + o.Name)
-	}
-	if !logDirectoryInfo.IsDir() {
-		return errors.New("log directory is not a directory: " + o.Name)
+	// Group
+	if !helpers.IsOnlyLowercaseAndNumbersAndNotEmpty(o.Group) {
+		invalid("group", "this group name is invalid: "+o.Group)
 	}
 
-	testFile = o.LogDir + "/.testwrite"
-	f, err = os.Create(testFile)
-	if err != nil {
-		return errors.New("cannot write to log directory: " + o.Name)
+	// Replicas
+	if o.Replicas < 0 {
+		invalid("replicas", "replicas cannot be negative: "+o.Name)
 	}
-	f.Close()
-	os.Remove(testFile)
 
-	// Log & Error File Names
-	if o.LogFileName == "" {
-		return errors.New("log file name is required: " + o.Name)
+	// Schedule
+	if o.Schedule == "" {
+		if o.Timezone != "" {
+			invalid("timezone", "timezone requires a schedule: "+o.Name)
+		}
+		if o.ConcurrencyPolicy != "" {
+			invalid("concurrency_policy", "concurrency policy requires a schedule: "+o.Name)
+		}
+		if o.StartingDeadline != "" {
+			invalid("starting_deadline", "starting deadline requires a schedule: "+o.Name)
+		}
+	} else {
+		if _, err := time.LoadLocation(o.Timezone); err != nil {
+			invalid("timezone", "unknown timezone: "+o.Timezone)
+		} else if _, err := o.parseSchedule(); err != nil {
+			invalid("schedule", "invalid schedule: "+err.Error())
+		}
+		if o.AutoRestart {
+			invalid("auto_restart", "auto restart cannot be used with a schedule: "+o.Name)
+		}
+		switch o.ConcurrencyPolicy {
+		case "", ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace:
+		default:
+			invalid("concurrency_policy", "concurrency policy must be allow, forbid or replace: "+o.ConcurrencyPolicy)
+		}
+		if deadline, err := time.ParseDuration(o.StartingDeadline); o.StartingDeadline != "" && (err != nil || deadline <= 0) {
+			invalid("starting_deadline", "starting deadline must be a positive duration such as 1h: "+o.StartingDeadline)
+		}
 	}
 
-	if o.ErrorFileName == "" {
-		return errors.New("error file name is required: " + o.Name)
+	// Type
+	switch o.Type {
+	case "", TypeService:
+		if len(o.SuccessCodes) > 0 {
+			invalid("success_codes", "success codes require the task type: "+o.Name)
+		}
+		if o.MaxRuntime != "" {
+			invalid("max_runtime", "max runtime requires the task type: "+o.Name)
+		}
+		if o.Retries != 0 {
+			invalid("retries", "retries require the task type: "+o.Name)
+		}
+	case TypeTask:
+		if o.AutoRestart {
+			invalid("auto_restart", "auto restart cannot be used with the task type, use retries instead: "+o.Name)
+		}
+		for _, code := range o.SuccessCodes {
+			if code < 0 || code > 255 {
+				invalid("success_codes", "success codes must be between 0 and 255: "+strconv.Itoa(code))
+			}
+		}
+		if maxRuntime, err := time.ParseDuration(o.MaxRuntime); o.MaxRuntime != "" && (err != nil || maxRuntime <= 0) {
+			invalid("max_runtime", "max runtime must be a positive duration such as 30s or 1h: "+o.MaxRuntime)
+		}
+		if o.Retries < 0 {
+			invalid("retries", "retries cannot be negative: "+o.Name)
+		}
+	default:
+		invalid("type", "type must be service or task: "+o.Type)
 	}
 
-	// Group
-	if !helpers.IsOnlyLowercaseAndNumbersAndNotEmpty(o.Group) {
-		return errors.New("this group name is invalid: " + o.Group)
+	// Hooks
+	errs = append(errs, o.validateHooks()...)
+
+	// Actions
+	errs = append(errs, o.validateActions()...)
+
+	// Build
+	errs = append(errs, o.validateBuild()...)
+
+	// Watch
+	errs = append(errs, o.validateWatch()...)
+
+	// Integrity
+	errs = append(errs, o.validateIntegrity()...)
+
+	return errors.Join(errs...)
+}
+
+func isWritableDir(dir string) bool {
+	testFile := dir + "/.testwrite"
+	f, err := os.Create(testFile)
+	if err != nil {
+		return false
 	}
+	f.Close()
+	os.Remove(testFile)
 
-	return nil
+	return true
 }
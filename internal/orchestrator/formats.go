@@ -0,0 +1,566 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// ConfigurationFormat detects the format of a configuration file by its extension. Unknown extensions are read as JSON.
+func ConfigurationFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+/*
+ConvertConfiguration translates a configuration between formats, keeping its defaults and templates. The source
+is decoded as strictly as when it is loaded, but the executables are not validated, so that a configuration can be
+converted on another machine. Comments of the source are not carried over.
+*/
+func ConvertConfiguration(content []byte, source string, from string, to string) ([]byte, *ValidationReport, error) {
+	report := &ValidationReport{Valid: true, Issues: make([]ValidationIssue, 0)}
+
+	document, issues := parseConfiguration(content, from)
+	_, resolverIssues := newTemplateResolver(document)
+	issues = append(issues, resolverIssues...)
+
+	converted := encodedDocument{Executables: make([]any, 0, len(document.Executables))}
+	for _, raw := range document.Executables {
+		value, decodeIssues := layerValue(raw)
+		issues = append(issues, decodeIssues...)
+		converted.Executables = append(converted.Executables, value)
+	}
+	if document.Defaults != nil {
+		converted.Defaults, _ = layerValue(*document.Defaults)
+	}
+	for _, template := range document.Templates {
+		if converted.Templates == nil {
+			converted.Templates = make(map[string]any)
+		}
+		converted.Templates[template.Template], _ = layerValue(template)
+	}
+
+	for _, issue := range issues {
+		issue.Source = source
+		report.add(issue)
+	}
+	report.sort()
+
+	if !report.Valid {
+		return nil, report, nil
+	}
+
+	encoded, err := encodeDocument(converted, to)
+	if err != nil {
+		return nil, report, err
+	}
+
+	return encoded, report, nil
+}
+
+// parseConfiguration splits a configuration in the given format into its executables, defaults and templates.
+func parseConfiguration(content []byte, format string) (rawDocument, []ValidationIssue) {
+	switch format {
+	case FormatYAML:
+		return parseYAML(content)
+	case FormatTOML:
+		return parseTOML(content)
+	default:
+		return parseJSON(content)
+	}
+}
+
+// encodedDocument is a configuration to encode. Its values are configurations, or layers with only some of their fields.
+type encodedDocument struct {
+	Defaults    any            `json:"defaults,omitempty" yaml:"defaults,omitempty" toml:"defaults,omitempty"`
+	Templates   map[string]any `json:"templates,omitempty" yaml:"templates,omitempty" toml:"templates,omitempty"`
+	Executables []any          `json:"executables" yaml:"executables" toml:"executables"`
+}
+
+// encodeDocument encodes a configuration without defaults and templates as an array of executables, except in TOML.
+func encodeDocument(document encodedDocument, format string) ([]byte, error) {
+	var value any = document
+	if document.Defaults == nil && document.Templates == nil && format != FormatTOML {
+		value = document.Executables
+	}
+
+	switch format {
+	case FormatYAML:
+		var buffer bytes.Buffer
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return nil, errors.New("error encoding configuration as YAML: " + err.Error())
+		}
+		encoder.Close()
+
+		return buffer.Bytes(), nil
+	case FormatTOML:
+		content, err := toml.Marshal(value)
+		if err != nil {
+			return nil, errors.New("error encoding configuration as TOML: " + err.Error())
+		}
+
+		return content, nil
+	case FormatJSON:
+		content, err := json.MarshalIndent(value, "", "    ")
+		if err != nil {
+			return nil, errors.New("error encoding configuration as JSON: " + err.Error())
+		}
+
+		return append(content, '\n'), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown configuration format %s", ErrInvalidArgument, format)
+	}
+}
+
+/*
+layerValue decodes the fields that are present in an executable, defaults or template into a struct that has only
+those fields, in the order of the configuration, so that encoding it does not add the missing ones.
+*/
+func layerValue(raw rawExecutable) (any, []ValidationIssue) {
+	_, issues := decodeConfiguration(raw.Index, raw.withoutField(ExtendsField))
+
+	var fields []reflect.StructField
+	var decoders []func(target any) error
+	if index := raw.fieldIndex(ExtendsField); index >= 0 {
+		fields = append(fields, reflect.StructField{Name: "Extends", Type: reflect.TypeOf(""), Tag: `json:"extends" yaml:"extends" toml:"extends"`})
+		decoders = append(decoders, raw.Fields[index].decode)
+	}
+
+	configurationType := reflect.TypeOf(Configuration{})
+	for i := 0; i < configurationType.NumField(); i++ {
+		field := configurationType.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		index := raw.fieldIndex(key)
+		if key == "" || key == "-" || index < 0 {
+			continue
+		}
+
+		fields = append(fields, reflect.StructField{Name: field.Name, Type: field.Type, Tag: field.Tag})
+		decoders = append(decoders, raw.Fields[index].decode)
+	}
+
+	value := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, decode := range decoders {
+		_ = decode(value.Field(i).Addr().Interface())
+	}
+
+	return value.Interface(), issues
+}
+
+// decodeGeneric strictly decodes a value of a YAML or TOML document by passing it through the JSON decoder.
+func decodeGeneric(value any, target any) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(target)
+}
+
+// parseYAML splits a YAML configuration into its executables, defaults and templates, keeping the position of every key and value.
+func parseYAML(content []byte) (rawDocument, []ValidationIssue) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+
+	var document yaml.Node
+	err := decoder.Decode(&document)
+	if errors.Is(err, io.EOF) || (err == nil && len(document.Content) == 0) {
+		return rawDocument{}, []ValidationIssue{{Index: -1, Line: 1, Column: 1, Message: "configuration must be a sequence of executables or a mapping"}}
+	}
+	if err != nil {
+		issue := ValidationIssue{Index: -1, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			issue.Line, _ = strconv.Atoi(match[1])
+			issue.Message = match[2]
+		}
+		return rawDocument{}, []ValidationIssue{issue}
+	}
+
+	var issues []ValidationIssue
+	var next yaml.Node
+	if err := decoder.Decode(&next); !errors.Is(err, io.EOF) {
+		issues = append(issues, ValidationIssue{Index: -1, Line: next.Line, Column: next.Column, Message: "configuration must be a single document"})
+	}
+
+	root := resolveYAMLAlias(document.Content[0])
+	switch root.Kind {
+	case yaml.SequenceNode:
+		executables, executablesIssues := yamlExecutables(root)
+		return rawDocument{Executables: executables}, append(issues, executablesIssues...)
+	case yaml.MappingNode:
+	default:
+		return rawDocument{}, append(issues, ValidationIssue{Index: -1, Line: root.Line, Column: root.Column, Message: "configuration must be a sequence of executables or a mapping"})
+	}
+
+	var result rawDocument
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], resolveYAMLAlias(root.Content[i+1])
+
+		switch key.Value {
+		case "executables":
+			if value.Kind != yaml.SequenceNode {
+				issues = append(issues, ValidationIssue{Index: -1, Field: key.Value, Line: value.Line, Column: value.Column, Message: "executables must be a sequence"})
+				continue
+			}
+			executables, executablesIssues := yamlExecutables(value)
+			result.Executables = append(result.Executables, executables...)
+			issues = append(issues, executablesIssues...)
+		case "defaults":
+			if value.Kind != yaml.MappingNode {
+				issues = append(issues, ValidationIssue{Index: -1, Field: key.Value, Line: value.Line, Column: value.Column, Message: "defaults must be a mapping"})
+				continue
+			}
+			result.Defaults = &rawExecutable{Index: -1, Position: position{Line: value.Line, Column: value.Column}, Fields: yamlFields(value)}
+		case "templates":
+			if value.Kind != yaml.MappingNode {
+				issues = append(issues, ValidationIssue{Index: -1, Field: key.Value, Line: value.Line, Column: value.Column, Message: "templates must be a mapping"})
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name, template := value.Content[j], resolveYAMLAlias(value.Content[j+1])
+				if template.Kind != yaml.MappingNode {
+					issues = append(issues, ValidationIssue{Index: -1, Executable: templateLabel(name.Value), Line: template.Line, Column: template.Column, Message: "template must be a mapping"})
+					continue
+				}
+				result.Templates = append(result.Templates, rawExecutable{
+					Index:    -1,
+					Template: name.Value,
+					Position: position{Line: name.Line, Column: name.Column},
+					Fields:   yamlFields(template),
+				})
+			}
+		default:
+			issues = append(issues, ValidationIssue{Index: -1, Field: key.Value, Line: key.Line, Column: key.Column, Message: "unknown field"})
+		}
+	}
+
+	return result, issues
+}
+
+func yamlExecutables(sequence *yaml.Node) ([]rawExecutable, []ValidationIssue) {
+	var executables []rawExecutable
+	var issues []ValidationIssue
+	for index, item := range sequence.Content {
+		item = resolveYAMLAlias(item)
+		if item.Kind != yaml.MappingNode {
+			issues = append(issues, ValidationIssue{Index: index, Line: item.Line, Column: item.Column, Message: "executable must be a mapping"})
+			continue
+		}
+
+		executables = append(executables, rawExecutable{
+			Index:    index,
+			Position: position{Line: item.Line, Column: item.Column},
+			Fields:   yamlFields(item),
+		})
+	}
+
+	return executables, issues
+}
+
+// yamlFields returns the keys of a mapping. Keys merged with "<<" come after the explicit ones, which override them.
+func yamlFields(mapping *yaml.Node) []rawField {
+	var fields, merged []rawField
+	explicit := make(map[string]bool)
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+
+		if key.Tag == "!!merge" {
+			sources := []*yaml.Node{resolveYAMLAlias(value)}
+			if sources[0].Kind == yaml.SequenceNode {
+				sources = sources[0].Content
+			}
+			for _, source := range sources {
+				if source = resolveYAMLAlias(source); source.Kind == yaml.MappingNode {
+					merged = append(merged, yamlFields(source)...)
+				}
+			}
+			continue
+		}
+
+		explicit[key.Value] = true
+		fields = append(fields, yamlField(key, value))
+	}
+
+	for _, field := range merged {
+		if explicit[field.Key] {
+			continue
+		}
+		explicit[field.Key] = true
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func yamlField(key *yaml.Node, value *yaml.Node) rawField {
+	return rawField{
+		Key:           key.Value,
+		KeyPosition:   position{Line: key.Line, Column: key.Column},
+		ValuePosition: position{Line: value.Line, Column: value.Column},
+		decode: func(target any) error {
+			var generic any
+			if err := value.Decode(&generic); err != nil {
+				return err
+			}
+			return decodeGeneric(generic, target)
+		},
+	}
+}
+
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
+
+/*
+parseTOML reads the "executables" array of tables, and the "defaults" and "templates" tables. The values are
+decoded by go-toml, while the positions of the keys come from walking the document with its parser.
+*/
+func parseTOML(content []byte) (rawDocument, []ValidationIssue) {
+	var document map[string]any
+	if err := toml.Unmarshal(content, &document); err != nil {
+		issue := ValidationIssue{Index: -1, Message: err.Error()}
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			issue.Line, issue.Column = decodeErr.Position()
+		}
+		return rawDocument{}, []ValidationIssue{issue}
+	}
+
+	walker := &tomlWalker{lines: newLineIndex(content), templates: make(map[string]*rawExecutable), unknown: make(map[string]bool)}
+	walker.parser.Reset(content)
+	walker.walk()
+
+	if _, ok := document["executables"]; !ok {
+		walker.issues = append(walker.issues, ValidationIssue{Index: -1, Line: 1, Column: 1, Message: "configuration must have an array of tables named executables"})
+	}
+	executables, _ := document["executables"].([]any)
+	defaults, _ := document["defaults"].(map[string]any)
+	templates, _ := document["templates"].(map[string]any)
+
+	var result rawDocument
+	for index, executable := range walker.executables {
+		var table map[string]any
+		if index < len(executables) {
+			table, _ = executables[index].(map[string]any)
+		}
+		if table == nil {
+			walker.issues = append(walker.issues, ValidationIssue{Index: index, Line: executable.Position.Line, Column: executable.Position.Column, Message: "executable must be a table"})
+			continue
+		}
+		result.Executables = append(result.Executables, bindTOMLFields(*executable, table))
+	}
+
+	if walker.defaults != nil {
+		defaultsLayer := bindTOMLFields(*walker.defaults, defaults)
+		result.Defaults = &defaultsLayer
+	}
+
+	for _, name := range walker.templateNames {
+		template := walker.templates[name]
+		table, ok := templates[name].(map[string]any)
+		if !ok {
+			walker.issues = append(walker.issues, ValidationIssue{Index: -1, Executable: templateLabel(name), Line: template.Position.Line, Column: template.Position.Column, Message: "template must be a table"})
+			continue
+		}
+		result.Templates = append(result.Templates, bindTOMLFields(*template, table))
+	}
+
+	return result, walker.issues
+}
+
+func bindTOMLFields(executable rawExecutable, table map[string]any) rawExecutable {
+	fields := make([]rawField, len(executable.Fields))
+	for i, field := range executable.Fields {
+		value := table[field.Key]
+		field.decode = func(target any) error {
+			return decodeGeneric(value, target)
+		}
+		fields[i] = field
+	}
+	executable.Fields = fields
+
+	return executable
+}
+
+// tomlWalker collects the executables, defaults and templates of a TOML document and the positions of their keys.
+type tomlWalker struct {
+	parser        unstable.Parser
+	lines         lineIndex
+	executables   []*rawExecutable
+	defaults      *rawExecutable
+	templates     map[string]*rawExecutable
+	templateNames []string
+	unknown       map[string]bool
+	issues        []ValidationIssue
+}
+
+func (o *tomlWalker) walk() {
+	var table []string
+	for o.parser.NextExpression() {
+		expression := o.parser.Expression()
+
+		switch expression.Kind {
+		case unstable.ArrayTable, unstable.Table:
+			keys, keyNodes := tomlKey(expression.Key())
+			if expression.Kind == unstable.ArrayTable && len(keys) == 1 && keys[0] == "executables" {
+				o.executables = append(o.executables, &rawExecutable{Index: len(o.executables), Position: o.position(keyNodes[0])})
+			}
+			table = o.absolute(keys)
+			o.visit(table, keyNodes, nil)
+		case unstable.KeyValue:
+			keys, keyNodes := tomlKey(expression.Key())
+			o.visit(append(append([]string{}, table...), keys...), keyNodes, expression.Value())
+		}
+	}
+}
+
+// absolute resolves the keys of a table header, where "executables" refers to the last executable.
+func (o *tomlWalker) absolute(keys []string) []string {
+	if keys[0] != "executables" {
+		return keys
+	}
+
+	return append([]string{"executables", strconv.Itoa(len(o.executables) - 1)}, keys[1:]...)
+}
+
+/*
+visit records the key at the path as a field of its executable, defaults or template. The key nodes are the ones
+of the last elements of the path, as written in the document.
+*/
+func (o *tomlWalker) visit(path []string, keyNodes []*unstable.Node, value *unstable.Node) {
+	var layer *rawExecutable
+	depth := 0
+	keyNode := func(i int) *unstable.Node {
+		i = min(max(i-(len(path)-len(keyNodes)), 0), len(keyNodes)-1)
+		return keyNodes[i]
+	}
+
+	switch path[0] {
+	case "executables":
+		depth = 2
+		if len(path) == 1 && value != nil && value.Kind == unstable.Array {
+			// Inline form: executables = [{ name = "..." }, ...]
+			elements := value.Children()
+			for elements.Next() {
+				o.executables = append(o.executables, &rawExecutable{Index: len(o.executables), Position: o.position(elements.Node())})
+				o.visit([]string{"executables", strconv.Itoa(len(o.executables) - 1)}, []*unstable.Node{elements.Node()}, elements.Node())
+			}
+			return
+		}
+		if len(path) >= 2 {
+			if index, err := strconv.Atoi(path[1]); err == nil && index >= 0 && index < len(o.executables) {
+				layer = o.executables[index]
+			}
+		}
+	case "defaults":
+		depth = 1
+		if o.defaults == nil {
+			o.defaults = &rawExecutable{Index: -1, Position: o.position(keyNode(0))}
+		}
+		layer = o.defaults
+	case "templates":
+		depth = 2
+		if len(path) >= 2 {
+			if o.templates[path[1]] == nil {
+				o.templates[path[1]] = &rawExecutable{Index: -1, Template: path[1], Position: o.position(keyNode(1))}
+				o.templateNames = append(o.templateNames, path[1])
+			}
+			layer = o.templates[path[1]]
+		}
+	default:
+		if !o.unknown[path[0]] {
+			o.unknown[path[0]] = true
+			position := o.position(keyNode(0))
+			o.issues = append(o.issues, ValidationIssue{Index: -1, Field: path[0], Line: position.Line, Column: position.Column, Message: "unknown field"})
+		}
+		return
+	}
+
+	if len(path) > depth && layer != nil {
+		if layer.fieldIndex(path[depth]) < 0 {
+			field := rawField{Key: path[depth], KeyPosition: o.position(keyNode(depth)), ValuePosition: o.position(value)}
+			if value == nil {
+				field.ValuePosition = field.KeyPosition
+			}
+			layer.Fields = append(layer.Fields, field)
+		}
+		return
+	}
+
+	// The key is a table of executables, defaults or templates written inline.
+	if value != nil && value.Kind == unstable.InlineTable {
+		keyValues := value.Children()
+		for keyValues.Next() {
+			keys, keyNodes := tomlKey(keyValues.Node().Key())
+			o.visit(append(append([]string{}, path...), keys...), keyNodes, keyValues.Node().Value())
+		}
+	}
+}
+
+// position returns the position of the node, or of its first child when the parser does not keep its range.
+func (o *tomlWalker) position(node *unstable.Node) position {
+	for node != nil && node.Raw.Length == 0 {
+		node = node.Child()
+	}
+	if node == nil {
+		return position{}
+	}
+
+	return o.lines.position(int64(node.Raw.Offset))
+}
+
+func tomlKey(iterator unstable.Iterator) ([]string, []*unstable.Node) {
+	var keys []string
+	var nodes []*unstable.Node
+	for iterator.Next() {
+		keys = append(keys, string(iterator.Node().Data))
+		nodes = append(nodes, iterator.Node())
+	}
+
+	return keys, nodes
+}
+
+// fieldIndex returns the index of the key among the fields of the executable, or -1.
+func (o rawExecutable) fieldIndex(key string) int {
+	for i, field := range o.Fields {
+		if field.Key == key {
+			return i
+		}
+	}
+
+	return -1
+}
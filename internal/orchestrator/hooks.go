@@ -0,0 +1,185 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/internal/logger"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+)
+
+var (
+	HookPreStart  = "pre_start"
+	HookPostStart = "post_start"
+	HookPreStop   = "pre_stop"
+	HookPostStop  = "post_stop"
+)
+
+var (
+	EventHookSucceeded = "hook_succeeded"
+	EventHookFailed    = "hook_failed"
+)
+
+var (
+	DefaultHookTimeout = 30 * time.Second
+)
+
+/*
+Hooks are commands run around the lifecycle of an executable, with its working directory and environment.
+A failing pre_start hook aborts the start. The other hooks are reported when they fail, without changing the
+lifecycle: post_start runs next to the new process, pre_stop before the stop signal is sent, and post_stop
+after the process exited, before it is restarted.
+*/
+type Hooks struct {
+	PreStart  *Hook `json:"pre_start,omitempty" yaml:"pre_start,omitempty" toml:"pre_start,omitempty"`
+	PostStart *Hook `json:"post_start,omitempty" yaml:"post_start,omitempty" toml:"post_start,omitempty"`
+	PreStop   *Hook `json:"pre_stop,omitempty" yaml:"pre_stop,omitempty" toml:"pre_stop,omitempty"`
+	PostStop  *Hook `json:"post_stop,omitempty" yaml:"post_stop,omitempty" toml:"post_stop,omitempty"`
+}
+
+type Hook struct {
+	Command   string   `json:"command" yaml:"command" toml:"command"`
+	Arguments []string `json:"arguments,omitempty" yaml:"arguments,omitempty" toml:"arguments,omitempty"`
+	// Timeout is a duration such as 10s, 30s by default.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+}
+
+// HookPaths are the resolved commands of the hooks.
+type HookPaths struct {
+	PreStart  string
+	PostStart string
+	PreStop   string
+	PostStop  string
+}
+
+type namedHook struct {
+	name string
+	hook *Hook
+	// path points to the resolved command in the paths of the executable.
+	path *string
+}
+
+// hooks returns the hooks of the configuration in the order of the lifecycle.
+func (o *Executable) hooks() []namedHook {
+	if o.Hooks == nil {
+		return nil
+	}
+
+	hooks := make([]namedHook, 0, 4)
+	for _, hook := range []namedHook{
+		{HookPreStart, o.Hooks.PreStart, &o.Paths.Hooks.PreStart},
+		{HookPostStart, o.Hooks.PostStart, &o.Paths.Hooks.PostStart},
+		{HookPreStop, o.Hooks.PreStop, &o.Paths.Hooks.PreStop},
+		{HookPostStop, o.Hooks.PostStop, &o.Paths.Hooks.PostStop},
+	} {
+		if hook.hook != nil {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	return hooks
+}
+
+func (o Hook) timeout() time.Duration {
+	timeout, err := time.ParseDuration(o.Timeout)
+	if err != nil || timeout <= 0 {
+		return DefaultHookTimeout
+	}
+
+	return timeout
+}
+
+func (o Hooks) clone() *Hooks {
+	clone := func(hook *Hook) *Hook {
+		if hook == nil {
+			return nil
+		}
+		copied := *hook
+		if hook.Arguments != nil {
+			copied.Arguments = append([]string{}, hook.Arguments...)
+		}
+		return &copied
+	}
+
+	return &Hooks{PreStart: clone(o.PreStart), PostStart: clone(o.PostStart), PreStop: clone(o.PreStop), PostStop: clone(o.PostStop)}
+}
+
+/*
+runHook runs a hook of the executable, if it has one, and waits for it at most for its timeout. Its output is
+written to "<log_file_name>.<hook>-<timestamp>.log" in the log directory of the executable. Besides the
+environment of the executable, the hook gets ORCHESTRATOR_EXECUTABLE, ORCHESTRATOR_HOOK and, when the executable
+has a process, ORCHESTRATOR_PID.
+*/
+func (o *Orchestrator) runHook(executable *Executable, name string, pid int) error {
+	index := slices.IndexFunc(executable.hooks(), func(hook namedHook) bool { return hook.name == name })
+	if index < 0 {
+		return nil
+	}
+	hook := executable.hooks()[index]
+
+	err := executable.runHook(name, *hook.hook, *hook.path, pid)
+	if err != nil {
+		o.Logger.Printf(logger.LogErr+"Hook %s of executable %s failed: %s", name, executable.Name, err.Error())
+		o.publish(EventHookFailed, executable, err.Error(), map[string]string{"hook": name})
+		return fmt.Errorf("%w: %s of %s: %s", ErrHookFailed, name, executable.Name, err.Error())
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Hook %s of executable %s succeeded", name, executable.Name)
+	o.publish(EventHookSucceeded, executable, "", map[string]string{"hook": name})
+
+	return nil
+}
+
+func (o *Executable) runHook(name string, hook Hook, path string, pid int) error {
+	timestamp := time.Now().Format(logger.LoggingTimestampFormat)
+	logFilePath := o.Paths.LogDir + "/" + fmt.Sprintf("%s.%s-%s.log", o.LogFileName, name, timestamp)
+
+	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.New("failed to open hook log file: " + err.Error())
+	}
+	defer logFile.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), hook.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, hook.Arguments...)
+	cmd.Dir = o.Paths.WorkingDir
+	cmd.Env = o.commandEnvironment(pid, "ORCHESTRATOR_HOOK="+name)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New("timed out after " + hook.timeout().String())
+	}
+
+	return err
+}
+
+// validateHooks checks the hooks of the configuration, with their resolved commands.
+func (o *Executable) validateHooks() []error {
+	var errs []error
+	for _, hook := range o.hooks() {
+		invalid := func(message string) {
+			errs = append(errs, &FieldError{Field: "hooks", Message: hook.name + ": " + message})
+		}
+
+		if hook.hook.Command == "" {
+			invalid("command is required: " + o.Name)
+		} else if info, err := os.Stat(*hook.path); err != nil {
+			invalid("error stating command: " + hook.hook.Command)
+		} else if info.IsDir() || info.Mode()&0111 == 0 {
+			invalid("command is not executable: " + hook.hook.Command)
+		}
+
+		if timeout, err := time.ParseDuration(hook.hook.Timeout); hook.hook.Timeout != "" && (err != nil || timeout <= 0) {
+			invalid("timeout must be a positive duration such as 10s: " + hook.hook.Timeout)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,209 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"orchestrator/internal/audit"
+	"orchestrator/internal/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+/*
+IntegrityError is a binary that does not match its checksum or signature, e.g. because it was tampered with or only
+partially copied. Field is the setting that failed. It is recorded in the audit log with the integrity_failure result.
+*/
+type IntegrityError struct {
+	Field string
+	Path  string
+	err   error
+}
+
+func (o *IntegrityError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrIntegrity.Error(), o.Path, o.err.Error())
+}
+
+func (o *IntegrityError) Unwrap() error {
+	return ErrIntegrity
+}
+
+func (o *IntegrityError) AuditResult() string {
+	return audit.ResultIntegrityFailure
+}
+
+// verifiesBinary tells whether the binary has a checksum or a signature to be verified against.
+func (o Configuration) verifiesBinary() bool {
+	return o.SHA256 != "" || o.ChecksumsFile != "" || o.SignatureFile != ""
+}
+
+/*
+verifyBinary checks the binary against its sha256, its entry in the checksums file and its ed25519 signature, those
+that are configured. The binary is read once for all of them.
+*/
+func (o *Executable) verifyBinary() error {
+	if !o.verifiesBinary() {
+		return nil
+	}
+
+	path := o.Paths.BinaryPath
+	failed := func(field string, err error) error {
+		return &IntegrityError{Field: field, Path: path, err: err}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return failed("binary_path", errors.New("error reading binary: "+err.Error()))
+	}
+	sum := sha256.Sum256(content)
+	actual := hex.EncodeToString(sum[:])
+
+	if o.SHA256 != "" && !strings.EqualFold(o.SHA256, actual) {
+		return failed("sha256", fmt.Errorf("sha256 is %s, expected %s", actual, strings.ToLower(o.SHA256)))
+	}
+
+	if o.ChecksumsFile != "" {
+		expected, err := checksumOf(o.Paths.ChecksumsFile, filepath.Base(path))
+		if err != nil {
+			return failed("checksums_file", err)
+		}
+		if !strings.EqualFold(expected, actual) {
+			return failed("checksums_file", fmt.Errorf("sha256 is %s, expected %s", actual, strings.ToLower(expected)))
+		}
+	}
+
+	if o.SignatureFile != "" {
+		if err := verifySignature(content, o.Paths.SignatureFile); err != nil {
+			return failed("signature_file", err)
+		}
+	}
+
+	return nil
+}
+
+// checksumOf returns the SHA-256 of a file name in a checksums file in the format of sha256sum.
+func checksumOf(checksumsFile string, name string) (string, error) {
+	content, err := os.ReadFile(checksumsFile)
+	if err != nil {
+		return "", errors.New("error reading checksums file: " + err.Error())
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		// Lines are "<sha256>  <name>", or "<sha256> *<name>" for binary mode.
+		checksum, file, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		file = strings.TrimPrefix(strings.TrimLeft(file, " "), "*")
+		if ok && sha256Pattern.MatchString(checksum) && (file == name || filepath.Base(file) == name) {
+			return checksum, nil
+		}
+	}
+
+	return "", errors.New("no checksum of " + name + " in " + checksumsFile)
+}
+
+// verifySignature checks a detached ed25519 signature of the content, raw or base64 encoded, against the trusted keys.
+func verifySignature(content []byte, signatureFile string) error {
+	signature, err := os.ReadFile(signatureFile)
+	if err != nil {
+		return errors.New("error reading signature: " + err.Error())
+	}
+	if len(signature) != ed25519.SignatureSize {
+		signature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil || len(signature) != ed25519.SignatureSize {
+			return errors.New("signature is not an ed25519 signature: " + signatureFile)
+		}
+	}
+
+	keys, err := trustedKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, content, signature) {
+			return nil
+		}
+	}
+
+	return errors.New("signature does not match any trusted key")
+}
+
+/*
+trustedKeys reads the ed25519 public keys signatures are verified against from TRUSTED_KEYS_PATH: one base64 encoded
+key per line, with # comments. The file is read on every verification, so that keys can be rotated without a restart.
+*/
+func trustedKeys() ([]ed25519.PublicKey, error) {
+	path := config.GetConfig().TRUSTED_KEYS_PATH
+	if path == "" {
+		return nil, errors.New("no trusted keys are configured, TRUSTED_KEYS_PATH is not set")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("error reading trusted keys: " + err.Error())
+	}
+
+	var keys []ed25519.PublicKey
+	for number, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("trusted key on line %d of %s is not a base64 ed25519 public key", number+1, path)
+		}
+		keys = append(keys, ed25519.PublicKey(key))
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no trusted keys in " + path)
+	}
+
+	return keys, nil
+}
+
+// validateIntegrity checks the integrity settings, then verifies the binary when they are valid.
+func (o *Executable) validateIntegrity() []error {
+	var errs []error
+	invalid := func(field string, message string) {
+		errs = append(errs, &FieldError{Field: field, Message: message})
+	}
+
+	if o.SHA256 != "" && !sha256Pattern.MatchString(o.SHA256) {
+		invalid("sha256", "sha256 must be 64 hexadecimal digits: "+o.Name)
+	}
+	if o.ChecksumsFile != "" {
+		if _, err := os.Stat(o.Paths.ChecksumsFile); err != nil {
+			invalid("checksums_file", "error stating checksums file: "+o.Name)
+		}
+	}
+	if o.SignatureFile != "" {
+		if _, err := os.Stat(o.Paths.SignatureFile); err != nil {
+			invalid("signature_file", "error stating signature file: "+o.Name)
+		}
+		if _, err := trustedKeys(); err != nil {
+			invalid("signature_file", err.Error())
+		}
+	}
+	// A missing binary is reported by the binary_path check.
+	if _, err := os.Stat(o.Paths.BinaryPath); len(errs) > 0 || err != nil {
+		return errs
+	}
+
+	var integrityErr *IntegrityError
+	if err := o.verifyBinary(); errors.As(err, &integrityErr) {
+		invalid(integrityErr.Field, integrityErr.Error())
+	}
+
+	return errs
+}
@@ -0,0 +1,172 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"orchestrator/internal/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	LogTailPollInterval = 500 * time.Millisecond
+	LogTailChunkSize    = 32 * 1024
+	// StderrTailBytes is the size of the end of the error log that is attached to the exited event.
+	StderrTailBytes = 2048
+)
+
+/*
+TailLogs streams the most recent log file of an executable from its current end, or from its start when fromStart is set.
+When the executable is started again and a newer log file appears, tailing continues from the start of the new file.
+The channel is closed when the context is done.
+*/
+func (o *Orchestrator) TailLogs(ctx context.Context, logsType string, processUUID uuid.UUID, fromStart bool) (<-chan string, error) {
+	executable := o.executable(processUUID)
+	if executable == nil {
+		return nil, ErrExecutableNotFound
+	}
+
+	if _, err := executable.logFiles(logsType); err != nil && !errors.Is(err, ErrLogsNotFound) {
+		return nil, err
+	}
+
+	chunks := make(chan string)
+	go func() {
+		defer close(chunks)
+
+		var file *os.File
+		defer func() {
+			if file != nil {
+				file.Close()
+			}
+		}()
+
+		buffer := make([]byte, LogTailChunkSize)
+		seekEnd := !fromStart
+		for {
+			if logs, err := executable.logFiles(logsType); err == nil && (file == nil || logs[0] != file.Name()) {
+				if file != nil {
+					o.drainLogs(ctx, file, buffer, chunks)
+					file.Close()
+				}
+
+				file, err = os.Open(logs[0])
+				if err != nil {
+					o.Logger.Printf(logger.LogErr+"Error opening log file %s: %s", logs[0], err.Error())
+					return
+				}
+				if seekEnd {
+					_, _ = file.Seek(0, io.SeekEnd)
+				}
+			}
+			seekEnd = false
+
+			if file != nil && !o.drainLogs(ctx, file, buffer, chunks) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(LogTailPollInterval):
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// drainLogs sends everything that can be read from the file. It reports false when the context is done.
+func (o *Orchestrator) drainLogs(ctx context.Context, file *os.File, buffer []byte, chunks chan<- string) bool {
+	for {
+		n, err := file.Read(buffer)
+		if n > 0 {
+			select {
+			case chunks <- string(buffer[:n]):
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if err != nil || n == 0 {
+			return true
+		}
+	}
+}
+
+// logFiles returns the paths of the log files of the executable, the most recent first.
+func (o *Executable) logFiles(logsType string) ([]string, error) {
+	var logPrefix string
+	switch logsType {
+	case logger.LogTypeOut:
+		logPrefix = o.LogFileName
+	case logger.LogTypeError:
+		logPrefix = o.ErrorFileName
+	case logger.LogTypeBuild:
+		logPrefix = o.LogFileName + "." + logger.LogTypeBuild
+	default:
+		return nil, fmt.Errorf("%w: invalid logs type", ErrInvalidArgument)
+	}
+
+	files, err := os.ReadDir(o.Paths.LogDir)
+	if err != nil {
+		return nil, errors.New("error reading logs folder: " + err.Error())
+	}
+
+	var logs []string
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), logPrefix+"-") || filepath.Ext(file.Name()) != ".log" {
+			continue
+		}
+		// The files of other instances, such as "out-1-<timestamp>.log" for "out", start with the prefix too.
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(file.Name(), logPrefix+"-"), ".log")
+		if _, err := time.Parse(logger.LoggingTimestampFormat, timestamp); err == nil {
+			logs = append(logs, filepath.Join(o.Paths.LogDir, file.Name()))
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil, ErrLogsNotFound
+	}
+
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i] > logs[j]
+	})
+
+	return logs, nil
+}
+
+// stderrTail returns the end of the most recent error log file of the executable, empty when there is none.
+func (o *Executable) stderrTail() string {
+	logs, err := o.logFiles(logger.LogTypeError)
+	if err != nil {
+		return ""
+	}
+
+	return logTail(logs[0], StderrTailBytes)
+}
+
+// logTail returns at most the last size bytes of a log file, empty when it cannot be read.
+func logTail(path string, size int) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return ""
+	}
+
+	content := make([]byte, min(info.Size(), int64(size)))
+	n, _ := file.ReadAt(content, info.Size()-int64(len(content)))
+
+	return strings.TrimSpace(strings.ToValidUTF8(string(content[:n]), ""))
+}
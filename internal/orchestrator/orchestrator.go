@@ -2,24 +2,46 @@ package orchestrator
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"orchestrator/internal/alerts"
+	"orchestrator/internal/audit"
 	"orchestrator/internal/config"
+	"orchestrator/internal/events"
 	"orchestrator/internal/logger"
+	"orchestrator/internal/webhooks"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"sort"
-	"strings"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
 )
 
 var (
 	RestartDelaySeconds = 10
+	StopTimeoutSeconds  = 10
+)
+
+var (
+	ActionSet         = "set"
+	ActionUnset       = "unset"
+	ActionRunAll      = "run_all"
+	ActionRunGroup    = "run_group"
+	ActionRun         = "run"
+	ActionStopAll     = "stop_all"
+	ActionStopGroup   = "stop_group"
+	ActionStop        = "stop"
+	ActionRestart     = "restart"
+	ActionReload      = "reload"
+	ActionAutoRestart = "auto_restart"
 )
 
 type OrchestratorInterface interface {
@@ -27,40 +49,122 @@ type OrchestratorInterface interface {
 
 	Set(ctx context.Context) error
 	Unset(ctx context.Context) error
+	Reload(ctx context.Context) error
+	Validate(ctx context.Context, content io.Reader, format string) (*ValidationReport, error)
 	Status(ctx context.Context) ([]Status, error)
+	ExecutableStatus(ctx context.Context, processUUID uuid.UUID) (Status, error)
+	ResolvedConfiguration(ctx context.Context) ([]ResolvedConfiguration, error)
+	ExecutableConfiguration(ctx context.Context, processUUID uuid.UUID) (ResolvedConfiguration, error)
 
 	RunAll(ctx context.Context) error
 	RunGroup(ctx context.Context, group string) error
+	RunGroupAndWait(ctx context.Context, group string) (GroupResult, error)
 	Run(ctx context.Context, processUUID uuid.UUID) error
 
 	StopAll(ctx context.Context) error
 	StopGroup(ctx context.Context, group string) error
 	Stop(ctx context.Context, processUUID uuid.UUID) error
 
+	Restart(ctx context.Context, processUUID uuid.UUID) error
+	RollingRestart(ctx context.Context, group string, options RolloutOptions) (RolloutResult, error)
+	Deploy(ctx context.Context, processUUID uuid.UUID, request DeployRequest) (DeployResult, error)
+
+	Pause(ctx context.Context, processUUID uuid.UUID) error
+	PauseGroup(ctx context.Context, group string) error
+	Resume(ctx context.Context, processUUID uuid.UUID) error
+	ResumeGroup(ctx context.Context, group string) error
+
+	Signal(ctx context.Context, processUUID uuid.UUID, signal string) error
+	SignalGroup(ctx context.Context, group string, signal string) error
+	RunAction(ctx context.Context, processUUID uuid.UUID, name string) (ActionResult, error)
+
+	CreateExecutable(ctx context.Context, configuration Configuration) (Status, error)
+	UpdateExecutable(ctx context.Context, processUUID uuid.UUID, configuration Configuration) (UpdateResult, error)
+	DeleteExecutable(ctx context.Context, processUUID uuid.UUID) error
+	Scale(ctx context.Context, processUUID uuid.UUID, replicas int) ([]Status, error)
+
 	ExecLogs(ctx context.Context, logsType string, processUUID uuid.UUID, offset int) (string, error)
+	TailLogs(ctx context.Context, logsType string, processUUID uuid.UUID, fromStart bool) (<-chan string, error)
+	Attach(ctx context.Context, processUUID uuid.UUID, readOnly bool) (*Attachment, error)
+	Subscribe() (<-chan events.Event, func())
 }
 
 type Orchestrator struct {
-	Logger        *log.Logger
-	LoggerCleanup func()
-	Notifications chan Notification
-	Executables   Executables
+	Logger          *log.Logger
+	LoggerCleanup   func()
+	Auditor         *audit.Auditor
+	AuditorCleanup  func()
+	Events          *events.Bus
+	Webhooks        *webhooks.Dispatcher
+	WebhooksCleanup func()
+	Alerts          *alerts.Engine
+	AlertsCleanup   func()
+	Cgroups         *Cgroups
+	Releases        *Releases
+	Notifications   chan Notification
+	Executables     Executables
+	Scheduler       *cron.Cron
+	scheduled       Executables
+	rollouts        sync.Map
+	// builds are the locks of the builds of the executables, by declared name.
+	builds sync.Map
+	// watches are the watchers of the executables with a watch, by declared name.
+	watches map[string]*Watcher
+	// documents are the configuration files as they were parsed to persist an entry, by path. They are read again once the executables are set or reloaded.
+	documents map[string]*configurationDocument
+	// mu guards Executables, scheduled, watches and documents, which are changed with the write lock. Readers iterate the copy returned by executables.
+	mu sync.RWMutex
 }
 
 type Notification struct {
 	Executable *Executable
+	pid        int
 	err        error
+	// restarting is set for an exit caused by a restart.
+	restarting bool
 }
 
 func NewOrchestrator() *Orchestrator {
 	logger, cleanup := logger.NewLogger()
 
-	return &Orchestrator{
-		Logger:        logger,
-		LoggerCleanup: cleanup,
-		Notifications: make(chan Notification),
-		Executables:   make(Executables, 0),
+	scheduler := cron.New(cron.WithParser(scheduleParser))
+	scheduler.Start()
+
+	c := config.GetConfig()
+	auditor, auditorCleanup, err := audit.NewAuditor(c.AUDIT_LOG_DIR, c.AUDIT_RETENTION_DAYS)
+	if err != nil {
+		panic(err)
+	}
+
+	bus := events.NewBus()
+	dispatcher, webhooksCleanup, err := webhooks.NewDispatcher(c.WEBHOOKS_DIR, bus, logger)
+	if err != nil {
+		panic(err)
 	}
+
+	orchestrator := &Orchestrator{
+		Logger:          logger,
+		LoggerCleanup:   cleanup,
+		Auditor:         auditor,
+		AuditorCleanup:  auditorCleanup,
+		Events:          bus,
+		Webhooks:        dispatcher,
+		WebhooksCleanup: webhooksCleanup,
+		Notifications:   make(chan Notification),
+		Executables:     make(Executables, 0),
+		Scheduler:       scheduler,
+		watches:         make(map[string]*Watcher),
+	}
+
+	orchestrator.Alerts, orchestrator.AlertsCleanup, err = alerts.NewEngine(c.ALERTS_DIR, bus, orchestrator.alertTargets, logger)
+	if err != nil {
+		panic(err)
+	}
+
+	orchestrator.setupCgroups(c.CGROUP_DIR)
+	orchestrator.setupReleases(c.RELEASES_DIR)
+
+	return orchestrator
 }
 
 func (o *Orchestrator) ConsumeNotifications() {
@@ -78,39 +182,53 @@ func (o *Orchestrator) ConsumeNotifications() {
 		} else {
 			o.Logger.Printf(logger.LogInfo+"Executable %s has finished successfully", executable.Name)
 		}
+		attributes := exitAttributes(notification.err)
+		if tail := executable.stderrTail(); tail != "" {
+			attributes["stderr_tail"] = tail
+		}
+		o.publish(EventExited, executable, "", attributes)
+		executable.Job.finished(notification.pid, notification.err)
+		// Retries wait on their own, so that the outcome of other tasks is not delayed.
+		if o.completeTask(executable, notification.pid, notification.err) {
+			go o.retryTask(executable)
+		}
 
-		if executable.AutoRestart && !o.isErrorGracefull(notification.err) {
+		// An instance removed by scaling down is not restarted, nor an executable that a restart starts again.
+		if executable.AutoRestart && !notification.restarting && !o.isErrorGracefull(notification.err) && o.executable(executable.ID) != nil {
+			o.trackRestart(executable)
 			o.Logger.Printf(logger.LogInfo+"Sleepin delay before starting the executable: %s", executable.Name)
+			o.publish(EventRestartScheduled, executable, "", map[string]string{"delay_seconds": strconv.Itoa(RestartDelaySeconds)})
 			time.Sleep(time.Duration(RestartDelaySeconds) * time.Second)
-			o.startExecutable(executable)
+			if executable.restarting.Load() || executable.Process.running() {
+				o.Logger.Printf(logger.LogInfo+"Executable %s was restarted during the delay", executable.Name)
+				continue
+			}
+			err := o.startExecutable(executable)
+			o.audit(context.Background(), ActionAutoRestart, Executables{executable}, err)
 		}
 	}
 }
 
 func (o *Orchestrator) Set(ctx context.Context) error {
-	var err error
+	o.mu.Lock()
+	defer o.mu.Unlock()
 
-	if len(o.Executables) > 0 {
-		return errors.New("executables already set")
-	}
+	return o.set(ctx)
+}
 
-	file, err := os.Open(config.GetConfig().EXECUTABLES_JSON_PATH)
-	if err != nil {
-		return errors.New("error opening executables file: " + err.Error())
-	}
-	defer file.Close()
+// set is Set with the write lock held.
+func (o *Orchestrator) set(ctx context.Context) (err error) {
+	defer func() {
+		o.audit(ctx, ActionSet, o.Executables, err)
+	}()
 
-	var executables Executables
-	err = json.NewDecoder(file).Decode(&executables)
-	if err != nil {
-		return errors.New("error decoding executables file: " + err.Error())
+	if len(o.Executables) > 0 {
+		return ErrExecutablesAlreadySet
 	}
 
-	for _, executable := range executables {
-		err = executable.validate()
-		if err != nil {
-			return errors.New("error validating executable: " + executable.Name + " " + err.Error())
-		}
+	executables, err := loadExecutables(config.GetConfig().EXECUTABLES_JSON_PATH)
+	if err != nil {
+		return err
 	}
 
 	for _, executable := range executables {
@@ -118,76 +236,229 @@ func (o *Orchestrator) Set(ctx context.Context) error {
 	}
 
 	o.Executables = executables
+	o.documents = nil
+	o.syncSchedules()
+	o.syncWatches()
+	o.publish(EventConfigSet, nil, "", map[string]string{"executables": strconv.Itoa(len(executables))})
 
 	return nil
 }
 
-func (o *Orchestrator) Unset(ctx context.Context) error {
+func (o *Orchestrator) Unset(ctx context.Context) (err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	unset := o.Executables
+	defer func() {
+		o.audit(ctx, ActionUnset, unset, err)
+	}()
+
 	if len(o.Executables) == 0 {
-		return errors.New("no executables to unset")
+		return fmt.Errorf("%w: no executables to unset", ErrExecutablesNotSet)
 	}
 
 	for _, executable := range o.Executables {
 		if executable.status().Running {
-			return errors.New("cannot unset executable " + executable.Name + " is running")
+			return fmt.Errorf("%w: cannot unset executable %s", ErrExecutableRunning, executable.Name)
 		}
 	}
 
 	o.Executables = make(Executables, 0)
+	o.syncSchedules()
+	o.syncWatches()
+	o.publish(EventConfigUnset, nil, "", nil)
 
 	return nil
 }
 
-func (o *Orchestrator) Status(ctx context.Context) ([]Status, error) {
-	statuses := make([]Status, 0, len(o.Executables))
+/*
+Reload applies the configuration file to the executables that are set, matching them by name.
+New executables are added, changed executables keep their ID and apply the new configuration on their next start,
+and removed executables are unset. A removed executable that is still running fails the reload.
+*/
+func (o *Orchestrator) Reload(ctx context.Context) (err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.Executables) == 0 {
+		return o.set(ctx)
+	}
+
+	defer func() {
+		o.audit(ctx, ActionReload, o.Executables, err)
+	}()
+
+	executables, err := loadExecutables(config.GetConfig().EXECUTABLES_JSON_PATH)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]*Executable, len(o.Executables))
 	for _, executable := range o.Executables {
-		status := executable.status()
+		current[executable.Name] = executable
+	}
+
+	reloaded := make(map[string]bool, len(executables))
+	for _, executable := range executables {
+		reloaded[executable.Name] = true
+	}
+
+	for _, executable := range o.Executables {
+		if !reloaded[executable.Name] && executable.status().Running {
+			return fmt.Errorf("%w: cannot remove executable %s", ErrExecutableRunning, executable.Name)
+		}
+	}
+
+	result := make(Executables, 0, len(executables))
+	for _, executable := range executables {
+		existing, ok := current[executable.Name]
+		if !ok {
+			executable.ID = uuid.New()
+			result = append(result, executable)
+			o.Logger.Printf(logger.LogInfo+"Reload added executable %s", executable.Name)
+			continue
+		}
+
+		if !reflect.DeepEqual(existing.Configuration, executable.Configuration) || !reflect.DeepEqual(existing.Paths, executable.Paths) {
+			existing.Configuration = executable.Configuration
+			existing.Paths = executable.Paths
+			existing.Declared = executable.Declared
+			existing.Index = executable.Index
+			o.Logger.Printf(logger.LogInfo+"Reload updated executable %s", executable.Name)
+		}
+		result = append(result, existing)
+	}
+
+	o.Executables = result
+	o.documents = nil
+	o.syncSchedules()
+	o.syncWatches()
+	o.publish(EventConfigReloaded, nil, "", map[string]string{"executables": strconv.Itoa(len(result))})
+
+	return nil
+}
+
+// Validate decodes and validates a candidate configuration in the format, JSON by default, without applying it and reports every problem it finds.
+func (o *Orchestrator) Validate(ctx context.Context, content io.Reader, format string) (*ValidationReport, error) {
+	_, report, err := readExecutables(content, format)
+
+	return report, err
+}
+
+func (o *Orchestrator) Status(ctx context.Context) ([]Status, error) {
+	executables := o.executables()
+	statuses := make([]Status, 0, len(executables))
+	for _, executable := range executables {
+		status := o.statusOf(executable)
 		statuses = append(statuses, status)
 	}
 
 	return statuses, nil
 }
 
+// alertTargets are the executables that alert rules are evaluated against: tasks and scheduled executables are not expected to keep running.
+func (o *Orchestrator) alertTargets() []alerts.Target {
+	executables := o.executables()
+	targets := make([]alerts.Target, 0, len(executables))
+	for _, executable := range executables {
+		if executable.isTask() || executable.Schedule != "" {
+			continue
+		}
+
+		targets = append(targets, alerts.Target{
+			ID:      executable.ID.String(),
+			Name:    executable.Name,
+			Group:   executable.Group,
+			Running: executable.Process.running(),
+		})
+	}
+
+	return targets
+}
+
+func (o *Orchestrator) ExecutableStatus(ctx context.Context, processUUID uuid.UUID) (Status, error) {
+	executable := o.executable(processUUID)
+	if executable == nil {
+		return Status{}, ErrExecutableNotFound
+	}
+
+	return o.statusOf(executable), nil
+}
+
+// ResolvedConfiguration returns the configuration of the executables as they are run. When they are not set, it is resolved from the configuration files.
+func (o *Orchestrator) ResolvedConfiguration(ctx context.Context) ([]ResolvedConfiguration, error) {
+	executables := o.executables()
+	if len(executables) == 0 {
+		var err error
+		executables, err = loadExecutables(config.GetConfig().EXECUTABLES_JSON_PATH)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configurations := make([]ResolvedConfiguration, 0, len(executables))
+	for _, executable := range executables {
+		configurations = append(configurations, executable.resolvedConfiguration())
+	}
+
+	return configurations, nil
+}
+
+func (o *Orchestrator) ExecutableConfiguration(ctx context.Context, processUUID uuid.UUID) (ResolvedConfiguration, error) {
+	executable := o.executable(processUUID)
+	if executable == nil {
+		return ResolvedConfiguration{}, ErrExecutableNotFound
+	}
+
+	return executable.resolvedConfiguration(), nil
+}
+
 /*
 Current strategy: Start as many executables as possible. If an executable fails to start, log the error and continue.
+The errors of the executables that failed to start are returned joined, once the others were started.
 Consider changing the strategy to force start all executables. If an executable fails to start, log the error and stop all executables.
 */
 func (o *Orchestrator) RunAll(ctx context.Context) error {
-	if len(o.Executables) == 0 {
-		return errors.New("there are no executables set to run")
+	executables := o.executables()
+	if len(executables) == 0 {
+		err := fmt.Errorf("%w: there are no executables set to run", ErrExecutablesNotSet)
+		o.audit(ctx, ActionRunAll, nil, err)
+		return err
 	}
 
-	for _, executable := range o.Executables {
-		o.startExecutable(executable)
-	}
+	unscheduled := executables.unscheduled()
+	err := o.startExecutables(unscheduled)
+	o.audit(ctx, ActionRunAll, unscheduled, err)
 
-	return nil
+	return err
 }
 
 func (o *Orchestrator) RunGroup(ctx context.Context, group string) error {
 	executablesGroup := Executables{}
 
-	for _, executable := range o.Executables {
+	for _, executable := range o.executables() {
 		if executable.Group == group {
 			executablesGroup = append(executablesGroup, executable)
 		}
 	}
 
 	if len(executablesGroup) == 0 {
-		return errors.New("no executables found in group")
+		err := ErrGroupNotFound
+		o.audit(ctx, ActionRunGroup, nil, err)
+		return err
 	}
 
-	for _, executable := range executablesGroup {
-		o.startExecutable(executable)
-	}
+	executablesGroup = executablesGroup.unscheduled()
+	err := o.startExecutables(executablesGroup)
+	o.audit(ctx, ActionRunGroup, executablesGroup, err)
 
-	return nil
+	return err
 }
 
 func (o *Orchestrator) Run(ctx context.Context, processUUID uuid.UUID) error {
 	var executable *Executable
 
-	for _, exec := range o.Executables {
+	for _, exec := range o.executables() {
 		if exec.ID == processUUID {
 			executable = exec
 			break
@@ -195,26 +466,42 @@ func (o *Orchestrator) Run(ctx context.Context, processUUID uuid.UUID) error {
 	}
 
 	if executable == nil {
-		return errors.New("executable not found")
+		err := ErrExecutableNotFound
+		o.audit(ctx, ActionRun, nil, err)
+		return err
 	}
 
-	o.startExecutable(executable)
+	var err error
+	if executable.Schedule != "" {
+		err = o.runJob(executable)
+	} else {
+		err = o.startExecutable(executable)
+	}
+	o.audit(ctx, ActionRun, Executables{executable}, err)
+	if err != nil {
+		return fmt.Errorf("error starting executable %s: %w", executable.Name, err)
+	}
 
 	return nil
-
 }
 
 func (o *Orchestrator) StopAll(ctx context.Context) error {
-	if len(o.Executables) == 0 {
-		return errors.New("no executables to stop")
+	executables := o.executables()
+	if len(executables) == 0 {
+		err := fmt.Errorf("%w: no executables to stop", ErrExecutablesNotSet)
+		o.audit(ctx, ActionStopAll, nil, err)
+		return err
 	}
 
-	for _, executable := range o.Executables {
-		err := executable.stop()
+	var errs []error
+	for _, executable := range executables {
+		err := o.stopExecutable(executable)
 		if err != nil {
 			o.Logger.Printf(logger.LogErr+"Error stopping executable %s: %s", executable.Name, err.Error())
 		}
+		errs = append(errs, err)
 	}
+	o.audit(ctx, ActionStopAll, executables, errors.Join(errs...))
 
 	return nil
 }
@@ -222,22 +509,27 @@ func (o *Orchestrator) StopAll(ctx context.Context) error {
 func (o *Orchestrator) StopGroup(ctx context.Context, group string) error {
 	executablesGroup := Executables{}
 
-	for _, executable := range o.Executables {
+	for _, executable := range o.executables() {
 		if executable.Group == group {
 			executablesGroup = append(executablesGroup, executable)
 		}
 	}
 
 	if len(executablesGroup) == 0 {
-		return errors.New("no executables found in group")
+		err := ErrGroupNotFound
+		o.audit(ctx, ActionStopGroup, nil, err)
+		return err
 	}
 
+	var errs []error
 	for _, executable := range executablesGroup {
-		err := executable.stop()
+		err := o.stopExecutable(executable)
 		if err != nil {
 			o.Logger.Printf(logger.LogErr+"Error stopping executable %s: %s", executable.Name, err.Error())
 		}
+		errs = append(errs, err)
 	}
+	o.audit(ctx, ActionStopGroup, executablesGroup, errors.Join(errs...))
 
 	return nil
 }
@@ -245,7 +537,7 @@ func (o *Orchestrator) StopGroup(ctx context.Context, group string) error {
 func (o *Orchestrator) Stop(ctx context.Context, processUUID uuid.UUID) error {
 	var executable *Executable
 
-	for _, exec := range o.Executables {
+	for _, exec := range o.executables() {
 		if exec.ID == processUUID {
 			executable = exec
 			break
@@ -253,21 +545,46 @@ func (o *Orchestrator) Stop(ctx context.Context, processUUID uuid.UUID) error {
 	}
 
 	if executable == nil {
-		return errors.New("executable not found")
+		err := ErrExecutableNotFound
+		o.audit(ctx, ActionStop, nil, err)
+		return err
+	}
+
+	err := o.stopExecutable(executable)
+	o.audit(ctx, ActionStop, Executables{executable}, err)
+	if err != nil {
+		return fmt.Errorf("error stopping executable %s: %w", executable.Name, err)
+	}
+
+	return nil
+}
+
+// Restart stops the executable, waits for it to exit and starts it again, without auto-restart starting it meanwhile.
+func (o *Orchestrator) Restart(ctx context.Context, processUUID uuid.UUID) error {
+	executable := o.executable(processUUID)
+	if executable == nil {
+		o.audit(ctx, ActionRestart, nil, ErrExecutableNotFound)
+		return ErrExecutableNotFound
 	}
 
-	err := executable.stop()
+	err := o.restartExecutable(executable)
+	o.audit(ctx, ActionRestart, Executables{executable}, err)
 	if err != nil {
-		return errors.New("error stopping executable " + executable.Name + ": " + err.Error())
+		return fmt.Errorf("error restarting executable %s: %w", executable.Name, err)
 	}
 
 	return nil
 }
 
+// Subscribe returns the lifecycle events of the executables from now on and a function that cancels the subscription.
+func (o *Orchestrator) Subscribe() (<-chan events.Event, func()) {
+	return o.Events.Subscribe()
+}
+
 func (o *Orchestrator) ExecLogs(ctx context.Context, logsType string, processUUID uuid.UUID, offset int) (string, error) {
 	var executable *Executable
 
-	for _, exec := range o.Executables {
+	for _, exec := range o.executables() {
 		if exec.ID == processUUID {
 			executable = exec
 			break
@@ -275,69 +592,179 @@ func (o *Orchestrator) ExecLogs(ctx context.Context, logsType string, processUUI
 	}
 
 	if executable == nil {
-		return "", errors.New("executable not found")
+		return "", ErrExecutableNotFound
 	}
 
-	var logPrefix string
-	switch logsType {
-	case logger.LogTypeOut:
-		logPrefix = executable.LogFileName
-	case logger.LogTypeError:
-		logPrefix = executable.ErrorFileName
-	default:
-		return "", errors.New("invalid logs type")
+	logs, err := executable.logFiles(logsType)
+	if err != nil {
+		return "", err
 	}
 
-	logsFolder := executable.LogDir
-	files, err := os.ReadDir(logsFolder)
+	if offset >= len(logs) {
+		return "", fmt.Errorf("%w: offset out of range", ErrInvalidArgument)
+	}
+
+	logContent, err := os.ReadFile(logs[offset])
 	if err != nil {
-		return "", errors.New("error reading logs folder: " + err.Error())
+		return "", errors.New("error opening log file: " + err.Error())
 	}
 
-	var logs []string
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".log" && strings.HasPrefix(file.Name(), logPrefix) {
-			logs = append(logs, file.Name())
-		}
+	return string(logContent), nil
+}
+
+func (o *Orchestrator) startExecutable(executable *Executable) error {
+	if executable.Process.running() {
+		o.Logger.Printf(logger.LogInfo+"Executable %s is already running", executable.Name)
+		return ErrExecutableRunning
 	}
 
-	if len(logs) == 0 {
-		return "", errors.New("no logs found")
+	executable.beginTask()
+	err := o.build(executable)
+	if err == nil {
+		err = o.runHook(executable, HookPreStart, 0)
+	}
+	if err == nil {
+		err = executable.start(o.Cgroups)
 	}
+	if err != nil {
+		o.Logger.Printf(logger.LogErr+"Error on trying to start the executable %s : %s", executable.Name, err.Error())
+		o.publish(EventStartFailed, executable, err.Error(), nil)
+		executable.Job.failed(err)
+		executable.failTask(err)
+		return err
+	}
+	o.Logger.Printf(logger.LogInfo+"Executable %s started successfully", executable.Name)
+	o.publish(EventStarted, executable, "", map[string]string{"pid": strconv.Itoa(executable.PID)})
+	executable.Job.started(executable.PID)
+	o.watchTask(executable)
 
-	sort.Slice(logs, func(i, j int) bool {
-		return logs[i] > logs[j]
-	})
+	go o.runHook(executable, HookPostStart, executable.PID)
+	go o.waitExecutable(executable)
 
-	if offset >= len(logs) {
-		return "", errors.New("offset out of range")
+	return nil
+}
+
+// waitExecutable waits for the process of the executable to exit, runs its post_stop hook and notifies the exit.
+func (o *Orchestrator) waitExecutable(executable *Executable) {
+	pid, done := executable.PID, executable.done
+	err := executable.Process.wait()
+	restarting := executable.restarting.Load()
+
+	o.runHook(executable, HookPostStop, pid)
+	close(done)
+
+	o.Notifications <- Notification{Executable: executable, pid: pid, err: err, restarting: restarting}
+}
+
+func (o *Orchestrator) stopExecutable(executable *Executable) error {
+	if executable.cancelRetry() {
+		o.Logger.Printf(logger.LogInfo+"Retry of task %s cancelled", executable.Name)
+		o.publish(EventStopRequested, executable, "", nil)
+		return nil
+	}
+	if !executable.status().Running {
+		return nil
 	}
 
-	offsetLog := logs[offset]
-	filePath := filepath.Join(logsFolder, offsetLog)
+	// A failing pre_stop hook is reported, and the executable is stopped anyway.
+	o.runHook(executable, HookPreStop, executable.PID)
 
-	logContent, err := os.ReadFile(filePath)
+	err := errors.Join(executable.stop(), executable.Job.stop())
 	if err != nil {
-		return "", errors.New("error opening log file: " + err.Error())
+		return err
+	}
+	// A paused process gets the signal once it is resumed.
+	if executable.pause() != nil {
+		if err := o.resumeExecutable(executable); err != nil {
+			return err
+		}
 	}
+	o.publish(EventStopRequested, executable, "", map[string]string{"signal": GracefullExitSignal.String()})
 
-	return string(logContent), nil
+	return nil
 }
 
-func (o *Orchestrator) startExecutable(executable *Executable) {
-	if executable.status().Running {
-		o.Logger.Printf(logger.LogInfo+"Executable %s is already running", executable.Name)
-		return
+/*
+restartExecutable stops and starts the executable. Its exit is not auto-restarted meanwhile. The executable is built
+first, so that it keeps running when its build fails.
+*/
+func (o *Orchestrator) restartExecutable(executable *Executable) error {
+	executable.restarting.Store(true)
+	defer executable.restarting.Store(false)
+
+	if err := o.build(executable); err != nil {
+		return err
 	}
 
-	err := executable.start()
+	err := o.stopExecutable(executable)
 	if err != nil {
-		o.Logger.Printf(logger.LogErr+"Error on trying to start the executable %s : %s", executable.Name, err.Error())
-		return
+		return err
 	}
-	o.Logger.Printf(logger.LogInfo+"Executable %s started successfully", executable.Name)
 
-	go executable.wait(o.Notifications)
+	err = executable.waitStopped(time.Duration(StopTimeoutSeconds) * time.Second)
+	if err != nil {
+		return err
+	}
+
+	return o.startExecutable(executable)
+}
+
+// startExecutables starts as many executables as possible. Executables that are already running are not considered failures.
+func (o *Orchestrator) startExecutables(executables Executables) error {
+	var errs []error
+	for _, executable := range executables {
+		err := o.startExecutable(executable)
+		if err != nil && !errors.Is(err, ErrExecutableRunning) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// unscheduled are the executables that are started on demand, i.e. without a schedule.
+func (o Executables) unscheduled() Executables {
+	unscheduled := make(Executables, 0, len(o))
+	for _, executable := range o {
+		if executable.Schedule == "" {
+			unscheduled = append(unscheduled, executable)
+		}
+	}
+
+	return unscheduled
+}
+
+// executables returns a copy of the executables that are set, which can be iterated while they change.
+func (o *Orchestrator) executables() Executables {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return slices.Clone(o.Executables)
+}
+
+func (o *Orchestrator) executable(processUUID uuid.UUID) *Executable {
+	return o.executables().byID(processUUID)
+}
+
+func (o Executables) byID(processUUID uuid.UUID) *Executable {
+	for _, executable := range o {
+		if executable.ID == processUUID {
+			return executable
+		}
+	}
+
+	return nil
+}
+
+func (o *Orchestrator) audit(ctx context.Context, action string, executables Executables, err error) {
+	targets := make([]audit.Target, 0, len(executables))
+	for _, executable := range executables {
+		targets = append(targets, audit.Target{ID: executable.ID.String(), Name: executable.Name, Source: executable.Source})
+	}
+
+	if auditErr := o.Auditor.Record(ctx, action, targets, err); auditErr != nil {
+		o.Logger.Printf(logger.LogErr+"Error recording audit action %s: %s", action, auditErr.Error())
+	}
 }
 
 func (o *Orchestrator) isErrorGracefull(err error) bool {
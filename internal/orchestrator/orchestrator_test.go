@@ -0,0 +1,36 @@
+package orchestrator
+
+import (
+	"io"
+	"log"
+	"orchestrator/internal/events"
+	"testing"
+)
+
+// newTestOrchestrator returns an orchestrator that logs nowhere, and the events it publishes.
+func newTestOrchestrator(t *testing.T) (*Orchestrator, <-chan events.Event) {
+	t.Helper()
+
+	bus := events.NewBus()
+	published, unsubscribe := bus.Subscribe()
+	t.Cleanup(unsubscribe)
+
+	return &Orchestrator{Logger: log.New(io.Discard, "", 0), Events: bus}, published
+}
+
+// nextEvent returns the next published event of the type, skipping the others.
+func nextEvent(t *testing.T, published <-chan events.Event, eventType string) events.Event {
+	t.Helper()
+
+	for {
+		select {
+		case event := <-published:
+			if event.Type == eventType {
+				return event
+			}
+		default:
+			t.Fatalf("no %s event was published", eventType)
+			return events.Event{}
+		}
+	}
+}
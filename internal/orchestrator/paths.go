@@ -0,0 +1,175 @@
+package orchestrator
+
+import (
+	"errors"
+	"orchestrator/internal/config"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Paths are the absolute paths of an executable, resolved from its configuration.
+type Paths struct {
+	BinaryPath string
+	WorkingDir string
+	LogDir     string
+	Hooks      HookPaths
+	// Actions are the resolved commands of the command actions, by name.
+	Actions       map[string]string
+	ChecksumsFile string
+	SignatureFile string
+	Build         BuildPaths
+	Watch         WatchPaths
+}
+
+/*
+resolvePaths resolves the binary path, working directory, log directory, hook and action commands of the configuration:
+"~" and environment variables such as $HOME are expanded, and relative paths are resolved against the base
+directory, usually the one of the configuration file. A bare command name that is not found there is looked up
+in the PATH.
+*/
+func (o *Executable) resolvePaths(baseDir string) error {
+	var errs []error
+
+	resolve := func(field string, path string) string {
+		if path == "" {
+			return ""
+		}
+
+		expanded, err := expandPath(path)
+		if err != nil {
+			errs = append(errs, &FieldError{Field: field, Message: err.Error()})
+			return ""
+		}
+		if !filepath.IsAbs(expanded) {
+			expanded = filepath.Join(baseDir, expanded)
+		}
+
+		return filepath.Clean(expanded)
+	}
+
+	// Bare command names, e.g. "ls", fall back to the PATH.
+	resolveCommand := func(field string, command string) string {
+		resolved := resolve(field, command)
+		if command == "" || strings.ContainsRune(command, filepath.Separator) || strings.ContainsAny(command, "~$") {
+			return resolved
+		}
+
+		if _, err := os.Stat(resolved); err != nil {
+			if lookedUp, err := exec.LookPath(command); err == nil {
+				if absolute, err := filepath.Abs(lookedUp); err == nil {
+					lookedUp = absolute
+				}
+				return lookedUp
+			}
+		}
+
+		return resolved
+	}
+
+	o.Paths = Paths{
+		BinaryPath: resolveCommand("binary_path", o.BinaryPath),
+		WorkingDir: resolve("working_dir", o.WorkingDir),
+		LogDir:     resolve("log_dir", o.LogDir),
+		// The checksums and the signature are looked up next to the configuration, not in the PATH.
+		ChecksumsFile: resolve("checksums_file", o.ChecksumsFile),
+		SignatureFile: resolve("signature_file", o.SignatureFile),
+	}
+
+	for _, hook := range o.hooks() {
+		*hook.path = resolveCommand("hooks", hook.hook.Command)
+	}
+
+	if o.Build != nil {
+		o.Paths.Build = BuildPaths{
+			Command:    resolveCommand("build", o.Build.Command),
+			WorkingDir: resolve("build", o.Build.WorkingDir),
+			Output:     resolve("build", o.Build.Output),
+		}
+		if o.Paths.Build.WorkingDir == "" {
+			o.Paths.Build.WorkingDir = o.Paths.WorkingDir
+		}
+		for _, input := range o.Build.Inputs {
+			o.Paths.Build.Inputs = append(o.Paths.Build.Inputs, resolve("build", input))
+		}
+	}
+
+	if o.Watch != nil {
+		// The working directory is watched by default.
+		for _, path := range o.Watch.Paths {
+			o.Paths.Watch.Paths = append(o.Paths.Watch.Paths, resolve("watch", path))
+		}
+		if len(o.Watch.Paths) == 0 && o.Paths.WorkingDir != "" {
+			o.Paths.Watch.Paths = []string{o.Paths.WorkingDir}
+		}
+		if o.Watch.Build != nil {
+			o.Paths.Watch.Build = resolveCommand("watch", o.Watch.Build.Command)
+		}
+	}
+
+	for name, action := range o.Actions {
+		if len(action.Command) == 0 {
+			continue
+		}
+		if o.Paths.Actions == nil {
+			o.Paths.Actions = make(map[string]string)
+		}
+		o.Paths.Actions[name] = resolveCommand("actions", action.Command[0])
+	}
+
+	return errors.Join(errs...)
+}
+
+// expandPath expands a leading "~" to the home directory and the environment variables of the path.
+func expandPath(path string) (string, error) {
+	var errs []error
+
+	expanded := os.Expand(path, func(variable string) string {
+		value, ok := os.LookupEnv(variable)
+		if !ok {
+			errs = append(errs, errors.New("environment variable is not set: "+variable))
+		}
+		return value
+	})
+
+	if expanded == "~" || strings.HasPrefix(expanded, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			errs = append(errs, errors.New("cannot expand ~: "+err.Error()))
+		} else {
+			expanded = home + strings.TrimPrefix(expanded, "~")
+		}
+	}
+
+	return expanded, errors.Join(errs...)
+}
+
+// configurationDir is the directory relative paths are resolved against when an executable has no configuration file, e.g. one created through the API.
+func configurationDir() string {
+	path := config.GetConfig().EXECUTABLES_JSON_PATH
+	if !strings.ContainsAny(path, "*?[") {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return absoluteDir(path)
+		}
+	}
+
+	return absoluteDir(filepath.Dir(path))
+}
+
+// sourceDir is the directory relative paths of an executable are resolved against.
+func sourceDir(source string) string {
+	if source == "" {
+		return configurationDir()
+	}
+
+	return absoluteDir(filepath.Dir(source))
+}
+
+func absoluteDir(dir string) string {
+	if absolute, err := filepath.Abs(dir); err == nil {
+		return absolute
+	}
+
+	return dir
+}
@@ -0,0 +1,194 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/internal/logger"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ActionPause       = "pause"
+	ActionPauseGroup  = "pause_group"
+	ActionResume      = "resume"
+	ActionResumeGroup = "resume_group"
+)
+
+var (
+	EventPaused  = "paused"
+	EventResumed = "resumed"
+)
+
+var (
+	StatePaused = "paused"
+)
+
+var (
+	// PauseMethodFreezer freezes the cgroup of the executable.
+	PauseMethodFreezer = "freezer"
+	// PauseMethodSignal sends SIGSTOP and SIGCONT to the process group of the executable.
+	PauseMethodSignal = "signal"
+)
+
+// Pause is how and since when a process is paused.
+type Pause struct {
+	Method string
+	Since  time.Time
+}
+
+/*
+Pause freezes the processes of a running executable, keeping their memory, until it is resumed. The cgroup v2
+freezer is used when the executable was started in a cgroup, otherwise its process group is sent SIGSTOP. A paused
+executable is still running: it is not restarted, and the max runtime of a task does not elapse while it is paused.
+*/
+func (o *Orchestrator) Pause(ctx context.Context, processUUID uuid.UUID) (err error) {
+	executable := o.executable(processUUID)
+	defer func() {
+		o.audit(ctx, ActionPause, Executables{executable}, err)
+	}()
+
+	if executable == nil {
+		return ErrExecutableNotFound
+	}
+
+	return o.pauseExecutable(executable)
+}
+
+// Resume thaws the processes of a paused executable.
+func (o *Orchestrator) Resume(ctx context.Context, processUUID uuid.UUID) (err error) {
+	executable := o.executable(processUUID)
+	defer func() {
+		o.audit(ctx, ActionResume, Executables{executable}, err)
+	}()
+
+	if executable == nil {
+		return ErrExecutableNotFound
+	}
+
+	return o.resumeExecutable(executable)
+}
+
+// PauseGroup pauses the running executables of a group. The executables that are not running or already paused are skipped.
+func (o *Orchestrator) PauseGroup(ctx context.Context, group string) (err error) {
+	executablesGroup, err := o.groupExecutables(group, func(executable *Executable) bool {
+		return executable.Process.running() && executable.pause() == nil
+	})
+	defer func() {
+		o.audit(ctx, ActionPauseGroup, executablesGroup, err)
+	}()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, executable := range executablesGroup {
+		errs = append(errs, o.pauseExecutable(executable))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ResumeGroup resumes the paused executables of a group.
+func (o *Orchestrator) ResumeGroup(ctx context.Context, group string) (err error) {
+	executablesGroup, err := o.groupExecutables(group, func(executable *Executable) bool {
+		return executable.pause() != nil
+	})
+	defer func() {
+		o.audit(ctx, ActionResumeGroup, executablesGroup, err)
+	}()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, executable := range executablesGroup {
+		errs = append(errs, o.resumeExecutable(executable))
+	}
+
+	return errors.Join(errs...)
+}
+
+// groupExecutables returns the executables of a group that match, and ErrGroupNotFound when the group has no executables.
+func (o *Orchestrator) groupExecutables(group string, match func(executable *Executable) bool) (Executables, error) {
+	found := false
+	executablesGroup := Executables{}
+	for _, executable := range o.executables() {
+		if executable.Group != group {
+			continue
+		}
+		found = true
+		if match(executable) {
+			executablesGroup = append(executablesGroup, executable)
+		}
+	}
+
+	if !found {
+		return nil, ErrGroupNotFound
+	}
+
+	return executablesGroup, nil
+}
+
+func (o *Orchestrator) pauseExecutable(executable *Executable) error {
+	if !executable.Process.running() {
+		return fmt.Errorf("%w: %s", ErrExecutableNotRunning, executable.Name)
+	}
+	if executable.pause() != nil {
+		return fmt.Errorf("%w: %s", ErrExecutablePaused, executable.Name)
+	}
+
+	method := PauseMethodSignal
+	if executable.Cgroup != "" {
+		if err := freezeCgroup(executable.Cgroup, true); err != nil {
+			o.Logger.Printf(logger.LogErr+"Freezing executable %s failed, sending SIGSTOP instead: %s", executable.Name, err.Error())
+			_ = freezeCgroup(executable.Cgroup, false)
+		} else {
+			method = PauseMethodFreezer
+		}
+	}
+	if method == PauseMethodSignal {
+		if err := syscall.Kill(-executable.PID, syscall.SIGSTOP); err != nil {
+			return fmt.Errorf("failed to pause executable %s : %w", executable.Name, err)
+		}
+	}
+
+	executable.setPause(&Pause{Method: method, Since: time.Now()})
+	executable.suspendTask()
+
+	o.Logger.Printf(logger.LogInfo+"Executable %s paused by %s", executable.Name, method)
+	o.publish(EventPaused, executable, "", map[string]string{"method": method})
+
+	return nil
+}
+
+func (o *Orchestrator) resumeExecutable(executable *Executable) error {
+	pause := executable.pause()
+	if pause == nil || !executable.Process.running() {
+		return fmt.Errorf("%w: %s", ErrExecutableNotPaused, executable.Name)
+	}
+
+	var err error
+	switch pause.Method {
+	case PauseMethodFreezer:
+		err = freezeCgroup(executable.Cgroup, false)
+	default:
+		err = syscall.Kill(-executable.PID, syscall.SIGCONT)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resume executable %s : %w", executable.Name, err)
+	}
+
+	executable.setPause(nil)
+	o.resumeTask(executable)
+
+	pausedFor := time.Since(pause.Since)
+	o.Logger.Printf(logger.LogInfo+"Executable %s resumed after %s", executable.Name, pausedFor.Round(time.Second))
+	o.publish(EventResumed, executable, "", map[string]string{"paused_seconds": strconv.Itoa(int(pausedFor.Seconds()))})
+
+	return nil
+}
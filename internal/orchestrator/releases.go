@@ -0,0 +1,305 @@
+package orchestrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"orchestrator/internal/logger"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ReleasesDirName is the store created next to the configuration when no directory is configured.
+	ReleasesDirName = "releases"
+	// ReleasesKept is how many versions of the binary of an executable are kept in the store, the deployed one included.
+	ReleasesKept = 5
+	// ReleasesHistoryLength is how many deployments of an executable are kept in its history.
+	ReleasesHistoryLength = 20
+	releasesHistoryFile   = "history.json"
+)
+
+var (
+	ReleaseOutcomeDeployed   = "deployed"
+	ReleaseOutcomeRolledBack = "rolled_back"
+)
+
+var versionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// Release is the deployment of a version of the binary of an executable.
+type Release struct {
+	Version    string    `json:"version"`
+	SHA256     string    `json:"sha256"`
+	DeployedAt time.Time `json:"deployed_at"`
+	// Outcome is deployed for a version that was kept, rolled_back for one that failed and was replaced by the previous one.
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+type DeploymentStatus struct {
+	// Version is the deployed version.
+	Version    string    `json:"version"`
+	DeployedAt time.Time `json:"deployed_at"`
+	// History are the deployments, most recent first.
+	History []Release `json:"history"`
+}
+
+/*
+Releases is the versioned store of the binaries of the executables: every deployed version of the binary of an
+executable is copied to <dir>/<name>/<version>/, and its deployments are recorded in <dir>/<name>/history.json.
+The executables keep running their binary_path, onto which the deployed version is installed.
+*/
+type Releases struct {
+	dir       string
+	mutex     sync.Mutex
+	histories map[string][]Release
+}
+
+func newReleases(dir string) *Releases {
+	if dir == "" {
+		dir = filepath.Join(configurationDir(), ReleasesDirName)
+	}
+
+	return &Releases{dir: dir, histories: make(map[string][]Release)}
+}
+
+// setupReleases opens the store of the deployed binaries.
+func (o *Orchestrator) setupReleases(dir string) {
+	o.Releases = newReleases(dir)
+	o.Logger.Printf(logger.LogInfo+"Deployed binaries are kept in %s", o.Releases.dir)
+}
+
+// history returns the deployments of an executable, oldest first. The store is locked by the caller.
+func (o *Releases) history(name string) []Release {
+	if history, ok := o.histories[name]; ok {
+		return history
+	}
+
+	var history []Release
+	content, err := os.ReadFile(filepath.Join(o.releaseDir(name), releasesHistoryFile))
+	if err == nil {
+		_ = json.Unmarshal(content, &history)
+	}
+	o.histories[name] = history
+
+	return history
+}
+
+// current returns the deployed release of an executable, false when it was never deployed.
+func (o *Releases) current(name string) (Release, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return currentRelease(o.history(name))
+}
+
+func currentRelease(history []Release) (Release, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Outcome == ReleaseOutcomeDeployed {
+			return history[i], true
+		}
+	}
+
+	return Release{}, false
+}
+
+// status returns the deployed version of an executable and its history, nil when it was never deployed.
+func (o *Releases) status(name string) *DeploymentStatus {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	history := o.history(name)
+	current, ok := currentRelease(history)
+	if !ok {
+		return nil
+	}
+
+	status := &DeploymentStatus{Version: current.Version, DeployedAt: current.DeployedAt, History: make([]Release, 0, len(history))}
+	for i := len(history) - 1; i >= 0; i-- {
+		status.History = append(status.History, history[i])
+	}
+
+	return status
+}
+
+// releaseDir is the directory of an executable in the store, named after its escaped name.
+func (o *Releases) releaseDir(name string) string {
+	escaped := url.PathEscape(name)
+	if strings.HasPrefix(escaped, ".") {
+		escaped = "%2E" + escaped[1:]
+	}
+
+	return filepath.Join(o.dir, escaped)
+}
+
+// statusOf returns the status of an executable with its deployment and its watch.
+func (o *Orchestrator) statusOf(executable *Executable) Status {
+	status := executable.status()
+	status.Deployment = o.Releases.status(executable.Declared.Name)
+	status.Watch = o.watchStatus(executable)
+
+	return status
+}
+
+// binary returns the path of a version of the binary of an executable in the store.
+func (o *Releases) binary(name string, version string, binaryPath string) string {
+	return filepath.Join(o.releaseDir(name), version, filepath.Base(binaryPath))
+}
+
+// stored returns the release of a version in the store, and ErrVersionNotFound when it is not kept.
+func (o *Releases) stored(name string, version string, binaryPath string) (Release, error) {
+	o.mutex.Lock()
+	history := slices.Clone(o.history(name))
+	o.mutex.Unlock()
+
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Version != version {
+			continue
+		}
+		if _, err := os.Stat(o.binary(name, version, binaryPath)); err != nil {
+			break
+		}
+		return Release{Version: version, SHA256: history[i].SHA256}, nil
+	}
+
+	return Release{}, fmt.Errorf("%w: %s of %s", ErrVersionNotFound, version, name)
+}
+
+/*
+add copies a new binary to the store. Without a version, the version is the beginning of its SHA-256. A version that
+is already kept must have the same content.
+*/
+func (o *Releases) add(name string, version string, binaryPath string, content io.Reader) (Release, error) {
+	if version != "" && !versionPattern.MatchString(version) {
+		return Release{}, fmt.Errorf("%w: version must be letters, digits, '.', '_', '+' and '-': %s", ErrInvalidArgument, version)
+	}
+
+	dir := o.releaseDir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Release{}, errors.New("error creating release directory: " + err.Error())
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return Release{}, errors.New("error creating temporary file: " + err.Error())
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Release{}, errors.New("error writing binary: " + err.Error())
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if version == "" {
+		version = sum[:12]
+	}
+
+	if existing, err := o.stored(name, version, binaryPath); err == nil {
+		if existing.SHA256 != sum {
+			return Release{}, fmt.Errorf("%w: version %s of %s is already kept with another binary", ErrInvalidArgument, version, name)
+		}
+		return existing, nil
+	}
+
+	path := o.binary(name, version, binaryPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Release{}, errors.New("error creating release directory: " + err.Error())
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return Release{}, errors.New("error setting file permissions: " + err.Error())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Release{}, errors.New("error storing binary: " + err.Error())
+	}
+
+	return Release{Version: version, SHA256: sum}, nil
+}
+
+// snapshot keeps the binary an executable runs as its first release, so that the first deployment can be rolled back.
+func (o *Releases) snapshot(name string, binaryPath string) (Release, error) {
+	if current, ok := o.current(name); ok {
+		return current, nil
+	}
+
+	file, err := os.Open(binaryPath)
+	if err != nil {
+		return Release{}, errors.New("error opening binary: " + err.Error())
+	}
+	defer file.Close()
+
+	release, err := o.add(name, "", binaryPath, file)
+	if err != nil {
+		return Release{}, err
+	}
+
+	release.Outcome = ReleaseOutcomeDeployed
+	release.DeployedAt = time.Now()
+	if info, err := file.Stat(); err == nil {
+		release.DeployedAt = info.ModTime()
+	}
+
+	return release, o.record(name, release)
+}
+
+// record appends a deployment to the history of an executable and removes the binaries that are no longer kept.
+func (o *Releases) record(name string, release Release) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	history := append(slices.Clone(o.history(name)), release)
+	if len(history) > ReleasesHistoryLength {
+		history = history[len(history)-ReleasesHistoryLength:]
+	}
+
+	content, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return errors.New("error encoding release history: " + err.Error())
+	}
+	if err := writeFileAtomic(filepath.Join(o.releaseDir(name), releasesHistoryFile), content); err != nil {
+		return err
+	}
+	o.histories[name] = history
+
+	// The most recently deployed versions are kept, the one deployed now included.
+	kept := map[string]bool{}
+	for i := len(history) - 1; i >= 0 && len(kept) < ReleasesKept; i-- {
+		if history[i].Outcome == ReleaseOutcomeDeployed {
+			kept[history[i].Version] = true
+		}
+	}
+	entries, err := os.ReadDir(o.releaseDir(name))
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !kept[entry.Name()] {
+			_ = os.RemoveAll(filepath.Join(o.releaseDir(name), entry.Name()))
+		}
+	}
+
+	return nil
+}
+
+// install replaces the binary at target with a version from the store. Running processes keep the previous one.
+func (o *Releases) install(name string, version string, binaryPath string, target string) error {
+	content, err := os.ReadFile(o.binary(name, version, binaryPath))
+	if err != nil {
+		return errors.New("error reading stored binary: " + err.Error())
+	}
+
+	return writeFileAtomic(target, content)
+}
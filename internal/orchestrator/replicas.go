@@ -0,0 +1,288 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/internal/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ActionScale = "scale"
+)
+
+var (
+	EventScaled = "scaled"
+)
+
+/*
+Replicated executables: an entry with "replicas: N" is run as N instances. Every instance is an executable of its
+own, with its own ID, process, log files and restart state. The first instance keeps the name of the entry and the
+next ones are named "<name>-<index>". Declared keeps the configuration of the entry, before its variables are
+interpolated for the instance, so that the instances can be rebuilt and the entry written back once.
+*/
+
+// replicaCount is the number of instances of the configuration.
+func (o Configuration) replicaCount() int {
+	return max(o.Replicas, 1)
+}
+
+func instanceName(name string, index int) string {
+	if index == 0 {
+		return name
+	}
+
+	return name + "-" + strconv.Itoa(index)
+}
+
+/*
+newInstance returns the instance with the index of a declared configuration. Its variables and paths are not resolved
+yet. The instances write to log files of their own, "<log_file_name>-<index>" and "<error_file_name>-<index>", unless
+the names or the log directory vary with the instance already.
+*/
+func newInstance(declared Configuration, index int) *Executable {
+	executable := &Executable{Configuration: declared.clone(), Declared: declared.clone(), Index: index}
+	executable.Name = instanceName(declared.Name, index)
+
+	if index > 0 && !perInstance(declared.LogDir) {
+		if declared.LogFileName != "" && !perInstance(declared.LogFileName) {
+			executable.LogFileName = instanceName(declared.LogFileName, index)
+		}
+		if declared.ErrorFileName != "" && !perInstance(declared.ErrorFileName) {
+			executable.ErrorFileName = instanceName(declared.ErrorFileName, index)
+		}
+	}
+
+	return executable
+}
+
+// perInstance tells whether a field uses ${instance} or the index of the instance, and so differs between instances.
+func perInstance(value string) bool {
+	for _, match := range variablePattern.FindAllStringSubmatch(value, -1) {
+		if !strings.HasPrefix(match[0], "$$") && (match[1] == "instance" || strings.Contains(match[1], "index")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// prepare interpolates the variables of the instance and resolves its paths against the base directory.
+func (o *Executable) prepare(baseDir string) error {
+	return errors.Join(o.interpolate(), o.resolvePaths(baseDir))
+}
+
+// instantiate builds the instances from the index from to the index to of a declared configuration, and validates them.
+func instantiate(declared Configuration, from int, to int, source string) (Executables, error) {
+	instances := make(Executables, 0, to-from)
+	for index := from; index < to; index++ {
+		instance := newInstance(declared, index)
+		instance.Source = source
+
+		err := errors.Join(instance.prepare(sourceDir(source)), instance.validate())
+		if err != nil {
+			return nil, validationReport(instance, err)
+		}
+
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+func (o Configuration) clone() Configuration {
+	if o.Arguments != nil {
+		o.Arguments = append([]string{}, o.Arguments...)
+	}
+	if o.Env != nil {
+		env := make(map[string]string, len(o.Env))
+		for key, value := range o.Env {
+			env[key] = value
+		}
+		o.Env = env
+	}
+	if o.SuccessCodes != nil {
+		o.SuccessCodes = append([]int{}, o.SuccessCodes...)
+	}
+	if o.Hooks != nil {
+		o.Hooks = o.Hooks.clone()
+	}
+	if o.Build != nil {
+		o.Build = o.Build.clone()
+	}
+	if o.Watch != nil {
+		o.Watch = o.Watch.clone()
+	}
+	if o.Actions != nil {
+		actions := make(map[string]Action, len(o.Actions))
+		for name, action := range o.Actions {
+			if action.Command != nil {
+				action.Command = append([]string{}, action.Command...)
+			}
+			actions[name] = action
+		}
+		o.Actions = actions
+	}
+
+	return o
+}
+
+// instances returns the instances of the entry the executable belongs to, by index.
+func (o *Orchestrator) instances(executable *Executable) Executables {
+	instances := make(Executables, 0, executable.Declared.replicaCount())
+	for _, existing := range o.executables() {
+		if existing.Declared.Name == executable.Declared.Name {
+			instances = append(instances, existing)
+		}
+	}
+
+	return instances
+}
+
+/*
+Scale changes the number of instances of the entry the executable belongs to. Scaling down stops the instances with
+the highest indices first, and fails when one of them does not stop in time. The new instances are started when
+one of the existing instances is running, unless they are scheduled.
+*/
+func (o *Orchestrator) Scale(ctx context.Context, processUUID uuid.UUID, replicas int) (statuses []Status, err error) {
+	executable := o.executable(processUUID)
+	var targets Executables
+	defer func() {
+		o.audit(ctx, ActionScale, targets, err)
+	}()
+
+	if executable == nil {
+		return nil, ErrExecutableNotFound
+	}
+	if replicas < 1 {
+		return nil, fmt.Errorf("%w: replicas must be at least 1", ErrInvalidArgument)
+	}
+
+	instances := o.instances(executable)
+	targets = instances
+
+	running := false
+	for _, instance := range instances {
+		running = running || instance.status().Running
+	}
+
+	for i := len(instances) - 1; i >= replicas; i-- {
+		if err := o.stopExecutable(instances[i]); err != nil {
+			return nil, err
+		}
+		if err := instances[i].waitStopped(time.Duration(StopTimeoutSeconds) * time.Second); err != nil {
+			return nil, err
+		}
+	}
+
+	declared := executable.Declared.clone()
+	declared.Replicas = replicas
+
+	var added Executables
+	if replicas > len(instances) {
+		added, err = instantiate(declared, len(instances), replicas, executable.Source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	o.mu.Lock()
+	if o.Executables.byID(executable.ID) == nil {
+		o.mu.Unlock()
+		return nil, ErrExecutableNotFound
+	}
+	for _, instance := range added {
+		if o.Executables.byName(instance.Name) != nil {
+			o.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrExecutableExists, instance.Name)
+		}
+		instance.ID = uuid.New()
+	}
+
+	last := instances[len(instances)-1]
+	result := make(Executables, 0, len(o.Executables)+len(added))
+	for _, existing := range o.Executables {
+		if existing.Declared.Name == declared.Name {
+			if existing.Index >= replicas {
+				continue
+			}
+			existing.Declared.Replicas = replicas
+			existing.Replicas = replicas
+		}
+		result = append(result, existing)
+		if existing == last {
+			result = append(result, added...)
+		}
+	}
+	o.Executables = result
+	o.syncSchedules()
+	o.syncWatches()
+	o.persistEntry(executable.Source, declared.Name, &declared)
+	o.mu.Unlock()
+	targets = append(targets, added...)
+
+	// The runs of scheduled instances are started by the scheduler.
+	if running && declared.Schedule == "" {
+		err = o.startExecutables(added)
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Executable %s scaled from %d to %d replicas", declared.Name, len(instances), replicas)
+	o.publish(EventScaled, executable, "", map[string]string{"replicas": strconv.Itoa(replicas), "previous": strconv.Itoa(len(instances))})
+
+	for _, instance := range o.instances(executable) {
+		statuses = append(statuses, o.statusOf(instance))
+	}
+
+	return statuses, err
+}
+
+/*
+evaluateIndex evaluates a variable that is a sum of integers and the index of the instance, e.g. "8000+index"
+or "index-1". It returns false when the variable is not such an expression.
+*/
+func evaluateIndex(expression string, index int) (string, bool) {
+	expression = strings.ReplaceAll(expression, " ", "")
+	if !strings.Contains(expression, "index") {
+		return "", false
+	}
+
+	total, sign, term := 0, 1, ""
+	apply := func() bool {
+		switch term {
+		case "index":
+			total += sign * index
+		default:
+			value, err := strconv.Atoi(term)
+			if err != nil {
+				return false
+			}
+			total += sign * value
+		}
+		return true
+	}
+
+	for _, character := range expression {
+		if character != '+' && character != '-' {
+			term += string(character)
+			continue
+		}
+		if term == "" || !apply() {
+			return "", false
+		}
+		term = ""
+		sign = 1
+		if character == '-' {
+			sign = -1
+		}
+	}
+	if term == "" || !apply() {
+		return "", false
+	}
+
+	return strconv.Itoa(total), true
+}
@@ -0,0 +1,207 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"orchestrator/internal/logger"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ActionRollingRestart = "rolling_restart"
+)
+
+var (
+	EventRolloutCompleted = "rollout_completed"
+	EventRolloutAborted   = "rollout_aborted"
+)
+
+var (
+	DefaultRolloutMaxUnavailable = 1
+	DefaultRolloutMinUptime      = 10 * time.Second
+)
+
+var (
+	RolloutMemberPending   = "pending"
+	RolloutMemberRestarted = "restarted"
+	RolloutMemberFailed    = "failed"
+	// RolloutMemberSkipped is a member that is not restarted: a task, or an executable that was not running.
+	RolloutMemberSkipped = "skipped"
+)
+
+type RolloutOptions struct {
+	// MaxUnavailable is how many members are restarted at once, 1 by default.
+	MaxUnavailable int
+	// MinUptime is how long the restarted members of a batch must stay up before the next batch, 10s by default.
+	MinUptime time.Duration
+}
+
+type RolloutMember struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// Batch is the 1-based batch of a restarted member, 0 for a skipped one.
+	Batch int    `json:"batch,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type RolloutResult struct {
+	Group string `json:"group"`
+	// Completed tells whether every batch was restarted and stayed up.
+	Completed bool            `json:"completed"`
+	Members   []RolloutMember `json:"members"`
+}
+
+// RolloutError is a rolling restart aborted because a member failed, with its result.
+type RolloutError struct {
+	Result RolloutResult
+	err    error
+}
+
+func (o *RolloutError) Error() string {
+	return fmt.Sprintf("%s: group %s: %s", ErrRolloutFailed.Error(), o.Result.Group, o.err.Error())
+}
+
+func (o *RolloutError) Unwrap() error {
+	return ErrRolloutFailed
+}
+
+func (o RolloutOptions) withDefaults() (RolloutOptions, error) {
+	if o.MaxUnavailable < 0 {
+		return o, fmt.Errorf("%w: max_unavailable must be positive: %d", ErrInvalidArgument, o.MaxUnavailable)
+	}
+	if o.MinUptime < 0 {
+		return o, fmt.Errorf("%w: min_uptime must be positive: %s", ErrInvalidArgument, o.MinUptime)
+	}
+	if o.MaxUnavailable == 0 {
+		o.MaxUnavailable = DefaultRolloutMaxUnavailable
+	}
+	if o.MinUptime == 0 {
+		o.MinUptime = DefaultRolloutMinUptime
+	}
+
+	return o, nil
+}
+
+/*
+RollingRestart restarts the running services of a group in batches of MaxUnavailable, so that the rest of the group
+keeps serving. The next batch only starts once every member of the current one stayed up for MinUptime. When a member
+fails to restart or exits during that time, the remaining batches are not restarted and a RolloutError with the state
+of every member is returned. Tasks and executables that are not running are skipped.
+*/
+func (o *Orchestrator) RollingRestart(ctx context.Context, group string, options RolloutOptions) (result RolloutResult, err error) {
+	executablesGroup, err := o.groupExecutables(group, func(executable *Executable) bool {
+		return !executable.isTask() && executable.Process.running()
+	})
+	defer func() {
+		o.audit(ctx, ActionRollingRestart, executablesGroup, err)
+	}()
+	if err != nil {
+		return RolloutResult{}, err
+	}
+
+	options, err = options.withDefaults()
+	if err != nil {
+		return RolloutResult{}, err
+	}
+
+	if _, running := o.rollouts.LoadOrStore(group, struct{}{}); running {
+		return RolloutResult{}, fmt.Errorf("%w: %s", ErrRolloutInProgress, group)
+	}
+	defer o.rollouts.Delete(group)
+
+	result = RolloutResult{Group: group, Completed: true}
+	members := map[*Executable]*RolloutMember{}
+	for _, executable := range o.executables() {
+		if executable.Group != group {
+			continue
+		}
+		result.Members = append(result.Members, RolloutMember{ID: executable.ID.String(), Name: executable.Name, State: RolloutMemberSkipped})
+	}
+	for i, executable := range executablesGroup {
+		for j := range result.Members {
+			if result.Members[j].ID == executable.ID.String() {
+				result.Members[j].State = RolloutMemberPending
+				result.Members[j].Batch = i/options.MaxUnavailable + 1
+				members[executable] = &result.Members[j]
+			}
+		}
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Rolling restart of group %s: %d executables, %d at once", group, len(executablesGroup), options.MaxUnavailable)
+	for start := 0; start < len(executablesGroup); start += options.MaxUnavailable {
+		batch := executablesGroup[start:min(start+options.MaxUnavailable, len(executablesGroup))]
+
+		failure := o.restartBatch(ctx, batch, members, options.MinUptime)
+		if failure != nil {
+			result.Completed = false
+			o.Logger.Printf(logger.LogErr+"Rolling restart of group %s aborted: %s", group, failure.Error())
+			o.publish(EventRolloutAborted, nil, failure.Error(), map[string]string{"group": group, "batch": strconv.Itoa(start/options.MaxUnavailable + 1)})
+			return result, &RolloutError{Result: result, err: failure}
+		}
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Rolling restart of group %s completed", group)
+	o.publish(EventRolloutCompleted, nil, "", map[string]string{"group": group, "executables": strconv.Itoa(len(executablesGroup))})
+
+	return result, nil
+}
+
+// restartBatch restarts the executables of a batch at once and waits for them to stay up for minUptime.
+func (o *Orchestrator) restartBatch(ctx context.Context, batch Executables, members map[*Executable]*RolloutMember, minUptime time.Duration) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(batch))
+	done := make([]chan struct{}, len(batch))
+	for i, executable := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = o.restartExecutable(executable)
+			done[i] = executable.done
+		}()
+	}
+	wg.Wait()
+
+	var failure error
+	fail := func(executable *Executable, err error) {
+		members[executable].State = RolloutMemberFailed
+		members[executable].Error = err.Error()
+		if failure == nil {
+			failure = fmt.Errorf("%s: %w", executable.Name, err)
+		}
+	}
+
+	gate := time.NewTimer(minUptime)
+	defer gate.Stop()
+	passed := false
+	for i, executable := range batch {
+		if errs[i] != nil {
+			fail(executable, errs[i])
+			continue
+		}
+
+		// Once the gate passed, the other members only need to still be up.
+		if !passed {
+			select {
+			case <-done[i]:
+				fail(executable, fmt.Errorf("exited within %s of its restart", minUptime))
+				continue
+			case <-gate.C:
+				passed = true
+			case <-ctx.Done():
+				fail(executable, ctx.Err())
+				continue
+			}
+		}
+		select {
+		case <-done[i]:
+			fail(executable, fmt.Errorf("exited within %s of its restart", minUptime))
+		default:
+			members[executable].State = RolloutMemberRestarted
+		}
+	}
+
+	return failure
+}
@@ -0,0 +1,437 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"orchestrator/internal/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ActionScheduledRun = "scheduled_run"
+)
+
+var (
+	EventRunMissed = "run_missed"
+)
+
+var (
+	// ConcurrencyAllow starts a new run while the previous one is still running.
+	ConcurrencyAllow = "allow"
+	// ConcurrencyForbid skips the run when the previous one is still running, and counts it as missed.
+	ConcurrencyForbid = "forbid"
+	// ConcurrencyReplace stops the previous run and starts a new one.
+	ConcurrencyReplace = "replace"
+)
+
+var (
+	// RunHistorySize is the number of the most recent runs kept for every scheduled executable.
+	RunHistorySize = 10
+	// MissedRunsCounted is the most runs that are counted as missed while the orchestrator was down.
+	MissedRunsCounted = 1000
+)
+
+// scheduleParser accepts the standard five fields, descriptors such as @daily and @every 5m, and a CRON_TZ= prefix.
+var scheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+/*
+Job is the state of a scheduled executable: an executable with a schedule runs to completion on a timer instead of
+being kept running. Its runs are started by the scheduler of the orchestrator, or on demand by starting the
+executable, and their outcome is kept in a short history.
+*/
+type Job struct {
+	mu         sync.Mutex
+	entry      cron.EntryID
+	spec       string
+	schedule   cron.Schedule
+	runs       []Run
+	missedRuns int
+	lastMissed time.Time
+	// processes are the runs started next to the process of the executable with the allow concurrency policy.
+	processes []*Process
+}
+
+// Run is a run of a scheduled executable. A run that failed to start has no PID and an error.
+type Run struct {
+	PID        int        `json:"pid,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ExitCode   *int       `json:"exit_code,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type ScheduleStatus struct {
+	Expression        string     `json:"expression"`
+	Timezone          string     `json:"timezone,omitempty"`
+	ConcurrencyPolicy string     `json:"concurrency_policy"`
+	NextRunAt         *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt         *time.Time `json:"last_run_at,omitempty"`
+	MissedRuns        int        `json:"missed_runs"`
+	LastMissedAt      *time.Time `json:"last_missed_at,omitempty"`
+	Runs              []Run      `json:"runs"`
+}
+
+// parseSchedule parses the schedule of the configuration in its timezone, the local one by default.
+func (o Configuration) parseSchedule() (cron.Schedule, error) {
+	return scheduleParser.Parse(o.scheduleSpec())
+}
+
+func (o Configuration) scheduleSpec() string {
+	if o.Schedule == "" || o.Timezone == "" {
+		return o.Schedule
+	}
+
+	return "CRON_TZ=" + o.Timezone + " " + o.Schedule
+}
+
+func (o Configuration) concurrencyPolicy() string {
+	if o.ConcurrencyPolicy == "" {
+		return ConcurrencyForbid
+	}
+
+	return o.ConcurrencyPolicy
+}
+
+func (o Configuration) startingDeadline() time.Duration {
+	deadline, _ := time.ParseDuration(o.StartingDeadline)
+
+	return deadline
+}
+
+/*
+lastRunPath is the file in the log directory that keeps the time of the last scheduled run, across restarts. It is
+named after the executable, since executables that share a log directory usually share their log file name too.
+*/
+func (o *Executable) lastRunPath() string {
+	return filepath.Join(o.Paths.LogDir, "."+strings.ReplaceAll(o.Name, string(filepath.Separator), "_")+".last-run")
+}
+
+/*
+syncSchedules brings the scheduler in line with the executables: executables that are no longer set, or whose
+schedule changed, are removed from it, and scheduled executables that are not in it yet are added.
+It is called after every change of the executables, with the write lock held.
+*/
+func (o *Orchestrator) syncSchedules() {
+	current := make(map[*Executable]bool, len(o.Executables))
+	for _, executable := range o.Executables {
+		current[executable] = true
+	}
+
+	for _, executable := range o.scheduled {
+		if !current[executable] || executable.Job.spec != executable.scheduleSpec() {
+			o.Scheduler.Remove(executable.Job.entry)
+			executable.Job.entry = 0
+		}
+	}
+
+	o.scheduled = make(Executables, 0, len(o.scheduled))
+	for _, executable := range o.Executables {
+		if executable.Schedule == "" {
+			continue
+		}
+		if executable.Job == nil {
+			executable.Job = &Job{}
+		}
+
+		if executable.Job.entry == 0 {
+			schedule, err := executable.parseSchedule()
+			if err != nil {
+				o.Logger.Printf(logger.LogErr+"Error scheduling executable %s: %s", executable.Name, err.Error())
+				continue
+			}
+
+			executable.Job.schedule = schedule
+			executable.Job.spec = executable.scheduleSpec()
+			executable.Job.entry = o.Scheduler.Schedule(schedule, cron.FuncJob(func() { o.runScheduled(executable) }))
+			o.catchUp(executable)
+		}
+		o.scheduled = append(o.scheduled, executable)
+	}
+}
+
+func (o *Orchestrator) runScheduled(executable *Executable) {
+	if o.executable(executable.ID) == nil {
+		return
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Executable %s is due by its schedule", executable.Name)
+	if err := os.WriteFile(executable.lastRunPath(), []byte(time.Now().Format(time.RFC3339Nano)), 0644); err != nil {
+		o.Logger.Printf(logger.LogErr+"Error recording the run of executable %s: %s", executable.Name, err.Error())
+	}
+	err := o.runJob(executable)
+	o.audit(context.Background(), ActionScheduledRun, Executables{executable}, err)
+}
+
+/*
+catchUp handles the runs that were due since the last scheduled run, while the orchestrator was down or the executable
+was not set. They are counted as missed, except the most recent one, which is started now when it was due within the
+starting deadline. Nothing was missed when the executable never ran on its schedule.
+*/
+func (o *Orchestrator) catchUp(executable *Executable) {
+	data, err := os.ReadFile(executable.lastRunPath())
+	if err != nil {
+		return
+	}
+	lastRun, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	schedule := executable.Job.schedule
+	missed := 0
+	for next := schedule.Next(lastRun); !next.IsZero() && !next.After(now) && missed < MissedRunsCounted; next = schedule.Next(next) {
+		missed++
+	}
+	if missed == 0 {
+		return
+	}
+
+	if deadline := executable.startingDeadline(); deadline > 0 && !schedule.Next(maxTime(lastRun, now.Add(-deadline))).After(now) {
+		o.Logger.Printf(logger.LogInfo+"Executable %s missed its run within its starting deadline, running it now", executable.Name)
+		missed--
+		go o.runScheduled(executable)
+	}
+	if missed == 0 {
+		return
+	}
+
+	o.Logger.Printf(logger.LogInfo+"Executable %s missed %d runs since its last run at %s", executable.Name, missed, lastRun.Format(time.RFC3339))
+	total := executable.Job.missed(missed)
+	o.publish(EventRunMissed, executable, "", map[string]string{"missed_runs": strconv.Itoa(total), "since": lastRun.Format(time.RFC3339)})
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+/*
+runJob starts a run of a scheduled executable according to its concurrency policy. With forbid, a run that is due
+while the previous one is still running is skipped and counted as missed.
+*/
+func (o *Orchestrator) runJob(executable *Executable) error {
+	if !executable.status().Running {
+		return o.startExecutable(executable)
+	}
+
+	switch executable.concurrencyPolicy() {
+	case ConcurrencyAllow:
+		return o.startOverlapping(executable)
+	case ConcurrencyReplace:
+		o.Logger.Printf(logger.LogInfo+"Executable %s is still running, replacing its run", executable.Name)
+		if err := o.stopExecutable(executable); err != nil {
+			return err
+		}
+		if err := executable.waitStopped(time.Duration(StopTimeoutSeconds) * time.Second); err != nil {
+			return err
+		}
+		return o.startExecutable(executable)
+	default:
+		o.Logger.Printf(logger.LogInfo+"Executable %s is still running, its run is missed", executable.Name)
+		missed := executable.Job.missed(1)
+		o.publish(EventRunMissed, executable, "", map[string]string{"missed_runs": strconv.Itoa(missed)})
+		return nil
+	}
+}
+
+// startOverlapping starts a run next to the one that is still running.
+func (o *Orchestrator) startOverlapping(executable *Executable) error {
+	if !executable.Process.running() {
+		return o.startExecutable(executable)
+	}
+
+	process, err := executable.spawn(o.Cgroups)
+	if err != nil {
+		o.Logger.Printf(logger.LogErr+"Error on trying to start the executable %s : %s", executable.Name, err.Error())
+		o.publish(EventStartFailed, executable, err.Error(), nil)
+		executable.Job.failed(err)
+		return err
+	}
+	o.Logger.Printf(logger.LogInfo+"Executable %s started successfully next to its previous run", executable.Name)
+	o.publish(EventStarted, executable, "", map[string]string{"pid": strconv.Itoa(process.PID)})
+	executable.Job.track(process)
+	executable.Job.started(process.PID)
+
+	go func() {
+		err := process.wait()
+		close(process.done)
+		executable.Job.release(process)
+		o.Notifications <- Notification{Executable: executable, pid: process.PID, err: err}
+	}()
+
+	return nil
+}
+
+func (o *Job) started(pid int) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.record(Run{PID: pid, StartedAt: time.Now()})
+}
+
+// track keeps a run that is started next to the process of the executable until it exits.
+func (o *Job) track(process *Process) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.processes = append(o.processes, process)
+}
+
+func (o *Job) failed(err error) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	o.record(Run{StartedAt: now, FinishedAt: &now, Error: err.Error()})
+}
+
+// finished records the exit of the run of the process.
+func (o *Job) finished(pid int, err error) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := range o.runs {
+		run := &o.runs[i]
+		if run.PID != pid || run.FinishedAt != nil {
+			continue
+		}
+
+		now := time.Now()
+		run.FinishedAt = &now
+
+		exitCode := 0
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr):
+			exitCode = exitErr.ExitCode()
+			run.Error = err.Error()
+		case err != nil:
+			run.Error = err.Error()
+		}
+		if err == nil || exitErr != nil {
+			run.ExitCode = &exitCode
+		}
+
+		return
+	}
+}
+
+func (o *Job) release(process *Process) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	processes := make([]*Process, 0, len(o.processes))
+	for _, existing := range o.processes {
+		if existing != process {
+			processes = append(processes, existing)
+		}
+	}
+	o.processes = processes
+}
+
+func (o *Job) missed(count int) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.missedRuns += count
+	o.lastMissed = time.Now()
+
+	return o.missedRuns
+}
+
+// overlapping is the number of runs that are running next to the process of the executable.
+func (o *Job) overlapping() int {
+	if o == nil {
+		return 0
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.processes)
+}
+
+// stop sends the graceful exit signal to the runs that are running next to the process of the executable.
+func (o *Job) stop() error {
+	if o == nil {
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var errs []error
+	for _, process := range o.processes {
+		if process.running() {
+			errs = append(errs, process.CMD.Process.Signal(GracefullExitSignal))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// record adds the run to the history, most recent first.
+func (o *Job) record(run Run) {
+	o.runs = append([]Run{run}, o.runs...)
+	if len(o.runs) > RunHistorySize {
+		o.runs = o.runs[:RunHistorySize]
+	}
+}
+
+func (o *Executable) scheduleStatus() *ScheduleStatus {
+	status := &ScheduleStatus{
+		Expression:        o.Schedule,
+		Timezone:          o.Timezone,
+		ConcurrencyPolicy: o.concurrencyPolicy(),
+		Runs:              []Run{},
+	}
+
+	job := o.Job
+	if job == nil {
+		return status
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	if job.entry != 0 {
+		next := job.schedule.Next(time.Now())
+		status.NextRunAt = &next
+	}
+	if len(job.runs) > 0 {
+		lastRun := job.runs[0].StartedAt
+		status.LastRunAt = &lastRun
+	}
+	status.MissedRuns = job.missedRuns
+	if job.missedRuns > 0 {
+		lastMissed := job.lastMissed
+		status.LastMissedAt = &lastMissed
+	}
+	status.Runs = append(status.Runs, job.runs...)
+
+	return status
+}
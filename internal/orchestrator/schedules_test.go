@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// scheduledExecutable returns an executable run every minute, which logs to the directory.
+func scheduledExecutable(t *testing.T, name string, logDir string) *Executable {
+	t.Helper()
+
+	executable := &Executable{Configuration: Configuration{Name: name, Schedule: "@every 1m", LogFileName: "out"}}
+	executable.Paths.LogDir = logDir
+	schedule, err := executable.parseSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	executable.Job = &Job{schedule: schedule}
+
+	return executable
+}
+
+// recordLastRun writes the time of the last scheduled run of the executable.
+func recordLastRun(t *testing.T, executable *Executable, lastRun time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(executable.lastRunPath(), []byte(lastRun.Format(time.RFC3339Nano)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCatchUpCountsMissedRuns(t *testing.T) {
+	tests := []struct {
+		name    string
+		lastRun time.Duration
+		missed  int
+	}{
+		{name: "none due", lastRun: 30 * time.Second, missed: 0},
+		{name: "one due", lastRun: 90 * time.Second, missed: 1},
+		{name: "several due", lastRun: 10*time.Minute + 30*time.Second, missed: 10},
+		{name: "counted up to the limit", lastRun: time.Duration(MissedRunsCounted+500) * time.Minute, missed: MissedRunsCounted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orchestrator, published := newTestOrchestrator(t)
+			executable := scheduledExecutable(t, "nightly", t.TempDir())
+			recordLastRun(t, executable, time.Now().Add(-test.lastRun))
+
+			orchestrator.catchUp(executable)
+
+			if missed := executable.scheduleStatus().MissedRuns; missed != test.missed {
+				t.Errorf("got %d missed runs, want %d", missed, test.missed)
+			}
+			if test.missed > 0 {
+				event := nextEvent(t, published, EventRunMissed)
+				if event.Attributes["missed_runs"] != strconv.Itoa(test.missed) || event.Attributes["since"] == "" {
+					t.Errorf("got attributes %v, want %d missed runs and since when", event.Attributes, test.missed)
+				}
+			}
+		})
+	}
+}
+
+func TestCatchUpWithoutLastRun(t *testing.T) {
+	orchestrator, _ := newTestOrchestrator(t)
+	executable := scheduledExecutable(t, "nightly", t.TempDir())
+
+	orchestrator.catchUp(executable)
+
+	if missed := executable.scheduleStatus().MissedRuns; missed != 0 {
+		t.Errorf("got %d missed runs for an executable that never ran, want 0", missed)
+	}
+}
+
+func TestCatchUpKeepsTheLastRunOfEveryExecutable(t *testing.T) {
+	orchestrator, _ := newTestOrchestrator(t)
+	logDir := t.TempDir()
+	// The executables share their log directory and their log file name.
+	nightly := scheduledExecutable(t, "nightly", logDir)
+	hourly := scheduledExecutable(t, "hourly", logDir)
+	recordLastRun(t, nightly, time.Now().Add(-5*time.Minute-30*time.Second))
+	recordLastRun(t, hourly, time.Now().Add(-30*time.Second))
+
+	orchestrator.catchUp(nightly)
+	orchestrator.catchUp(hourly)
+
+	if missed := nightly.scheduleStatus().MissedRuns; missed != 5 {
+		t.Errorf("nightly missed %d runs, want 5", missed)
+	}
+	if missed := hourly.scheduleStatus().MissedRuns; missed != 0 {
+		t.Errorf("hourly missed %d runs, want 0", missed)
+	}
+}
+
+func TestLastRunPath(t *testing.T) {
+	logDir := t.TempDir()
+	web := scheduledExecutable(t, "web", logDir)
+	worker := scheduledExecutable(t, "team/worker", logDir)
+
+	if web.lastRunPath() == worker.lastRunPath() {
+		t.Errorf("executables of the same log directory share %s", web.lastRunPath())
+	}
+	if want := filepath.Join(logDir, ".team_worker.last-run"); worker.lastRunPath() != want {
+		t.Errorf("got %s, want %s", worker.lastRunPath(), want)
+	}
+}
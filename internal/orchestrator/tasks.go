@@ -0,0 +1,455 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"orchestrator/internal/logger"
+	"os/exec"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	ActionRetry = "retry"
+)
+
+var (
+	EventSucceeded      = "succeeded"
+	EventFailed         = "failed"
+	EventTimedOut       = "timed_out"
+	EventRetryScheduled = "retry_scheduled"
+)
+
+var (
+	// TypeService is kept running, the default.
+	TypeService = "service"
+	// TypeTask is expected to exit, and its exit code tells whether it succeeded.
+	TypeTask = "task"
+)
+
+var (
+	StateRunning   = "running"
+	StateStopped   = "stopped"
+	StatePending   = "pending"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateTimedOut  = "timed_out"
+	StateRetrying  = "retrying"
+)
+
+/*
+Task is the state of the current run of a task. A run is made of attempts: a task that fails or exceeds its max
+runtime is started again after the restart delay, as many times as its retries allow. The run is finished when
+an attempt succeeds, the retries are exhausted or the task is stopped.
+*/
+type Task struct {
+	mu       sync.Mutex
+	state    string
+	attempt  int
+	retry    bool
+	pid      int
+	exitCode *int
+	timedOut bool
+	timer    *time.Timer
+	// remaining is the max runtime left to the attempt when its timer was armed, armedAt.
+	remaining  time.Duration
+	armedAt    time.Time
+	suspended  bool
+	startedAt  time.Time
+	finishedAt time.Time
+	finished   chan struct{}
+}
+
+type TaskStatus struct {
+	State       string     `json:"state"`
+	Attempt     int        `json:"attempt"`
+	MaxAttempts int        `json:"max_attempts"`
+	ExitCode    *int       `json:"exit_code,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// GroupResult is the outcome of the tasks of a group that was run and waited for.
+type GroupResult struct {
+	Group string `json:"group"`
+	// Finished tells whether every task finished before the wait was cancelled.
+	Finished bool `json:"finished"`
+	// Succeeded tells whether every executable started and every task succeeded.
+	Succeeded bool `json:"succeeded"`
+	// Error reports the executables that could not be started. The tasks among them are failed.
+	Error string `json:"error,omitempty"`
+	// States counts the tasks by state.
+	States map[string]int `json:"states"`
+	Tasks  []Status       `json:"tasks"`
+}
+
+func (o Configuration) isTask() bool {
+	return o.Type == TypeTask
+}
+
+func (o Configuration) maxRuntime() time.Duration {
+	maxRuntime, _ := time.ParseDuration(o.MaxRuntime)
+
+	return maxRuntime
+}
+
+func (o Configuration) succeeded(exitCode int) bool {
+	if len(o.SuccessCodes) == 0 {
+		return exitCode == 0
+	}
+
+	return slices.Contains(o.SuccessCodes, exitCode)
+}
+
+// beginTask starts a new run of the task, or its next attempt when it is retried.
+func (o *Executable) beginTask() {
+	if !o.isTask() {
+		return
+	}
+	if o.Task == nil {
+		o.Task = &Task{state: StatePending}
+	}
+
+	o.Task.mu.Lock()
+	defer o.Task.mu.Unlock()
+
+	if !o.Task.retry || o.Task.finished == nil {
+		o.Task.attempt = 0
+		o.Task.finished = make(chan struct{})
+	}
+	o.Task.retry = false
+	o.Task.attempt++
+	o.Task.state = StateRunning
+	o.Task.pid = 0
+	o.Task.exitCode = nil
+	o.Task.timedOut = false
+	o.Task.startedAt = time.Now()
+	o.Task.finishedAt = time.Time{}
+}
+
+// watchTask records the process of the attempt and kills it when it runs longer than the max runtime.
+func (o *Orchestrator) watchTask(executable *Executable) {
+	task := executable.Task
+	if task == nil {
+		return
+	}
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	task.pid = executable.PID
+	task.suspended = false
+
+	maxRuntime := executable.maxRuntime()
+	if maxRuntime == 0 {
+		return
+	}
+
+	task.remaining = maxRuntime
+	o.armTask(executable)
+}
+
+/*
+armTask kills the process group of the attempt once the rest of its max runtime elapsed, so that the children of the
+process do not outlive it. The lock of the task is held.
+*/
+func (o *Orchestrator) armTask(executable *Executable) {
+	task := executable.Task
+	pid := task.pid
+	task.armedAt = time.Now()
+	task.timer = time.AfterFunc(task.remaining, func() {
+		task.mu.Lock()
+		if task.pid != pid || task.state != StateRunning {
+			task.mu.Unlock()
+			return
+		}
+		task.timedOut = true
+		task.mu.Unlock()
+
+		o.Logger.Printf(logger.LogErr+"Executable %s exceeded its max runtime of %s, killing it", executable.Name, executable.MaxRuntime)
+		if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil {
+			o.Logger.Printf(logger.LogErr+"Error killing executable %s: %s", executable.Name, err.Error())
+		}
+	})
+}
+
+// suspendTask stops the max runtime of the attempt from elapsing while it is paused.
+func (o *Executable) suspendTask() {
+	task := o.Task
+	if task == nil {
+		return
+	}
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	if task.timer != nil && task.state == StateRunning && task.timer.Stop() {
+		task.remaining -= time.Since(task.armedAt)
+		task.suspended = true
+	}
+}
+
+// resumeTask lets the rest of the max runtime of the attempt elapse again.
+func (o *Orchestrator) resumeTask(executable *Executable) {
+	task := executable.Task
+	if task == nil {
+		return
+	}
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	if task.suspended && task.state == StateRunning {
+		task.suspended = false
+		o.armTask(executable)
+	}
+}
+
+// failTask finishes the run of a task that could not be started.
+func (o *Executable) failTask(err error) {
+	if o.Task == nil {
+		return
+	}
+
+	o.Task.mu.Lock()
+	defer o.Task.mu.Unlock()
+
+	o.Task.state = StateFailed
+	o.Task.finishedAt = time.Now()
+	o.Task.finish()
+}
+
+/*
+completeTask decides the outcome of the attempt that exited: it succeeded when its exit code is one of the success
+codes, it timed out when it was killed after its max runtime, it was stopped when it exited on the graceful exit
+signal, and it failed otherwise. It returns whether the task is retried.
+*/
+func (o *Orchestrator) completeTask(executable *Executable, pid int, err error) bool {
+	task := executable.Task
+	if task == nil {
+		return false
+	}
+
+	task.mu.Lock()
+	if task.pid != pid || task.state != StateRunning {
+		task.mu.Unlock()
+		return false
+	}
+
+	if task.timer != nil {
+		task.timer.Stop()
+	}
+	task.finishedAt = time.Now()
+
+	var exitErr *exec.ExitError
+	errors.As(err, &exitErr)
+	signaled := exitErr != nil && exitErr.ExitCode() < 0
+
+	if !signaled {
+		exitCode := 0
+		if exitErr != nil {
+			exitCode = exitErr.ExitCode()
+		}
+		task.exitCode = &exitCode
+	}
+
+	switch {
+	case task.timedOut:
+		task.state = StateTimedOut
+	case err != nil && exitErr == nil:
+		task.state = StateFailed
+	case signaled && exitErr.Sys().(syscall.WaitStatus).Signal() == GracefullExitSignal:
+		task.state = StateStopped
+	case !signaled && executable.succeeded(*task.exitCode):
+		task.state = StateSucceeded
+	default:
+		task.state = StateFailed
+	}
+
+	attributes := map[string]string{"attempt": strconv.Itoa(task.attempt)}
+	if task.exitCode != nil {
+		attributes["exit_code"] = strconv.Itoa(*task.exitCode)
+	}
+
+	state, attempt := task.state, task.attempt
+	retry := (state == StateFailed || state == StateTimedOut) && attempt <= executable.Retries
+	if retry {
+		task.state = StateRetrying
+		task.retry = true
+	} else {
+		task.finish()
+	}
+	task.mu.Unlock()
+
+	switch state {
+	case StateSucceeded:
+		o.Logger.Printf(logger.LogInfo+"Task %s succeeded on attempt %d", executable.Name, attempt)
+		o.publish(EventSucceeded, executable, "", attributes)
+	case StateTimedOut:
+		o.Logger.Printf(logger.LogErr+"Task %s timed out on attempt %d", executable.Name, attempt)
+		o.publish(EventTimedOut, executable, "", attributes)
+	case StateFailed:
+		o.Logger.Printf(logger.LogErr+"Task %s failed on attempt %d", executable.Name, attempt)
+		o.publish(EventFailed, executable, "", attributes)
+	}
+
+	return retry
+}
+
+// retryTask starts the next attempt of a task after the restart delay, unless the task was stopped or started meanwhile.
+func (o *Orchestrator) retryTask(executable *Executable) {
+	o.Logger.Printf(logger.LogInfo+"Sleeping delay before retrying the task: %s", executable.Name)
+	o.publish(EventRetryScheduled, executable, "", map[string]string{"delay_seconds": strconv.Itoa(RestartDelaySeconds)})
+	time.Sleep(time.Duration(RestartDelaySeconds) * time.Second)
+
+	if o.executable(executable.ID) == nil {
+		return
+	}
+
+	task := executable.Task
+	task.mu.Lock()
+	retry := task.retry && task.state == StateRetrying
+	task.mu.Unlock()
+	if !retry {
+		return
+	}
+
+	err := o.startExecutable(executable)
+	o.audit(context.Background(), ActionRetry, Executables{executable}, err)
+}
+
+// cancelRetry stops a task that waits for its next attempt, and finishes its run. It tells whether there was one.
+func (o *Executable) cancelRetry() bool {
+	task := o.Task
+	if task == nil {
+		return false
+	}
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	if !task.retry || task.state != StateRetrying {
+		return false
+	}
+	task.retry = false
+	task.state = StateStopped
+	task.finish()
+
+	return true
+}
+
+// finish marks the run as finished for the ones waiting for it. The lock of the task is held.
+func (o *Task) finish() {
+	if o.finished != nil {
+		select {
+		case <-o.finished:
+		default:
+			close(o.finished)
+		}
+	}
+}
+
+// done returns a channel that is closed when the current run of the task is finished.
+func (o *Task) done() <-chan struct{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.finished == nil {
+		o.finished = make(chan struct{})
+		close(o.finished)
+	}
+
+	return o.finished
+}
+
+func (o *Executable) state() string {
+	if o.pause() != nil && o.Process.running() {
+		return StatePaused
+	}
+	if o.Task != nil {
+		o.Task.mu.Lock()
+		defer o.Task.mu.Unlock()
+		return o.Task.state
+	}
+
+	switch {
+	case o.Process.running():
+		return StateRunning
+	case o.isTask():
+		return StatePending
+	default:
+		return StateStopped
+	}
+}
+
+func (o *Executable) taskStatus() *TaskStatus {
+	status := &TaskStatus{State: StatePending, MaxAttempts: o.Retries + 1}
+	if o.Task == nil {
+		return status
+	}
+
+	o.Task.mu.Lock()
+	defer o.Task.mu.Unlock()
+
+	status.State = o.Task.state
+	status.Attempt = o.Task.attempt
+	status.ExitCode = o.Task.exitCode
+	if !o.Task.startedAt.IsZero() {
+		startedAt := o.Task.startedAt
+		status.StartedAt = &startedAt
+	}
+	if !o.Task.finishedAt.IsZero() {
+		finishedAt := o.Task.finishedAt
+		status.FinishedAt = &finishedAt
+	}
+
+	return status
+}
+
+/*
+RunGroupAndWait starts the executables of a group like RunGroup, then waits until every task of the group has
+finished, or the context is done, and aggregates their outcome. Services of the group are started but not waited for.
+A task that cannot be started is failed, and the others are waited for all the same.
+*/
+func (o *Orchestrator) RunGroupAndWait(ctx context.Context, group string) (GroupResult, error) {
+	startErr := o.RunGroup(ctx, group)
+	if errors.Is(startErr, ErrGroupNotFound) {
+		return GroupResult{}, startErr
+	}
+
+	var tasks Executables
+	for _, executable := range o.executables() {
+		if executable.Group == group && executable.isTask() && executable.Schedule == "" {
+			tasks = append(tasks, executable)
+		}
+	}
+
+	result := GroupResult{Group: group, Finished: true, States: make(map[string]int), Tasks: make([]Status, 0, len(tasks))}
+	for _, task := range tasks {
+		if task.Task == nil {
+			continue
+		}
+
+		select {
+		case <-task.Task.done():
+		case <-ctx.Done():
+			result.Finished = false
+		}
+	}
+
+	result.Succeeded = result.Finished && startErr == nil
+	if startErr != nil {
+		result.Error = startErr.Error()
+	}
+	for _, task := range tasks {
+		status := o.statusOf(task)
+		result.States[status.State]++
+		result.Succeeded = result.Succeeded && status.State == StateSucceeded
+		result.Tasks = append(result.Tasks, status)
+	}
+
+	return result, nil
+}
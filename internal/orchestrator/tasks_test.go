@@ -0,0 +1,156 @@
+package orchestrator
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+// exitError runs a shell command and returns how it exited.
+func exitError(t *testing.T, command string) error {
+	t.Helper()
+
+	err := exec.Command("sh", "-c", command).Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("running %q: %v", command, err)
+	}
+
+	return err
+}
+
+// runningTask returns a task on its first attempt, with the process of the pid.
+func runningTask(configuration Configuration, pid int) *Executable {
+	configuration.Name, configuration.Type = "job", TypeTask
+	executable := &Executable{Configuration: configuration}
+	executable.beginTask()
+	executable.Task.pid = pid
+
+	return executable
+}
+
+func TestCompleteTask(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration Configuration
+		command       string
+		timedOut      bool
+		state         string
+		exitCode      *int
+		retry         bool
+		event         string
+	}{
+		{name: "exit 0", command: "exit 0", state: StateSucceeded, exitCode: intPointer(0), event: EventSucceeded},
+		{name: "exit 3", command: "exit 3", state: StateFailed, exitCode: intPointer(3), event: EventFailed},
+		{name: "success code", configuration: Configuration{SuccessCodes: []int{3}}, command: "exit 3", state: StateSucceeded, exitCode: intPointer(3), event: EventSucceeded},
+		{name: "exit 0 not a success code", configuration: Configuration{SuccessCodes: []int{3}}, command: "exit 0", state: StateFailed, exitCode: intPointer(0), event: EventFailed},
+		{name: "graceful exit signal", command: "kill -TERM $$", state: StateStopped},
+		{name: "killed", command: "kill -KILL $$", state: StateFailed, event: EventFailed},
+		{name: "max runtime", command: "kill -KILL $$", timedOut: true, state: StateTimedOut, event: EventTimedOut},
+		{name: "retried", configuration: Configuration{Retries: 1}, command: "exit 1", state: StateRetrying, exitCode: intPointer(1), retry: true, event: EventFailed},
+		{name: "timed out and retried", configuration: Configuration{Retries: 1}, command: "kill -KILL $$", timedOut: true, state: StateRetrying, retry: true, event: EventTimedOut},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orchestrator, published := newTestOrchestrator(t)
+			executable := runningTask(test.configuration, 42)
+			executable.Task.timedOut = test.timedOut
+
+			retry := orchestrator.completeTask(executable, 42, exitError(t, test.command))
+
+			status := executable.taskStatus()
+			if retry != test.retry || status.State != test.state {
+				t.Errorf("got retry %v and state %s, want %v and %s", retry, status.State, test.retry, test.state)
+			}
+			if (status.ExitCode == nil) != (test.exitCode == nil) || status.ExitCode != nil && *status.ExitCode != *test.exitCode {
+				t.Errorf("got exit code %v, want %v", status.ExitCode, test.exitCode)
+			}
+			if test.event != "" {
+				nextEvent(t, published, test.event)
+			}
+
+			select {
+			case <-executable.Task.done():
+				if test.retry {
+					t.Error("the run is finished while the task is retried")
+				}
+			default:
+				if !test.retry {
+					t.Error("the run is not finished")
+				}
+			}
+		})
+	}
+}
+
+func TestCompleteTaskIgnoresAnotherProcess(t *testing.T) {
+	orchestrator, _ := newTestOrchestrator(t)
+	executable := runningTask(Configuration{}, 42)
+
+	if orchestrator.completeTask(executable, 7, exitError(t, "exit 1")) {
+		t.Error("the exit of another process is retried")
+	}
+	if state := executable.taskStatus().State; state != StateRunning {
+		t.Errorf("got state %s, want %s", state, StateRunning)
+	}
+}
+
+func TestRetriedTaskAttempts(t *testing.T) {
+	orchestrator, _ := newTestOrchestrator(t)
+	executable := runningTask(Configuration{Retries: 1}, 42)
+
+	if !orchestrator.completeTask(executable, 42, exitError(t, "exit 1")) {
+		t.Fatal("the first attempt is not retried")
+	}
+	executable.beginTask()
+	executable.Task.pid = 43
+	if orchestrator.completeTask(executable, 43, exitError(t, "exit 1")) {
+		t.Error("the last attempt is retried")
+	}
+
+	status := executable.taskStatus()
+	if status.State != StateFailed || status.Attempt != 2 || status.MaxAttempts != 2 {
+		t.Errorf("got %+v, want failed on attempt 2 of 2", status)
+	}
+}
+
+func TestCancelRetry(t *testing.T) {
+	orchestrator, _ := newTestOrchestrator(t)
+	executable := runningTask(Configuration{Retries: 3}, 42)
+	orchestrator.completeTask(executable, 42, exitError(t, "exit 1"))
+
+	if !executable.cancelRetry() {
+		t.Fatal("the retry is not cancelled")
+	}
+	if state := executable.taskStatus().State; state != StateStopped {
+		t.Errorf("got state %s, want %s", state, StateStopped)
+	}
+	select {
+	case <-executable.Task.done():
+	default:
+		t.Error("the run is not finished once its retry is cancelled")
+	}
+	if executable.cancelRetry() {
+		t.Error("a task that is not retried is cancelled")
+	}
+}
+
+func TestFailTask(t *testing.T) {
+	executable := runningTask(Configuration{Retries: 1}, 0)
+
+	executable.failTask(errors.New("binary not found"))
+
+	if state := executable.taskStatus().State; state != StateFailed {
+		t.Errorf("got state %s, want %s", state, StateFailed)
+	}
+	select {
+	case <-executable.Task.done():
+	default:
+		t.Error("the run of a task that failed to start is not finished")
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
@@ -0,0 +1,328 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ExtendsField names the template an executable, or another template, is based on.
+	ExtendsField = "extends"
+)
+
+var variablePattern = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// ResolvedConfiguration is the configuration of an executable after its defaults, templates and variables are applied.
+type ResolvedConfiguration struct {
+	ID     string `json:"id,omitempty"`
+	Source string `json:"source,omitempty"`
+	Configuration
+}
+
+/*
+templateResolver merges every executable with the defaults and the chain of templates it extends. A field of an
+executable overrides the one of its template, which overrides the one of the defaults. Arrays such as the arguments
+are concatenated in that order, and objects such as the environment are merged key by key.
+*/
+type templateResolver struct {
+	defaults  *rawExecutable
+	templates map[string]rawExecutable
+}
+
+// newTemplateResolver checks the defaults and templates once, and drops the fields that cannot be merged.
+func newTemplateResolver(document rawDocument) (*templateResolver, []ValidationIssue) {
+	var issues []ValidationIssue
+	resolver := &templateResolver{templates: make(map[string]rawExecutable, len(document.Templates))}
+
+	if document.Defaults != nil {
+		defaults, defaultsIssues := checkLayer(*document.Defaults, "defaults", false)
+		resolver.defaults = &defaults
+		issues = append(issues, defaultsIssues...)
+	}
+
+	for _, template := range document.Templates {
+		label := templateLabel(template.Template)
+		if _, ok := resolver.templates[template.Template]; ok {
+			issues = append(issues, ValidationIssue{Index: -1, Executable: label, Line: template.Position.Line, Column: template.Position.Column, Message: "template is defined more than once"})
+			continue
+		}
+
+		template, templateIssues := checkLayer(template, label, true)
+		resolver.templates[template.Template] = template
+		issues = append(issues, templateIssues...)
+	}
+
+	return resolver, issues
+}
+
+// resolve returns the executable merged with the defaults and its templates.
+func (o *templateResolver) resolve(raw rawExecutable) (rawExecutable, []ValidationIssue) {
+	var issues []ValidationIssue
+	var layers []rawExecutable
+
+	extends, extendsPosition, err := raw.extends()
+	if err != nil {
+		issues = append(issues, ValidationIssue{Index: raw.Index, Field: ExtendsField, Line: extendsPosition.Line, Column: extendsPosition.Column, Message: err.Error()})
+	}
+
+	visited := make(map[string]bool)
+	for extends != "" {
+		template, ok := o.templates[extends]
+		if !ok {
+			issues = append(issues, ValidationIssue{Index: raw.Index, Field: ExtendsField, Line: extendsPosition.Line, Column: extendsPosition.Column, Message: "unknown template: " + extends})
+			break
+		}
+		if visited[extends] {
+			issues = append(issues, ValidationIssue{Index: raw.Index, Field: ExtendsField, Line: extendsPosition.Line, Column: extendsPosition.Column, Message: "templates extend each other in a cycle: " + extends})
+			break
+		}
+		visited[extends] = true
+
+		layers = append([]rawExecutable{template}, layers...)
+		extends, extendsPosition, _ = template.extends()
+	}
+	if o.defaults != nil {
+		layers = append([]rawExecutable{*o.defaults}, layers...)
+	}
+
+	resolved := rawExecutable{Index: raw.Index, Position: raw.Position}
+	merged := make(map[string]int)
+	for _, layer := range append(layers, raw) {
+		seen := make(map[string]bool, len(layer.Fields))
+		for _, field := range layer.Fields {
+			if field.Key == ExtendsField {
+				continue
+			}
+
+			i, ok := merged[field.Key]
+			switch {
+			case !ok, seen[field.Key]:
+				// A key repeated in the same layer is kept, so that decoding reports it.
+				merged[field.Key] = len(resolved.Fields)
+				resolved.Fields = append(resolved.Fields, field)
+			default:
+				resolved.Fields[i] = mergeField(resolved.Fields[i], field)
+			}
+			seen[field.Key] = true
+		}
+	}
+
+	return resolved, issues
+}
+
+// checkLayer reports the problems of defaults or a template and removes the fields that cannot be decoded.
+func checkLayer(layer rawExecutable, label string, extendable bool) (rawExecutable, []ValidationIssue) {
+	var issues []ValidationIssue
+
+	fields := make([]rawField, 0, len(layer.Fields))
+	for _, field := range layer.Fields {
+		if field.Key == "name" || (field.Key == ExtendsField && !extendable) {
+			issues = append(issues, ValidationIssue{Index: -1, Field: field.Key, Line: field.KeyPosition.Line, Column: field.KeyPosition.Column, Message: "field is not allowed in " + label})
+			continue
+		}
+		fields = append(fields, field)
+	}
+	layer.Fields = fields
+
+	if _, _, err := layer.extends(); err != nil {
+		position := layer.fieldPosition(ExtendsField)
+		issues = append(issues, ValidationIssue{Index: -1, Field: ExtendsField, Line: position.Line, Column: position.Column, Message: err.Error()})
+	}
+
+	_, decodeIssues := decodeConfiguration(-1, layer.withoutField(ExtendsField))
+	invalid := make(map[string]bool, len(decodeIssues))
+	for _, issue := range decodeIssues {
+		invalid[issue.Field] = true
+		issues = append(issues, issue)
+	}
+
+	fields = make([]rawField, 0, len(layer.Fields))
+	for _, field := range layer.Fields {
+		if !invalid[field.Key] {
+			fields = append(fields, field)
+		}
+	}
+	layer.Fields = fields
+
+	for i := range issues {
+		issues[i].Executable = label
+	}
+
+	return layer, issues
+}
+
+// mergeField overrides a field with the one of a later layer, concatenating arrays and merging objects.
+func mergeField(base rawField, override rawField) rawField {
+	fieldIndex, ok := configurationFields()[override.Key]
+	if !ok {
+		return override
+	}
+
+	kind := reflect.TypeOf(Configuration{}).FieldByIndex(fieldIndex).Type.Kind()
+	if kind != reflect.Slice && kind != reflect.Map {
+		return override
+	}
+
+	merged := override
+	merged.decode = func(target any) error {
+		if err := base.decode(target); err != nil {
+			return err
+		}
+
+		value := reflect.ValueOf(target).Elem()
+		if kind == reflect.Map {
+			// Decoding into a map that is not empty adds to its keys.
+			return override.decode(target)
+		}
+
+		appended := reflect.New(value.Type())
+		if err := override.decode(appended.Interface()); err != nil {
+			return err
+		}
+		value.Set(reflect.AppendSlice(value, appended.Elem()))
+
+		return nil
+	}
+
+	return merged
+}
+
+// extends returns the template named by the executable, if any.
+func (o rawExecutable) extends() (string, position, error) {
+	for _, field := range o.Fields {
+		if field.Key != ExtendsField {
+			continue
+		}
+
+		var extends string
+		if err := field.decode(&extends); err != nil {
+			return "", field.ValuePosition, errors.New("invalid value, expected a string")
+		}
+		return extends, field.ValuePosition, nil
+	}
+
+	return "", o.Position, nil
+}
+
+func (o rawExecutable) withoutField(key string) rawExecutable {
+	fields := make([]rawField, 0, len(o.Fields))
+	for _, field := range o.Fields {
+		if field.Key != key {
+			fields = append(fields, field)
+		}
+	}
+	o.Fields = fields
+
+	return o
+}
+
+func (o *Executable) resolvedConfiguration() ResolvedConfiguration {
+	resolved := ResolvedConfiguration{Source: o.Source, Configuration: o.Configuration}
+	if o.ID != uuid.Nil {
+		resolved.ID = o.ID.String()
+	}
+
+	return resolved
+}
+
+func templateLabel(name string) string {
+	return "template " + name
+}
+
+/*
+interpolate replaces the variables in the string fields of the configuration: ${name}, ${group}, ${instance}
+and ${index} of a replica, sums with the index such as ${8000+index}, and ${env:VARIABLE} from the environment
+of the orchestrator. "$${" is kept as a literal "${".
+*/
+func (o *Executable) interpolate() error {
+	var errs []error
+
+	name := o.Declared.Name
+	if name == "" {
+		name = o.Name
+	}
+
+	lookup := func(variable string) (string, error) {
+		if value, ok := evaluateIndex(variable, o.Index); ok {
+			return value, nil
+		}
+
+		switch {
+		case variable == "name":
+			return name, nil
+		case variable == "instance":
+			return o.Name, nil
+		case variable == "group":
+			return o.Group, nil
+		case strings.HasPrefix(variable, "env:"):
+			value, ok := os.LookupEnv(strings.TrimPrefix(variable, "env:"))
+			if !ok {
+				return "", errors.New("environment variable is not set: " + strings.TrimPrefix(variable, "env:"))
+			}
+			return value, nil
+		default:
+			return "", errors.New("unknown variable: ${" + variable + "}")
+		}
+	}
+
+	expand := func(field string, value string) string {
+		return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+
+			expanded, err := lookup(match[2 : len(match)-1])
+			if err != nil {
+				errs = append(errs, &FieldError{Field: field, Message: err.Error()})
+				return match
+			}
+			return expanded
+		})
+	}
+
+	// Nested objects such as the hooks are interpolated too, and their problems are reported on the top-level field.
+	var expandValue func(key string, value reflect.Value)
+	expandValue = func(key string, value reflect.Value) {
+		switch value.Kind() {
+		case reflect.String:
+			value.SetString(expand(key, value.String()))
+		case reflect.Slice:
+			for i := 0; i < value.Len(); i++ {
+				expandValue(key, value.Index(i))
+			}
+		case reflect.Map:
+			// Map elements are not addressable, they are expanded in a copy that replaces them.
+			for _, mapKey := range value.MapKeys() {
+				element := reflect.New(value.Type().Elem()).Elem()
+				element.Set(value.MapIndex(mapKey))
+				expandValue(key, element)
+				value.SetMapIndex(mapKey, element)
+			}
+		case reflect.Pointer:
+			if !value.IsNil() {
+				expandValue(key, value.Elem())
+			}
+		case reflect.Struct:
+			for i := 0; i < value.NumField(); i++ {
+				expandValue(key, value.Field(i))
+			}
+		}
+	}
+
+	target := reflect.ValueOf(&o.Configuration).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		key, _, _ := strings.Cut(target.Type().Field(i).Tag.Get("json"), ",")
+		if key == "" || key == "-" || key == "name" || key == "group" {
+			continue
+		}
+
+		expandValue(key, target.Field(i))
+	}
+
+	return errors.Join(errs...)
+}
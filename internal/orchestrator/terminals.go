@@ -0,0 +1,213 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/google/uuid"
+)
+
+var (
+	// TerminalScrollbackBytes is the size of the most recent output that is replayed to a new attachment.
+	TerminalScrollbackBytes = 16 * 1024
+	// TerminalBufferSize is the number of output chunks buffered for an attachment. A viewer that does not keep up loses output.
+	TerminalBufferSize = 256
+	// TerminalDrainTimeout is how long the output of an exited process is read before its terminal is closed.
+	TerminalDrainTimeout = 1 * time.Second
+	DefaultTerminalRows  = 24
+	DefaultTerminalCols  = 80
+	DefaultTerminalType  = "xterm-256color"
+)
+
+/*
+Terminal is the pseudo-terminal of a process started with tty. Its output is recorded to the out log file and
+sent to the attachments. Any number of read-only attachments can watch it, and one interactive attachment can
+also write to it and resize it.
+*/
+type Terminal struct {
+	mu          sync.Mutex
+	pty         *os.File
+	scrollback  []byte
+	attachments map[*Attachment]bool
+	interactive *Attachment
+	closed      bool
+	recorded    chan struct{}
+}
+
+// Attachment is a connection to the terminal of a process. Output is closed when the process exits or the attachment is closed.
+type Attachment struct {
+	terminal *Terminal
+	output   chan []byte
+	Output   <-chan []byte
+	ReadOnly bool
+}
+
+// startTerminal starts the command with a pseudo-terminal as its stdin, stdout and stderr, and records its output to the log file.
+func startTerminal(cmd *exec.Cmd, log io.Writer) (*Terminal, error) {
+	file, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: uint16(DefaultTerminalRows), Cols: uint16(DefaultTerminalCols)})
+	if err != nil {
+		return nil, err
+	}
+
+	terminal := &Terminal{
+		pty:         file,
+		attachments: make(map[*Attachment]bool),
+		recorded:    make(chan struct{}),
+	}
+	go terminal.record(log)
+
+	return terminal, nil
+}
+
+func (o *Terminal) record(log io.Writer) {
+	defer close(o.recorded)
+
+	buffer := make([]byte, LogTailChunkSize)
+	for {
+		n, err := o.pty.Read(buffer)
+		if n > 0 {
+			chunk := append([]byte{}, buffer[:n]...)
+			_, _ = log.Write(chunk)
+			o.broadcast(chunk)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.closed = true
+	for attachment := range o.attachments {
+		close(attachment.output)
+	}
+	o.attachments = nil
+	o.interactive = nil
+}
+
+func (o *Terminal) broadcast(chunk []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.scrollback = append(o.scrollback, chunk...)
+	if len(o.scrollback) > TerminalScrollbackBytes {
+		o.scrollback = append([]byte{}, o.scrollback[len(o.scrollback)-TerminalScrollbackBytes:]...)
+	}
+
+	for attachment := range o.attachments {
+		select {
+		case attachment.output <- chunk:
+		default:
+		}
+	}
+}
+
+// close waits a little for the output of the exited process to be recorded, then closes the terminal.
+func (o *Terminal) close() {
+	select {
+	case <-o.recorded:
+	case <-time.After(TerminalDrainTimeout):
+	}
+
+	o.pty.Close()
+	<-o.recorded
+}
+
+// attach returns a new attachment that first receives the scrollback of the terminal.
+func (o *Terminal) attach(readOnly bool) (*Attachment, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil, ErrExecutableNotRunning
+	}
+	if !readOnly && o.interactive != nil {
+		return nil, ErrTerminalAttached
+	}
+
+	output := make(chan []byte, TerminalBufferSize)
+	attachment := &Attachment{terminal: o, output: output, Output: output, ReadOnly: readOnly}
+	if len(o.scrollback) > 0 {
+		output <- append([]byte{}, o.scrollback...)
+	}
+
+	o.attachments[attachment] = true
+	if !readOnly {
+		o.interactive = attachment
+	}
+
+	return attachment, nil
+}
+
+// Write sends input to the terminal.
+func (o *Attachment) Write(p []byte) (int, error) {
+	if o.ReadOnly {
+		return 0, fmt.Errorf("%w: the attachment is read-only", ErrInvalidArgument)
+	}
+
+	return o.terminal.pty.Write(p)
+}
+
+// Resize changes the size of the terminal.
+func (o *Attachment) Resize(rows int, cols int) error {
+	if o.ReadOnly {
+		return fmt.Errorf("%w: the attachment is read-only", ErrInvalidArgument)
+	}
+	if rows <= 0 || cols <= 0 || rows > 0xffff || cols > 0xffff {
+		return fmt.Errorf("%w: rows and cols must be positive", ErrInvalidArgument)
+	}
+
+	return pty.Setsize(o.terminal.pty, &pty.Winsize{Rows: uint16(rows), Cols: uint16(cols)})
+}
+
+// Close detaches from the terminal. The process keeps running.
+func (o *Attachment) Close() {
+	terminal := o.terminal
+	terminal.mu.Lock()
+	defer terminal.mu.Unlock()
+
+	if !terminal.attachments[o] {
+		return
+	}
+
+	delete(terminal.attachments, o)
+	close(o.output)
+	if terminal.interactive == o {
+		terminal.interactive = nil
+	}
+}
+
+/*
+Attach connects to the terminal of a running executable started with tty. A read-only attachment only receives
+the output, there can be many of them. There is at most one interactive attachment, which can also write input
+and resize the terminal.
+*/
+func (o *Orchestrator) Attach(ctx context.Context, processUUID uuid.UUID, readOnly bool) (*Attachment, error) {
+	executable := o.executable(processUUID)
+	if executable == nil {
+		return nil, ErrExecutableNotFound
+	}
+	if !executable.TTY {
+		return nil, fmt.Errorf("%w: executable %s is not started with tty", ErrInvalidArgument, executable.Name)
+	}
+
+	terminal := executable.Terminal
+	if terminal == nil || !executable.Process.running() {
+		return nil, fmt.Errorf("%w: %s", ErrExecutableNotRunning, executable.Name)
+	}
+
+	attachment, err := terminal.attach(readOnly)
+	if errors.Is(err, ErrExecutableNotRunning) {
+		return nil, fmt.Errorf("%w: %s", ErrExecutableNotRunning, executable.Name)
+	}
+
+	return attachment, err
+}
@@ -0,0 +1,387 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/internal/logger"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ActionWatchRestart = "watch_restart"
+)
+
+var (
+	EventFilesChanged   = "files_changed"
+	EventBuildSucceeded = "build_succeeded"
+	EventBuildFailed    = "build_failed"
+)
+
+var (
+	DefaultWatchDebounce = 500 * time.Millisecond
+	// WatchChangedFilesReported is how many of the changed files are named in the change reason.
+	WatchChangedFilesReported = 10
+	watchBuildName            = "build"
+)
+
+/*
+Watch restarts the running instances of an executable when files change under its paths, for local development.
+Changes are gathered until none happened for the debounce period, then the build command runs, if there is one,
+and the instances are restarted once it succeeded.
+*/
+type Watch struct {
+	// Paths are the files and directories watched, directories recursively. The working directory by default.
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty" toml:"paths,omitempty"`
+	// Include and Exclude are patterns such as *.go matched against file names. Without Include every file counts.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty" toml:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty" toml:"exclude,omitempty"`
+	// Debounce is a duration such as 500ms, 500ms by default.
+	Debounce string `json:"debounce,omitempty" yaml:"debounce,omitempty" toml:"debounce,omitempty"`
+	// Build is a command run in the working directory before the restart, which is skipped when it fails.
+	Build *Hook `json:"build,omitempty" yaml:"build,omitempty" toml:"build,omitempty"`
+}
+
+// WatchPaths are the resolved paths and build command of the watch.
+type WatchPaths struct {
+	Paths []string
+	Build string
+}
+
+type WatchStatus struct {
+	Paths     []string   `json:"paths"`
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+	// Changed are the files of the last change, at most WatchChangedFilesReported of them.
+	Changed []string `json:"changed,omitempty"`
+	// Error is set when the paths cannot be watched, or the last build or restart failed.
+	Error string `json:"error,omitempty"`
+}
+
+/*
+Watcher watches the paths of an executable with inotify. It is shared by the instances of the executable, which
+are restarted together, and is replaced when the watch or the paths of the executable change.
+*/
+type Watcher struct {
+	name  string
+	watch Watch
+	paths Paths
+	fd    int
+	file  *os.File
+	mutex sync.Mutex
+	// dirs are the watched directories by watch descriptor, with the names of the watched files, nil for all of them.
+	dirs      map[int32]*watchedDir
+	changes   chan string
+	changedAt time.Time
+	changed   []string
+	err       error
+	closed    bool
+}
+
+type watchedDir struct {
+	path  string
+	files map[string]bool
+}
+
+func (o Watch) debounce() time.Duration {
+	debounce, err := time.ParseDuration(o.Debounce)
+	if err != nil || debounce <= 0 {
+		return DefaultWatchDebounce
+	}
+
+	return debounce
+}
+
+func (o Watch) clone() *Watch {
+	o.Paths = slices.Clone(o.Paths)
+	o.Include = slices.Clone(o.Include)
+	o.Exclude = slices.Clone(o.Exclude)
+	if o.Build != nil {
+		build := *o.Build
+		build.Arguments = slices.Clone(build.Arguments)
+		o.Build = &build
+	}
+
+	return &o
+}
+
+/*
+syncWatches brings the watchers in line with the executables: the watchers of executables that are no longer set,
+or whose watch or paths changed, are closed, and the executables with a watch that have no watcher get one.
+It is called after every change of the executables, with the write lock held.
+*/
+func (o *Orchestrator) syncWatches() {
+	declared := make(map[string]*Executable)
+	for _, executable := range o.Executables {
+		if _, ok := declared[executable.Declared.Name]; !ok && executable.Watch != nil {
+			declared[executable.Declared.Name] = executable
+		}
+	}
+
+	for name, watcher := range o.watches {
+		executable, ok := declared[name]
+		if ok && reflect.DeepEqual(watcher.watch, *executable.Watch) && reflect.DeepEqual(watcher.paths, executable.Paths) {
+			continue
+		}
+		watcher.close()
+		delete(o.watches, name)
+	}
+
+	for name, executable := range declared {
+		if _, ok := o.watches[name]; ok {
+			continue
+		}
+
+		watcher := newWatcher(name, *executable.Watch.clone(), executable.Paths)
+		if watcher.err != nil {
+			o.Logger.Printf(logger.LogErr+"Error watching the files of executable %s: %s", name, watcher.err.Error())
+		} else {
+			o.Logger.Printf(logger.LogInfo+"Watching the files of executable %s in %s", name, strings.Join(executable.Paths.Watch.Paths, ", "))
+			go o.consumeChanges(watcher)
+		}
+		o.watches[name] = watcher
+	}
+}
+
+/*
+ignored tells whether a changed path does not count: hidden files and directories, such as .git or the swap files of
+editors, the log directory, the binary of the executable and the output of its build, which the builds write, and
+the excluded or not included files.
+*/
+func (o *Watcher) ignored(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") || path == o.paths.BinaryPath || path == o.paths.Build.Output {
+		return true
+	}
+	if o.paths.LogDir != "" && (path == o.paths.LogDir || strings.HasPrefix(path, o.paths.LogDir+string(filepath.Separator))) {
+		return true
+	}
+	for _, pattern := range o.watch.Exclude {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *Watcher) included(path string) bool {
+	if len(o.watch.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range o.watch.Include {
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// consumeChanges gathers the changed files until none changed for the debounce period, then restarts the executable.
+func (o *Orchestrator) consumeChanges(watcher *Watcher) {
+	var changed []string
+	timer := time.NewTimer(watcher.watch.debounce())
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case path, ok := <-watcher.changes:
+			if !ok {
+				return
+			}
+			if !slices.Contains(changed, path) {
+				changed = append(changed, path)
+			}
+			timer.Reset(watcher.watch.debounce())
+		case <-timer.C:
+			if watcher.isClosed() {
+				return
+			}
+			o.filesChanged(watcher, changed)
+			changed = nil
+		}
+	}
+}
+
+/*
+filesChanged runs the build of the watch and the one of the executable, then restarts the running instances of the
+executable. The other instances are left alone and run the change on their next start, unless their last start
+failed because of the build.
+*/
+func (o *Orchestrator) filesChanged(watcher *Watcher, changed []string) {
+	instances := Executables{}
+	for _, executable := range o.executables() {
+		if executable.Declared.Name == watcher.name {
+			instances = append(instances, executable)
+		}
+	}
+	if len(instances) == 0 {
+		return
+	}
+	executable := instances[0]
+	// Instances that failed to start because of their build are started once it is fixed.
+	unbuilt := map[*Executable]bool{}
+	for _, instance := range instances {
+		unbuilt[instance] = instance.buildErr != nil && !instance.Process.running()
+	}
+
+	reason := changeReason(changed)
+	o.Logger.Printf(logger.LogInfo+"Files of executable %s changed: %s", watcher.name, reason)
+	o.publish(EventFilesChanged, executable, reason, map[string]string{"files": strconv.Itoa(len(changed))})
+
+	var err error
+	defer func() {
+		watcher.record(changed, err)
+	}()
+
+	if build := executable.Watch.Build; build != nil {
+		if err = executable.runHook(watchBuildName, *build, executable.Paths.Watch.Build, 0); err != nil {
+			err = fmt.Errorf("%w: %s: %s", ErrBuildFailed, watcher.name, err.Error())
+			o.Logger.Printf(logger.LogErr+"Build of executable %s failed, it is not restarted: %s", watcher.name, err.Error())
+			o.publish(EventBuildFailed, executable, err.Error(), map[string]string{"reason": reason})
+			return
+		}
+		o.Logger.Printf(logger.LogInfo+"Build of executable %s succeeded", watcher.name)
+		o.publish(EventBuildSucceeded, executable, "", map[string]string{"reason": reason})
+	}
+	// The build of the executable runs before the restart as well, so that the running instances are kept when it fails.
+	if err = o.build(executable); err != nil {
+		o.Logger.Printf(logger.LogErr+"Executable %s is not restarted, its build failed", watcher.name)
+		return
+	}
+
+	restarted := Executables{}
+	var errs []error
+	for _, instance := range instances {
+		switch {
+		case instance.Process.running():
+			errs = append(errs, o.restartExecutable(instance))
+		case unbuilt[instance]:
+			errs = append(errs, o.startExecutable(instance))
+		default:
+			continue
+		}
+		restarted = append(restarted, instance)
+	}
+	err = errors.Join(errs...)
+	o.audit(context.Background(), ActionWatchRestart, restarted, err)
+}
+
+// changeReason names the changed files, at most WatchChangedFilesReported of them.
+func changeReason(changed []string) string {
+	files := " files changed: "
+	if len(changed) == 1 {
+		files = " file changed: "
+	}
+	reason := strconv.Itoa(len(changed)) + files + strings.Join(changed[:min(len(changed), WatchChangedFilesReported)], ", ")
+	if len(changed) > WatchChangedFilesReported {
+		reason += ", ..."
+	}
+
+	return reason
+}
+
+func (o *Watcher) record(changed []string, err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.changedAt = time.Now()
+	o.changed = slices.Clone(changed[:min(len(changed), WatchChangedFilesReported)])
+	o.err = err
+}
+
+func (o *Watcher) isClosed() bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.closed
+}
+
+// close stops watching. The debouncing of the changes stops once the reads returned.
+func (o *Watcher) close() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.closed || o.file == nil {
+		o.closed = true
+		return
+	}
+	o.closed = true
+	o.file.Close()
+}
+
+// watchStatus returns the state of the watcher of an executable, nil when it has no watch.
+func (o *Orchestrator) watchStatus(executable *Executable) *WatchStatus {
+	o.mu.RLock()
+	watcher, ok := o.watches[executable.Declared.Name]
+	o.mu.RUnlock()
+	if !ok || executable.Watch == nil {
+		return nil
+	}
+
+	watcher.mutex.Lock()
+	defer watcher.mutex.Unlock()
+
+	status := &WatchStatus{Paths: watcher.paths.Watch.Paths, Changed: watcher.changed}
+	if !watcher.changedAt.IsZero() {
+		status.ChangedAt = &watcher.changedAt
+	}
+	if watcher.err != nil {
+		status.Error = watcher.err.Error()
+	}
+
+	return status
+}
+
+// validateWatch checks the watch of the configuration, with its resolved paths and build command.
+func (o *Executable) validateWatch() []error {
+	if o.Watch == nil {
+		return nil
+	}
+
+	var errs []error
+	invalid := func(message string) {
+		errs = append(errs, &FieldError{Field: "watch", Message: message})
+	}
+
+	if o.Schedule != "" {
+		invalid("watch cannot be used with a schedule: " + o.Name)
+	}
+	for _, path := range o.Paths.Watch.Paths {
+		if _, err := os.Stat(path); err != nil {
+			invalid("error stating watched path: " + path)
+		}
+	}
+	for _, pattern := range slices.Concat(o.Watch.Include, o.Watch.Exclude) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			invalid("invalid pattern: " + pattern)
+		}
+	}
+	if debounce, err := time.ParseDuration(o.Watch.Debounce); o.Watch.Debounce != "" && (err != nil || debounce <= 0) {
+		invalid("debounce must be a positive duration such as 500ms: " + o.Watch.Debounce)
+	}
+
+	if build := o.Watch.Build; build != nil {
+		if build.Command == "" {
+			invalid("build: command is required: " + o.Name)
+		} else if info, err := os.Stat(o.Paths.Watch.Build); err != nil {
+			invalid("build: error stating command: " + build.Command)
+		} else if info.IsDir() || info.Mode()&0111 == 0 {
+			invalid("build: command is not executable: " + build.Command)
+		}
+		if timeout, err := time.ParseDuration(build.Timeout); build.Timeout != "" && (err != nil || timeout <= 0) {
+			invalid("build: timeout must be a positive duration such as 10s: " + build.Timeout)
+		}
+	}
+
+	return errs
+}
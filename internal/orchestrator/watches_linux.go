@@ -0,0 +1,149 @@
+package orchestrator
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// watchMask are the inotify events of a watched directory that change its files.
+var watchMask uint32 = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// newWatcher starts watching the paths of an executable. A watcher that failed has its error and does not watch.
+func newWatcher(name string, watch Watch, paths Paths) *Watcher {
+	watcher := &Watcher{name: name, watch: watch, paths: paths, dirs: make(map[int32]*watchedDir), changes: make(chan string, 64)}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		watcher.err = errors.New("error initializing inotify: " + err.Error())
+		return watcher
+	}
+	// A non-blocking file is read through the poller of the runtime, so that closing it stops the reads.
+	watcher.fd, watcher.file = fd, os.NewFile(uintptr(fd), "inotify")
+
+	for _, path := range paths.Watch.Paths {
+		if err := watcher.add(path); err != nil {
+			watcher.file.Close()
+			watcher.err = err
+			return watcher
+		}
+	}
+
+	go watcher.read()
+
+	return watcher
+}
+
+// add watches a file, through its directory, or a directory and its subdirectories.
+func (o *Watcher) add(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.New("error stating watched path: " + err.Error())
+	}
+	if !info.IsDir() {
+		return o.addDir(filepath.Dir(path), filepath.Base(path))
+	}
+
+	return filepath.WalkDir(path, func(dir string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if dir != path && o.ignored(dir) {
+			return filepath.SkipDir
+		}
+
+		return o.addDir(dir, "")
+	})
+}
+
+// addDir watches a directory for one of its files, or all of them when file is empty.
+func (o *Watcher) addDir(dir string, file string) error {
+	wd, err := syscall.InotifyAddWatch(o.fd, dir, watchMask)
+	if err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			return errors.New("too many watched directories, raise fs.inotify.max_user_watches: " + dir)
+		}
+		return errors.New("error watching " + dir + ": " + err.Error())
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	watched, ok := o.dirs[int32(wd)]
+	switch {
+	case !ok:
+		watched = &watchedDir{path: dir}
+		if file != "" {
+			watched.files = map[string]bool{file: true}
+		}
+		o.dirs[int32(wd)] = watched
+	case file == "":
+		watched.files = nil
+	case watched.files != nil:
+		watched.files[file] = true
+	}
+
+	return nil
+}
+
+// read decodes the inotify events until the watcher is closed, and sends the changed files to be debounced.
+func (o *Watcher) read() {
+	defer close(o.changes)
+
+	buffer := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := o.file.Read(buffer)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			wd := int32(binary.NativeEndian.Uint32(buffer[offset:]))
+			mask := binary.NativeEndian.Uint32(buffer[offset+4:])
+			length := int(binary.NativeEndian.Uint32(buffer[offset+12:]))
+			name := strings.TrimRight(string(buffer[offset+syscall.SizeofInotifyEvent:offset+syscall.SizeofInotifyEvent+length]), "\x00")
+			offset += syscall.SizeofInotifyEvent + length
+
+			if path, ok := o.event(wd, mask, name); ok {
+				o.changes <- path
+			}
+		}
+	}
+}
+
+// event returns the changed file of an event, if it counts, and watches the directories created in watched ones.
+func (o *Watcher) event(wd int32, mask uint32, name string) (string, bool) {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		return "(too many changes)", true
+	}
+
+	o.mutex.Lock()
+	watched, ok := o.dirs[wd]
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(o.dirs, wd)
+	}
+	o.mutex.Unlock()
+	if !ok || name == "" || (watched.files != nil && !watched.files[name]) {
+		return "", false
+	}
+
+	path := filepath.Join(watched.path, name)
+	if o.ignored(path) {
+		return "", false
+	}
+	if mask&syscall.IN_ISDIR != 0 {
+		if mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 && watched.files == nil {
+			_ = o.add(path)
+		}
+		return "", false
+	}
+	if !o.included(path) {
+		return "", false
+	}
+
+	return path, true
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package orchestrator
+
+import "errors"
+
+// newWatcher returns a watcher with its error, files are only watched with inotify.
+func newWatcher(name string, watch Watch, paths Paths) *Watcher {
+	return &Watcher{name: name, watch: watch, paths: paths, err: errors.New("watch mode requires linux")}
+}
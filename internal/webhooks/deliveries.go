@@ -0,0 +1,327 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"orchestrator/internal/events"
+	"orchestrator/internal/logger"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+var (
+	EventPing = "ping"
+)
+
+var (
+	HeaderEvent     = "X-Orchestrator-Event"
+	HeaderDelivery  = "X-Orchestrator-Delivery"
+	HeaderSignature = "X-Orchestrator-Signature"
+	HeaderTimestamp = "X-Orchestrator-Timestamp"
+)
+
+var (
+	DeliveryTimeout        = 10 * time.Second
+	DeliveryMaxAttempts    = 10
+	DeliveryInitialBackoff = 1 * time.Second
+	DeliveryMaxBackoff     = 5 * time.Minute
+	// DeliveryHistorySize is the number of finished deliveries that are kept, besides the pending ones.
+	DeliveryHistorySize  = 1000
+	ResponseExcerptBytes = 512
+)
+
+/*
+Delivery is an event to deliver to a webhook. A delivery is attempted until the endpoint answers with a 2xx status,
+waiting twice as long after every failed attempt, and fails after DeliveryMaxAttempts attempts.
+*/
+type Delivery struct {
+	ID             string       `json:"id"`
+	WebhookID      string       `json:"webhook_id"`
+	Event          events.Event `json:"event"`
+	Status         string       `json:"status"`
+	Attempts       int          `json:"attempts"`
+	CreatedAt      time.Time    `json:"created_at"`
+	LastAttemptAt  *time.Time   `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time   `json:"next_attempt_at,omitempty"`
+	ResponseStatus int          `json:"response_status,omitempty"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// Deliveries returns the deliveries of a webhook, the most recent first, optionally of a status only and at most limit of them.
+func (o *Dispatcher) Deliveries(id string, status string, limit int) ([]Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.index(id) < 0 {
+		return nil, ErrWebhookNotFound
+	}
+
+	deliveries := make([]Delivery, 0)
+	for i := len(o.deliveries) - 1; i >= 0; i-- {
+		if limit > 0 && len(deliveries) >= limit {
+			break
+		}
+
+		delivery := o.deliveries[i]
+		if delivery.WebhookID == id && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	return deliveries, nil
+}
+
+// enqueue adds a delivery of the event to the webhook. The lock of the dispatcher is held.
+func (o *Dispatcher) enqueue(webhook Webhook, event events.Event) Delivery {
+	now := time.Now().UTC()
+	delivery := Delivery{
+		ID:            uuid.New().String(),
+		WebhookID:     webhook.ID,
+		Event:         event,
+		Status:        DeliveryPending,
+		CreatedAt:     now,
+		NextAttemptAt: &now,
+	}
+	o.deliveries = append(o.deliveries, delivery)
+	o.prune()
+
+	return delivery
+}
+
+// prune drops the oldest finished deliveries beyond the history size.
+func (o *Dispatcher) prune() {
+	finished := 0
+	for _, delivery := range o.deliveries {
+		if delivery.Status != DeliveryPending {
+			finished++
+		}
+	}
+	if finished <= DeliveryHistorySize {
+		return
+	}
+
+	deliveries := make([]Delivery, 0, len(o.deliveries))
+	for _, delivery := range o.deliveries {
+		if delivery.Status != DeliveryPending && finished > DeliveryHistorySize {
+			finished--
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	o.deliveries = deliveries
+}
+
+// deliver attempts the deliveries of a webhook that are due, then sleeps until the next one is due or a new one is queued.
+func (o *Dispatcher) deliver(id string, worker *worker) {
+	defer o.running.Done()
+
+	for {
+		next := o.attemptDue(id, worker)
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			due = timer.C
+		}
+
+		select {
+		case <-o.stop:
+		case <-worker.stop:
+		case <-worker.wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if worker.stopped(o.stop) {
+			return
+		}
+	}
+}
+
+// stopped tells whether the worker or the whole dispatcher is stopping.
+func (o *worker) stopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	case <-o.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// attemptDue attempts every delivery of a webhook that is due, oldest first, and returns when the next one is due.
+func (o *Dispatcher) attemptDue(id string, worker *worker) time.Time {
+	for {
+		if worker.stopped(o.stop) {
+			return time.Time{}
+		}
+
+		o.mu.Lock()
+		now := time.Now()
+		var next time.Time
+		index := -1
+		for i, delivery := range o.deliveries {
+			if delivery.WebhookID != id || delivery.Status != DeliveryPending {
+				continue
+			}
+			if !delivery.NextAttemptAt.After(now) {
+				index = i
+				break
+			}
+			if next.IsZero() || delivery.NextAttemptAt.Before(next) {
+				next = *delivery.NextAttemptAt
+			}
+		}
+		if index < 0 {
+			o.mu.Unlock()
+			return next
+		}
+
+		delivery := o.deliveries[index]
+		webhookIndex := o.index(id)
+		if webhookIndex < 0 {
+			o.deliveries[index].abandon("webhook was unregistered")
+			o.record(o.deliveries[index])
+			o.mu.Unlock()
+			continue
+		}
+		webhook := o.webhooks[webhookIndex]
+		o.mu.Unlock()
+
+		responseStatus, err := o.send(webhook, delivery)
+
+		o.mu.Lock()
+		index = o.find(delivery.ID)
+		if index >= 0 {
+			o.deliveries[index].attempted(responseStatus, err)
+			if err != nil {
+				o.logger.Printf(logger.LogErr+"Webhook delivery %s of event %s to %s failed on attempt %d: %s", delivery.ID, delivery.Event.Type, webhook.URL, o.deliveries[index].Attempts, err.Error())
+			}
+			o.record(o.deliveries[index])
+			o.prune()
+		}
+		o.mu.Unlock()
+	}
+}
+
+func (o *Dispatcher) find(id string) int {
+	for i := range o.deliveries {
+		if o.deliveries[i].ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// send posts the event to the webhook, signed with the secret of the webhook.
+func (o *Dispatcher) send(webhook Webhook, delivery Delivery) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, errors.New("error encoding event: " + err.Error())
+	}
+
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.New("error creating request: " + err.Error())
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "orchestrator-webhooks")
+	request.Header.Set(HeaderEvent, delivery.Event.Type)
+	request.Header.Set(HeaderDelivery, delivery.ID)
+	SignRequest(request, webhook.Secret, body)
+
+	response, err := o.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	excerpt, _ := io.ReadAll(io.LimitReader(response.Body, int64(ResponseExcerptBytes)))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message := "endpoint answered with status " + strconv.Itoa(response.StatusCode)
+		if len(excerpt) > 0 {
+			message += ": " + string(excerpt)
+		}
+		return response.StatusCode, errors.New(message)
+	}
+
+	return response.StatusCode, nil
+}
+
+/*
+SignRequest sets the signature headers of a request: the Unix time it is sent at in the timestamp header, and the
+signature of the timestamp and the body in the signature header. Receivers reject the requests whose timestamp is
+too old, so that a captured request cannot be replayed later.
+*/
+func SignRequest(request *http.Request, secret string, body []byte) {
+	timestamp := time.Now().Unix()
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, "sha256="+Sign(secret, timestamp, body))
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" with the secret, as sent in the signature header.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// attempted records the outcome of an attempt and schedules the next one when the delivery failed.
+func (o *Delivery) attempted(responseStatus int, err error) {
+	now := time.Now().UTC()
+	o.Attempts++
+	o.LastAttemptAt = &now
+	o.ResponseStatus = responseStatus
+	o.NextAttemptAt = nil
+
+	if err == nil {
+		o.Status = DeliverySucceeded
+		o.Error = ""
+		return
+	}
+
+	o.Error = err.Error()
+	if o.Attempts >= DeliveryMaxAttempts {
+		o.Status = DeliveryFailed
+		return
+	}
+
+	next := now.Add(backoff(o.Attempts))
+	o.NextAttemptAt = &next
+}
+
+func (o *Delivery) abandon(reason string) {
+	o.Status = DeliveryFailed
+	o.Error = reason
+	o.NextAttemptAt = nil
+}
+
+// backoff is the wait after the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	wait := DeliveryInitialBackoff
+	for i := 1; i < attempts && wait < DeliveryMaxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, DeliveryMaxBackoff)
+}
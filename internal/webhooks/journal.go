@@ -0,0 +1,135 @@
+package webhooks
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var (
+	DeliveriesLogFileName = "deliveries.log"
+	// DeliveriesLogCompactAfter is the number of records appended to the deliveries log before it is folded into the deliveries file.
+	DeliveriesLogCompactAfter = 1000
+)
+
+/*
+journal persists the deliveries without rewriting all of them on every change: deliveries.json keeps them as of the
+last compaction, and deliveries.log the deliveries that changed since, one JSON record per line, where the last
+record of a delivery wins. Once the log has DeliveriesLogCompactAfter records, it is folded into deliveries.json.
+*/
+type journal struct {
+	dir     string
+	file    *os.File
+	records int
+}
+
+/*
+load reads the deliveries of the last compaction and replays the records of the log onto them. The deliveries that
+could be read are returned with the error, when one of the files cannot be read.
+*/
+func (o *journal) load() ([]Delivery, error) {
+	var deliveries []Delivery
+	var errs []error
+	content, err := os.ReadFile(filepath.Join(o.dir, DeliveriesFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		errs = append(errs, errors.New("error reading deliveries file: "+err.Error()))
+	default:
+		if err := json.Unmarshal(content, &deliveries); err != nil {
+			deliveries = nil
+			errs = append(errs, errors.New("error decoding deliveries file: "+err.Error()))
+		}
+	}
+
+	o.file, err = os.OpenFile(filepath.Join(o.dir, DeliveriesLogFileName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return deliveries, errors.Join(append(errs, errors.New("error opening deliveries log: "+err.Error()))...)
+	}
+
+	indices := make(map[string]int, len(deliveries))
+	for i, delivery := range deliveries {
+		indices[delivery.ID] = i
+	}
+
+	// A record cut short by a crash is the last line, and is skipped.
+	scanner := bufio.NewScanner(o.file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var delivery Delivery
+		if json.Unmarshal(scanner.Bytes(), &delivery) != nil || delivery.ID == "" {
+			continue
+		}
+		o.records++
+
+		if i, ok := indices[delivery.ID]; ok {
+			deliveries[i] = delivery
+			continue
+		}
+		indices[delivery.ID] = len(deliveries)
+		deliveries = append(deliveries, delivery)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, errors.New("error reading deliveries log: "+err.Error()))
+	}
+
+	return deliveries, errors.Join(errs...)
+}
+
+// append writes the changed deliveries to the log.
+func (o *journal) append(deliveries ...Delivery) error {
+	if o.file == nil {
+		return errors.New("deliveries log is not open")
+	}
+
+	var content []byte
+	for _, delivery := range deliveries {
+		record, err := json.Marshal(delivery)
+		if err != nil {
+			return errors.New("error encoding delivery: " + err.Error())
+		}
+		content = append(append(content, record...), '\n')
+	}
+
+	if _, err := o.file.Write(content); err != nil {
+		return errors.New("error writing deliveries log: " + err.Error())
+	}
+	o.records += len(deliveries)
+
+	return nil
+}
+
+// due tells whether the log has enough records to be compacted.
+func (o *journal) due() bool {
+	return o.records >= DeliveriesLogCompactAfter
+}
+
+/*
+compact writes all the deliveries to deliveries.json and empties the log. When it is interrupted between both, the
+records of the log are replayed onto deliveries that already have them, which leaves them unchanged.
+*/
+func (o *journal) compact(deliveries []Delivery) error {
+	if deliveries == nil {
+		deliveries = []Delivery{}
+	}
+	if err := writeJSON(filepath.Join(o.dir, DeliveriesFileName), deliveries, 0600); err != nil {
+		return err
+	}
+
+	if o.file != nil {
+		if err := o.file.Truncate(0); err != nil {
+			return errors.New("error truncating deliveries log: " + err.Error())
+		}
+	}
+	o.records = 0
+
+	return nil
+}
+
+func (o *journal) close() {
+	if o.file != nil {
+		o.file.Close()
+	}
+}
@@ -0,0 +1,389 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"orchestrator/internal/events"
+	"orchestrator/internal/logger"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	WebhooksFileName   = "webhooks.json"
+	DeliveriesFileName = "deliveries.json"
+	SecretSize         = 32
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidWebhook  = errors.New("invalid webhook")
+)
+
+/*
+Webhook is an endpoint that receives the events of the orchestrator as JSON. Events and Groups filter what is
+delivered, every event by default. Events that are not about an executable, such as config_reloaded, are not
+filtered by group.
+*/
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the deliveries. It is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events,omitempty"`
+	Groups    []string  `json:"groups,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+/*
+Dispatcher delivers the events of the bus to the registered webhooks, each webhook by a worker of its own so that a
+slow or failing endpoint does not delay the others. The webhooks are kept in webhooks.json in its directory, which
+may also be written by hand, and the deliveries in a journal, so that the pending ones are resumed after a restart.
+*/
+type Dispatcher struct {
+	mu         sync.Mutex
+	dir        string
+	logger     *log.Logger
+	client     *http.Client
+	webhooks   []Webhook
+	deliveries []Delivery
+	journal    *journal
+	// workers deliver the events of the webhooks, by webhook ID.
+	workers map[string]*worker
+	running sync.WaitGroup
+	stop    chan struct{}
+}
+
+// worker delivers the events of a webhook, one after the other.
+type worker struct {
+	wake chan struct{}
+	stop chan struct{}
+}
+
+func NewDispatcher(dir string, bus *events.Bus, logger *log.Logger) (*Dispatcher, func(), error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, errors.New("error creating webhooks directory: " + err.Error())
+	}
+
+	dispatcher := &Dispatcher{
+		dir:     dir,
+		logger:  logger,
+		client:  &http.Client{Timeout: DeliveryTimeout},
+		journal: &journal{dir: dir},
+		workers: make(map[string]*worker),
+		stop:    make(chan struct{}),
+	}
+
+	if err := dispatcher.load(); err != nil {
+		dispatcher.journal.close()
+		return nil, nil, err
+	}
+
+	subscription, cancel := bus.Subscribe()
+	go dispatcher.consume(subscription)
+
+	dispatcher.mu.Lock()
+	for _, webhook := range dispatcher.webhooks {
+		dispatcher.startWorker(webhook.ID)
+	}
+	dispatcher.mu.Unlock()
+
+	cleanup := func() {
+		cancel()
+		close(dispatcher.stop)
+		dispatcher.running.Wait()
+		dispatcher.journal.close()
+	}
+
+	return dispatcher, cleanup, nil
+}
+
+// Webhooks returns the registered webhooks, without their secrets.
+func (o *Dispatcher) Webhooks() []Webhook {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	webhooks := make([]Webhook, 0, len(o.webhooks))
+	for _, webhook := range o.webhooks {
+		webhooks = append(webhooks, webhook.redacted())
+	}
+
+	return webhooks
+}
+
+func (o *Dispatcher) Webhook(id string) (Webhook, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	index := o.index(id)
+	if index < 0 {
+		return Webhook{}, ErrWebhookNotFound
+	}
+
+	return o.webhooks[index].redacted(), nil
+}
+
+// Register validates and adds a webhook. A secret is generated when it has none.
+func (o *Dispatcher) Register(webhook Webhook) (Webhook, error) {
+	if webhook.Secret == "" {
+		secret := make([]byte, SecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return Webhook{}, errors.New("error generating webhook secret: " + err.Error())
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	if err := webhook.validate(); err != nil {
+		return Webhook{}, err
+	}
+
+	webhook.ID = uuid.New().String()
+	webhook.CreatedAt = time.Now().UTC()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.webhooks = append(o.webhooks, webhook)
+	if err := o.saveWebhooks(); err != nil {
+		o.webhooks = o.webhooks[:len(o.webhooks)-1]
+		return Webhook{}, err
+	}
+	o.startWorker(webhook.ID)
+
+	return webhook, nil
+}
+
+// Unregister removes a webhook. Its pending deliveries are abandoned.
+func (o *Dispatcher) Unregister(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	index := o.index(id)
+	if index < 0 {
+		return ErrWebhookNotFound
+	}
+
+	webhooks := slices.Delete(slices.Clone(o.webhooks), index, index+1)
+	previous := o.webhooks
+	o.webhooks = webhooks
+	if err := o.saveWebhooks(); err != nil {
+		o.webhooks = previous
+		return err
+	}
+
+	o.stopWorker(id)
+
+	var abandoned []Delivery
+	for i := range o.deliveries {
+		if o.deliveries[i].WebhookID == id && o.deliveries[i].Status == DeliveryPending {
+			o.deliveries[i].abandon("webhook was unregistered")
+			abandoned = append(abandoned, o.deliveries[i])
+		}
+	}
+	o.record(abandoned...)
+
+	return nil
+}
+
+// Ping queues a ping event for a webhook, to check that it is reachable.
+func (o *Dispatcher) Ping(id string) (Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	index := o.index(id)
+	if index < 0 {
+		return Delivery{}, ErrWebhookNotFound
+	}
+
+	event := events.Event{Timestamp: time.Now().UTC(), Type: EventPing, Message: "ping"}
+	delivery := o.enqueue(o.webhooks[index], event)
+	o.record(delivery)
+	o.notify(id)
+
+	return delivery, nil
+}
+
+func (o *Dispatcher) index(id string) int {
+	return slices.IndexFunc(o.webhooks, func(webhook Webhook) bool { return webhook.ID == id })
+}
+
+// consume queues a delivery of every event of the subscription for every webhook that it matches.
+func (o *Dispatcher) consume(subscription <-chan events.Event) {
+	for event := range subscription {
+		o.mu.Lock()
+		var queued []Delivery
+		for _, webhook := range o.webhooks {
+			if webhook.matches(event) {
+				queued = append(queued, o.enqueue(webhook, event))
+			}
+		}
+		o.record(queued...)
+		for _, delivery := range queued {
+			o.notify(delivery.WebhookID)
+		}
+		o.mu.Unlock()
+	}
+}
+
+// notify wakes the worker of a webhook up to attempt its deliveries. The lock of the dispatcher is held.
+func (o *Dispatcher) notify(id string) {
+	worker, ok := o.workers[id]
+	if !ok {
+		return
+	}
+
+	select {
+	case worker.wake <- struct{}{}:
+	default:
+	}
+}
+
+// startWorker starts delivering the events of a webhook. The lock of the dispatcher is held.
+func (o *Dispatcher) startWorker(id string) {
+	worker := &worker{wake: make(chan struct{}, 1), stop: make(chan struct{})}
+	o.workers[id] = worker
+
+	o.running.Add(1)
+	go o.deliver(id, worker)
+}
+
+// stopWorker stops the worker of a webhook once its current attempt returned. The lock of the dispatcher is held.
+func (o *Dispatcher) stopWorker(id string) {
+	if worker, ok := o.workers[id]; ok {
+		close(worker.stop)
+		delete(o.workers, id)
+	}
+}
+
+func (o Webhook) matches(event events.Event) bool {
+	if len(o.Events) > 0 && !slices.Contains(o.Events, event.Type) {
+		return false
+	}
+	if len(o.Groups) > 0 && event.ExecutableID != "" && !slices.Contains(o.Groups, event.Group) {
+		return false
+	}
+
+	return true
+}
+
+func (o Webhook) redacted() Webhook {
+	o.Secret = ""
+	return o
+}
+
+func (o Webhook) validate() error {
+	endpoint, err := url.Parse(o.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL: %s", ErrInvalidWebhook, o.URL)
+	}
+	if o.Secret == "" {
+		return fmt.Errorf("%w: secret is required: %s", ErrInvalidWebhook, o.URL)
+	}
+
+	return nil
+}
+
+func (o *Dispatcher) load() error {
+	content, err := os.ReadFile(filepath.Join(o.dir, WebhooksFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return errors.New("error reading webhooks file: " + err.Error())
+	default:
+		if err := json.Unmarshal(content, &o.webhooks); err != nil {
+			return errors.New("error decoding webhooks file: " + err.Error())
+		}
+	}
+
+	changed := false
+	for i := range o.webhooks {
+		if err := o.webhooks[i].validate(); err != nil {
+			return err
+		}
+		// Webhooks written by hand get an ID once.
+		if o.webhooks[i].ID == "" {
+			o.webhooks[i].ID = uuid.New().String()
+			o.webhooks[i].CreatedAt = time.Now().UTC()
+			changed = true
+		}
+	}
+	if changed {
+		if err := o.saveWebhooks(); err != nil {
+			return err
+		}
+	}
+
+	o.deliveries, err = o.journal.load()
+	if err != nil {
+		o.logger.Printf(logger.LogErr+"Error loading webhook deliveries, starting with the ones that could be read: %s", err.Error())
+	}
+	o.prune()
+
+	// The webhooks removed from webhooks.json by hand have no worker to attempt their deliveries.
+	var abandoned []Delivery
+	for i := range o.deliveries {
+		if o.deliveries[i].Status == DeliveryPending && o.index(o.deliveries[i].WebhookID) < 0 {
+			o.deliveries[i].abandon("webhook was unregistered")
+			abandoned = append(abandoned, o.deliveries[i])
+		}
+	}
+	o.record(abandoned...)
+
+	return nil
+}
+
+func (o *Dispatcher) saveWebhooks() error {
+	if o.webhooks == nil {
+		o.webhooks = []Webhook{}
+	}
+
+	return writeJSON(filepath.Join(o.dir, WebhooksFileName), o.webhooks, 0600)
+}
+
+/*
+record persists the deliveries that changed by appending them to the journal, which is compacted once it grew long
+enough. A failure is logged, the deliveries are still attempted. The lock of the dispatcher is held.
+*/
+func (o *Dispatcher) record(deliveries ...Delivery) {
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := o.journal.append(deliveries...); err != nil {
+		o.logger.Printf(logger.LogErr+"Error saving webhook deliveries: %s", err.Error())
+		return
+	}
+	if o.journal.due() {
+		if err := o.journal.compact(o.deliveries); err != nil {
+			o.logger.Printf(logger.LogErr+"Error compacting webhook deliveries: %s", err.Error())
+		}
+	}
+}
+
+// writeJSON replaces the file through a temporary file, so that it is never left half written.
+func writeJSON(path string, value any, mode os.FileMode) error {
+	content, err := json.MarshalIndent(value, "", "    ")
+	if err != nil {
+		return errors.New("error encoding " + filepath.Base(path) + ": " + err.Error())
+	}
+
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, append(content, '\n'), mode); err != nil {
+		return errors.New("error writing " + filepath.Base(path) + ": " + err.Error())
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return errors.New("error writing " + filepath.Base(path) + ": " + err.Error())
+	}
+
+	return nil
+}